@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/controller"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest/auth"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messaging/msghandler"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	arieshttp "github.com/hyperledger/aries-framework-go/pkg/didcomm/transport/http"
@@ -77,6 +78,7 @@ const (
 	agentWebhookFlagShorthand = "w"
 	agentWebhookFlagUsage     = "URL to send notifications to." +
 		" This flag can be repeated, allowing for multiple listeners." +
+		" In multi-tenant mode values should be in `tenant@url` format." +
 		" Alternatively, this can be set with the following environment variable (in CSV format): " + agentWebhookEnvKey
 
 	// default label flag.
@@ -149,6 +151,59 @@ const (
 		" Possible values [true] [false]. Defaults to false if not set." +
 		" Alternatively, this can be set with the following environment variable: " + agentAutoAcceptEnvKey
 
+	// graphql flag.
+	agentGraphQLFlagName  = "enable-graphql"
+	agentGraphQLEnvKey    = "ARIESD_ENABLE_GRAPHQL"
+	agentGraphQLFlagUsage = "Enables GraphQL query endpoint over stored credentials, connections and DIDs." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + agentGraphQLEnvKey
+
+	// FIPS mode flag.
+	agentFIPSModeFlagName  = "fips-mode"
+	agentFIPSModeEnvKey    = "ARIESD_FIPS_MODE"
+	agentFIPSModeFlagUsage = "Restricts crypto, KMS and packers to FIPS 140 approved algorithms." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + agentFIPSModeEnvKey
+
+	// tenants flag
+	agentTenantsFlagName  = "tenants"
+	agentTenantsEnvKey    = "ARIESD_TENANTS"
+	agentTenantsFlagUsage = "IDs of the tenants served by this agent. Setting tenants, tenant API keys or an OIDC" +
+		" issuer starts the agent in multi-tenant mode, in which every tenant gets its own agent with its own" +
+		" stores and REST requests are routed to the agent of the authenticated tenant." +
+		" The agents share the HTTP inbound transport, on which the endpoint of a tenant is the external" +
+		" inbound host followed by the tenant ID, e.g. https://example.com:8081/acme." +
+		" Tenant IDs may contain lowercase letters, digits and '-'." +
+		" Alternatively, this can be set with the following environment variable (in CSV format): " +
+		agentTenantsEnvKey
+
+	// tenant API keys flag
+	agentTenantAPIKeysFlagName  = "tenant-api-keys"
+	agentTenantAPIKeysEnvKey    = "ARIESD_TENANT_API_KEYS" // nolint:gosec
+	agentTenantAPIKeysFlagUsage = "API keys of tenants, checked in the X-API-Key header." +
+		" Values should be in `tenant@key` format." +
+		" Alternatively, this can be set with the following environment variable (in CSV format): " +
+		agentTenantAPIKeysEnvKey
+
+	// OIDC issuer flag
+	agentOIDCIssuerFlagName  = "oidc-issuer"
+	agentOIDCIssuerEnvKey    = "ARIESD_OIDC_ISSUER"
+	agentOIDCIssuerFlagUsage = "Issuer of OIDC bearer tokens accepted in the authorization header." +
+		" Token signing keys are fetched from the JSON Web Key Set of the issuer." +
+		" Alternatively, this can be set with the following environment variable: " + agentOIDCIssuerEnvKey
+
+	// OIDC audience flag
+	agentOIDCAudienceFlagName  = "oidc-audience"
+	agentOIDCAudienceEnvKey    = "ARIESD_OIDC_AUDIENCE"
+	agentOIDCAudienceFlagUsage = "Audience OIDC bearer tokens must be issued for. Mandatory if OIDC issuer is set." +
+		" Alternatively, this can be set with the following environment variable: " + agentOIDCAudienceEnvKey
+
+	// OIDC tenant claim flag
+	agentOIDCTenantClaimFlagName  = "oidc-tenant-claim"
+	agentOIDCTenantClaimEnvKey    = "ARIESD_OIDC_TENANT_CLAIM"
+	agentOIDCTenantClaimFlagUsage = "Claim of OIDC bearer tokens holding the tenant ID. Defaults to 'tenant' if not set." +
+		" Alternatively, this can be set with the following environment variable: " + agentOIDCTenantClaimEnvKey
+
 	// transport return route option flag.
 	agentTransportReturnRouteFlagName  = "transport-return-route"
 	agentTransportReturnRouteEnvKey    = "ARIESD_TRANSPORT_RETURN_ROUTE"
@@ -175,9 +230,13 @@ type agentParameters struct {
 	token                                          string
 	webhookURLs, httpResolvers, outboundTransports []string
 	inboundHostInternals, inboundHostExternals     []string
-	autoAccept                                     bool
+	autoAccept, graphQL, fipsMode                  bool
 	msgHandler                                     command.MessageHandler
 	dbParam                                        *dbParam
+	storeProvider                                  storage.Provider
+	inboundTransport                               transport.InboundTransport
+	tenants, tenantAPIKeys                         []string
+	oidcIssuer, oidcAudience, oidcTenantClaim      string
 }
 
 type dbParam struct {
@@ -269,7 +328,17 @@ func createStartCMD(server server) *cobra.Command { //nolint: funlen, gocyclo
 				return err
 			}
 
-			autoAccept, err := getAutoAcceptValue(cmd)
+			autoAccept, err := getBoolValue(cmd, agentAutoAcceptFlagName, agentAutoAcceptEnvKey)
+			if err != nil {
+				return err
+			}
+
+			graphQL, err := getBoolValue(cmd, agentGraphQLFlagName, agentGraphQLEnvKey)
+			if err != nil {
+				return err
+			}
+
+			fipsMode, err := getBoolValue(cmd, agentFIPSModeFlagName, agentFIPSModeEnvKey)
 			if err != nil {
 				return err
 			}
@@ -279,6 +348,31 @@ func createStartCMD(server server) *cobra.Command { //nolint: funlen, gocyclo
 				return err
 			}
 
+			tenants, err := getUserSetVars(cmd, agentTenantsFlagName, agentTenantsEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			tenantAPIKeys, err := getUserSetVars(cmd, agentTenantAPIKeysFlagName, agentTenantAPIKeysEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			oidcIssuer, err := getUserSetVar(cmd, agentOIDCIssuerFlagName, agentOIDCIssuerEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			oidcAudience, err := getUserSetVar(cmd, agentOIDCAudienceFlagName, agentOIDCAudienceEnvKey, true)
+			if err != nil {
+				return err
+			}
+
+			oidcTenantClaim, err := getUserSetVar(cmd, agentOIDCTenantClaimFlagName, agentOIDCTenantClaimEnvKey, true)
+			if err != nil {
+				return err
+			}
+
 			httpResolvers, err := getUserSetVars(cmd, agentHTTPResolverFlagName, agentHTTPResolverEnvKey, true)
 			if err != nil {
 				return err
@@ -318,9 +412,16 @@ func createStartCMD(server server) *cobra.Command { //nolint: funlen, gocyclo
 				httpResolvers:        httpResolvers,
 				outboundTransports:   outboundTransports,
 				autoAccept:           autoAccept,
+				graphQL:              graphQL,
+				fipsMode:             fipsMode,
 				transportReturnRoute: transportReturnRoute,
 				tlsCertFile:          tlsCertFile,
 				tlsKeyFile:           tlsKeyFile,
+				tenants:              tenants,
+				tenantAPIKeys:        tenantAPIKeys,
+				oidcIssuer:           oidcIssuer,
+				oidcAudience:         oidcAudience,
+				oidcTenantClaim:      oidcTenantClaim,
 			}
 
 			return startAgent(parameters)
@@ -362,8 +463,8 @@ func getDBParam(cmd *cobra.Command) (*dbParam, error) {
 	return dbParam, nil
 }
 
-func getAutoAcceptValue(cmd *cobra.Command) (bool, error) {
-	v, err := getUserSetVar(cmd, agentAutoAcceptFlagName, agentAutoAcceptEnvKey, true)
+func getBoolValue(cmd *cobra.Command, flagName, envKey string) (bool, error) {
+	v, err := getUserSetVar(cmd, flagName, envKey, true)
 	if err != nil {
 		return false, err
 	}
@@ -417,6 +518,23 @@ func createFlags(startCmd *cobra.Command) {
 	// auto accept flag
 	startCmd.Flags().StringP(agentAutoAcceptFlagName, "", "", agentAutoAcceptFlagUsage)
 
+	// graphql flag
+	startCmd.Flags().StringP(agentGraphQLFlagName, "", "", agentGraphQLFlagUsage)
+
+	// FIPS mode flag
+	startCmd.Flags().StringP(agentFIPSModeFlagName, "", "", agentFIPSModeFlagUsage)
+
+	// tenants flag
+	startCmd.Flags().StringSliceP(agentTenantsFlagName, "", []string{}, agentTenantsFlagUsage)
+
+	// tenant API keys flag
+	startCmd.Flags().StringSliceP(agentTenantAPIKeysFlagName, "", []string{}, agentTenantAPIKeysFlagUsage)
+
+	// OIDC flags
+	startCmd.Flags().StringP(agentOIDCIssuerFlagName, "", "", agentOIDCIssuerFlagUsage)
+	startCmd.Flags().StringP(agentOIDCAudienceFlagName, "", "", agentOIDCAudienceFlagUsage)
+	startCmd.Flags().StringP(agentOIDCTenantClaimFlagName, "", "", agentOIDCTenantClaimFlagUsage)
+
 	// transport return route option flag
 	startCmd.Flags().StringP(agentTransportReturnRouteFlagName, "", "", agentTransportReturnRouteFlagUsage)
 
@@ -622,20 +740,55 @@ func startAgent(parameters *agentParameters) error {
 		return errMissingHost
 	}
 
+	var (
+		router http.Handler
+		err    error
+	)
+
+	if parameters.isMultiTenant() {
+		router, err = createMultiTenantRouter(parameters)
+	} else {
+		router, err = createAgentRouter(parameters)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Starting aries agent rest on host [%s]", parameters.host)
+	// start server on given port and serve using given handlers
+	handler := cors.New(
+		cors.Options{
+			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodHead},
+			AllowedHeaders: []string{
+				"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization", auth.APIKeyHeader,
+			},
+		},
+	).Handler(router)
+
+	err = parameters.server.ListenAndServe(parameters.host, handler, parameters.tlsCertFile, parameters.tlsKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to start aries agent rest on port [%s], cause:  %w", parameters.host, err)
+	}
+
+	return nil
+}
+
+func createAgentRouter(parameters *agentParameters) (*mux.Router, error) {
 	// set message handler
 	parameters.msgHandler = msghandler.NewRegistrar()
 
 	ctx, err := createAriesAgent(parameters)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// get all HTTP REST API handlers available for controller API
 	handlers, err := controller.GetRESTHandlers(ctx, controller.WithWebhookURLs(parameters.webhookURLs...),
 		controller.WithDefaultLabel(parameters.defaultLabel), controller.WithAutoAccept(parameters.autoAccept),
-		controller.WithMessageHandler(parameters.msgHandler))
+		controller.WithMessageHandler(parameters.msgHandler), controller.WithGraphQL(parameters.graphQL))
 	if err != nil {
-		return fmt.Errorf("failed to start aries agent rest on port [%s], failed to get rest service api :  %w",
+		return nil, fmt.Errorf("failed to start aries agent rest on port [%s], failed to get rest service api :  %w",
 			parameters.host, err)
 	}
 
@@ -649,29 +802,20 @@ func startAgent(parameters *agentParameters) error {
 		router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
 	}
 
-	logger.Infof("Starting aries agent rest on host [%s]", parameters.host)
-	// start server on given port and serve using given handlers
-	handler := cors.New(
-		cors.Options{
-			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodHead},
-			AllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "Authorization"},
-		},
-	).Handler(router)
-
-	err = parameters.server.ListenAndServe(parameters.host, handler, parameters.tlsCertFile, parameters.tlsKeyFile)
-	if err != nil {
-		return fmt.Errorf("failed to start aries agent rest on port [%s], cause:  %w", parameters.host, err)
-	}
-
-	return nil
+	return router, nil
 }
 
 func createAriesAgent(parameters *agentParameters) (*context.Provider, error) {
 	var opts []aries.Option
 
-	storePro, err := createStoreProviders(parameters)
-	if err != nil {
-		return nil, err
+	storePro := parameters.storeProvider
+	if storePro == nil {
+		var err error
+
+		storePro, err = createStoreProviders(parameters)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	opts = append(opts, aries.WithStoreProvider(storePro))
@@ -680,6 +824,14 @@ func createAriesAgent(parameters *agentParameters) (*context.Provider, error) {
 		opts = append(opts, aries.WithTransportReturnRoute(parameters.transportReturnRoute))
 	}
 
+	if parameters.fipsMode {
+		opts = append(opts, aries.WithFIPSMode())
+	}
+
+	if parameters.inboundTransport != nil {
+		opts = append(opts, aries.WithInboundTransport(parameters.inboundTransport))
+	}
+
 	inboundTransportOpt, err := getInboundTransportOpts(parameters.inboundHostInternals,
 		parameters.inboundHostExternals, parameters.tlsCertFile, parameters.tlsKeyFile)
 	if err != nil {
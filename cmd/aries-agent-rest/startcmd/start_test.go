@@ -453,6 +453,60 @@ func TestStartCmdWithoutWebhookURLAndAutoAccept(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestStartCmdWithGraphQL(t *testing.T) {
+	startCmd, err := Cmd(&mockServer{})
+	require.NoError(t, err)
+
+	args := []string{
+		"--" + agentHostFlagName,
+		randomURL(),
+		"--" + agentInboundHostFlagName,
+		httpProtocol + "@" + randomURL(),
+		"--" + databaseTypeFlagName,
+		databaseTypeMemOption,
+		"--" + agentWebhookFlagName,
+		"",
+		"--" + agentGraphQLFlagName,
+		"true",
+	}
+	startCmd.SetArgs(args)
+
+	err = startCmd.Execute()
+	require.NoError(t, err)
+
+	startCmd, err = Cmd(&mockServer{})
+	require.NoError(t, err)
+
+	startCmd.SetArgs(append(args[:len(args)-1], "invalid"))
+
+	err = startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestStartCmdWithFIPSMode(t *testing.T) {
+	startCmd, err := Cmd(&mockServer{})
+	require.NoError(t, err)
+
+	args := []string{
+		"--" + agentHostFlagName,
+		randomURL(),
+		"--" + agentInboundHostFlagName,
+		httpProtocol + "@" + randomURL(),
+		"--" + databaseTypeFlagName,
+		databaseTypeMemOption,
+		"--" + agentWebhookFlagName,
+		"",
+		"--" + agentFIPSModeFlagName,
+		"invalid",
+	}
+	startCmd.SetArgs(args)
+
+	err = startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
 func TestStartCmdValidArgs(t *testing.T) {
 	startCmd, err := Cmd(&mockServer{})
 	require.NoError(t, err)
@@ -0,0 +1,333 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package startcmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest/auth"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	arieshttp "github.com/hyperledger/aries-framework-go/pkg/didcomm/transport/http"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/wrapper/tenant"
+)
+
+const (
+	tenantSeparator = "@"
+	oidcHTTPTimeout = 10 * time.Second
+)
+
+func (p *agentParameters) isMultiTenant() bool {
+	return len(p.tenants) > 0 || len(p.tenantAPIKeys) > 0 || p.oidcIssuer != ""
+}
+
+// createMultiTenantRouter creates an agent with tenant scoped stores for every tenant and returns a router which
+// authenticates requests and dispatches them to the agent of the authenticated tenant.
+func createMultiTenantRouter(parameters *agentParameters) (http.Handler, error) {
+	if parameters.token != "" {
+		return nil, errors.New("api token can't be used in multi-tenant mode, use tenant API keys instead")
+	}
+
+	inbound, err := newSharedInbound(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := parseTenantAPIKeys(parameters.tenantAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticators, err := createAuthenticators(parameters, apiKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := append([]string{}, parameters.tenants...)
+	for _, tenantID := range apiKeys {
+		tenants = append(tenants, tenantID)
+	}
+
+	webhookURLs, err := parseTenantWebhookURLs(parameters.webhookURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	storeProvider, err := createStoreProviders(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	handlers := make(map[string]http.Handler)
+
+	for _, tenantID := range tenants {
+		if _, ok := handlers[tenantID]; ok {
+			continue
+		}
+
+		handlers[tenantID], err = createTenantRouter(parameters, storeProvider, inbound, tenantID,
+			webhookURLs[tenantID])
+		if err != nil {
+			return nil, err
+		}
+
+		delete(webhookURLs, tenantID)
+	}
+
+	if len(webhookURLs) > 0 {
+		unknown := make([]string, 0, len(webhookURLs))
+		for tenantID := range webhookURLs {
+			unknown = append(unknown, tenantID)
+		}
+
+		sort.Strings(unknown)
+
+		return nil, fmt.Errorf("webhook URLs set for unknown tenants: %s", strings.Join(unknown, ", "))
+	}
+
+	if inbound != nil {
+		inbound.start()
+	}
+
+	logger.Infof("Serving %d tenants", len(handlers))
+
+	return auth.Middleware(authenticators...)(auth.NewTenantRouter(handlers)), nil
+}
+
+func createTenantRouter(parameters *agentParameters, storeProvider storage.Provider, inbound *sharedInbound,
+	tenantID string, webhookURLs []string) (http.Handler, error) {
+	scopedProvider, err := tenant.NewProvider(storeProvider, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantParameters := *parameters
+	tenantParameters.storeProvider = scopedProvider
+	tenantParameters.webhookURLs = webhookURLs
+	tenantParameters.inboundHostInternals = nil
+	tenantParameters.inboundHostExternals = nil
+
+	if inbound != nil {
+		tenantParameters.inboundTransport = inbound.tenantTransport(tenantID)
+	}
+
+	router, err := createAgentRouter(&tenantParameters)
+	if err != nil {
+		return nil, fmt.Errorf("tenant '%s': %w", tenantID, err)
+	}
+
+	return router, nil
+}
+
+func createAuthenticators(parameters *agentParameters, apiKeys map[string]string) ([]auth.Authenticator, error) {
+	var authenticators []auth.Authenticator
+
+	if len(apiKeys) > 0 {
+		apiKeyAuthenticator, err := auth.NewAPIKeyAuthenticator(apiKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, apiKeyAuthenticator)
+	}
+
+	if parameters.oidcIssuer != "" {
+		opts := []auth.OIDCOpt{auth.WithHTTPClient(&http.Client{Timeout: oidcHTTPTimeout})}
+
+		if parameters.oidcTenantClaim != "" {
+			opts = append(opts, auth.WithTenantClaim(parameters.oidcTenantClaim))
+		}
+
+		oidcAuthenticator, err := auth.NewOIDCAuthenticator(parameters.oidcIssuer, parameters.oidcAudience, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, oidcAuthenticator)
+	}
+
+	if len(authenticators) == 0 {
+		return nil, errors.New("multi-tenant mode requires tenant API keys or an OIDC issuer")
+	}
+
+	return authenticators, nil
+}
+
+// sharedInbound is the inbound HTTP server shared by the agents of the tenants. Messages are routed to the agent of
+// the tenant whose ID is the first segment of the request path, e.g. https://agent.example.com/acme.
+type sharedInbound struct {
+	server            *http.Server
+	externalAddr      string
+	certFile, keyFile string
+
+	lock     sync.RWMutex
+	handlers map[string]http.Handler
+}
+
+func newSharedInbound(parameters *agentParameters) (*sharedInbound, error) {
+	if len(parameters.inboundHostInternals) == 0 {
+		return nil, nil
+	}
+
+	internalHost, err := getInboundSchemeToURLMap(parameters.inboundHostInternals)
+	if err != nil {
+		return nil, fmt.Errorf("inbound internal host : %w", err)
+	}
+
+	externalHost, err := getInboundSchemeToURLMap(parameters.inboundHostExternals)
+	if err != nil {
+		return nil, fmt.Errorf("inbound external host : %w", err)
+	}
+
+	for scheme := range internalHost {
+		if scheme != httpProtocol {
+			return nil, fmt.Errorf("inbound transport [%s] not supported in multi-tenant mode", scheme)
+		}
+	}
+
+	externalAddr := externalHost[httpProtocol]
+	if externalAddr == "" {
+		externalAddr = internalHost[httpProtocol]
+	}
+
+	i := &sharedInbound{
+		externalAddr: strings.TrimSuffix(externalAddr, "/"),
+		certFile:     parameters.tlsCertFile,
+		keyFile:      parameters.tlsKeyFile,
+		handlers:     make(map[string]http.Handler),
+	}
+
+	i.server = &http.Server{Addr: internalHost[httpProtocol], Handler: i}
+
+	return i, nil
+}
+
+func (i *sharedInbound) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenantID := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0] // nolint:gomnd
+
+	i.lock.RLock()
+	handler, ok := i.handlers[tenantID]
+	i.lock.RUnlock()
+
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+func (i *sharedInbound) start() {
+	logger.Infof("Starting shared inbound transport on host [%s]", i.server.Addr)
+
+	go func() {
+		var err error
+
+		if i.certFile != "" && i.keyFile != "" {
+			err = i.server.ListenAndServeTLS(i.certFile, i.keyFile)
+		} else {
+			err = i.server.ListenAndServe()
+		}
+
+		if err != http.ErrServerClosed {
+			logger.Fatalf("shared inbound transport with address [%s] failed, cause:  %s", i.server.Addr, err)
+		}
+	}()
+}
+
+func (i *sharedInbound) tenantTransport(tenantID string) *tenantInbound {
+	return &tenantInbound{shared: i, tenantID: tenantID}
+}
+
+// tenantInbound is the inbound transport of the agent of a tenant, served by the shared inbound server.
+type tenantInbound struct {
+	shared   *sharedInbound
+	tenantID string
+}
+
+// Start routes the messages of the tenant to the agent.
+func (t *tenantInbound) Start(prov transport.Provider) error {
+	handler, err := arieshttp.NewInboundHandler(prov)
+	if err != nil {
+		return fmt.Errorf("tenant '%s' inbound transport start failed: %w", t.tenantID, err)
+	}
+
+	t.shared.lock.Lock()
+	t.shared.handlers[t.tenantID] = handler
+	t.shared.lock.Unlock()
+
+	return nil
+}
+
+// Stop stops routing the messages of the tenant to the agent.
+func (t *tenantInbound) Stop() error {
+	t.shared.lock.Lock()
+	delete(t.shared.handlers, t.tenantID)
+	t.shared.lock.Unlock()
+
+	return nil
+}
+
+// Endpoint is the endpoint of the tenant: the external address of the shared inbound server and the tenant ID.
+func (t *tenantInbound) Endpoint() string {
+	return t.shared.externalAddr + "/" + t.tenantID
+}
+
+// parseTenantAPIKeys parses `tenant@key` values into a map of API keys to tenant IDs.
+func parseTenantAPIKeys(values []string) (map[string]string, error) {
+	apiKeys := make(map[string]string, len(values))
+
+	for _, value := range values {
+		tenantID, key, err := splitTenantValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tenant API key: %w", err)
+		}
+
+		if _, ok := apiKeys[key]; ok {
+			return nil, fmt.Errorf("invalid tenant API key: key of tenant '%s' is not unique", tenantID)
+		}
+
+		apiKeys[key] = tenantID
+	}
+
+	return apiKeys, nil
+}
+
+// parseTenantWebhookURLs parses `tenant@url` values into webhook URLs per tenant ID.
+func parseTenantWebhookURLs(values []string) (map[string][]string, error) {
+	webhookURLs := make(map[string][]string)
+
+	for _, value := range values {
+		tenantID, url, err := splitTenantValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook URL: %w", err)
+		}
+
+		webhookURLs[tenantID] = append(webhookURLs[tenantID], url)
+	}
+
+	return webhookURLs, nil
+}
+
+func splitTenantValue(value string) (string, string, error) {
+	parts := strings.SplitN(value, tenantSeparator, 2) // nolint:gomnd
+	if len(parts) != 2 || parts[1] == "" {             // nolint:gomnd
+		return "", "", errors.New("use tenant@value to pass the option")
+	}
+
+	if err := tenant.ValidateID(parts[0]); err != nil {
+		return "", "", err
+	}
+
+	return parts[0], parts[1], nil
+}
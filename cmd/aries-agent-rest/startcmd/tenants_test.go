@@ -0,0 +1,280 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package startcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest/auth"
+)
+
+const tenantDIDDoc = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:peer:21tDAKCERh95uGgKbJNHYp",
+  "verificationMethod": [
+    {
+      "id": "did:peer:21tDAKCERh95uGgKbJNHYp#keys-1",
+      "type": "Ed25519VerificationKey2018",
+      "controller": "did:peer:21tDAKCERh95uGgKbJNHYp",
+      "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+    }
+  ]
+}`
+
+func serveTenantRequest(handler http.Handler, method, path, apiKey string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+
+	if apiKey != "" {
+		req.Header.Set(auth.APIKeyHeader, apiKey)
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func getDIDRecords(t *testing.T, handler http.Handler, apiKey string) []interface{} {
+	t.Helper()
+
+	rr := serveTenantRequest(handler, http.MethodGet, "/vdr/did/records", apiKey, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	response := struct {
+		Result []interface{} `json:"result"`
+	}{}
+
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+	return response.Result
+}
+
+func TestStartAriesWithTenants(t *testing.T) {
+	handler, err := createMultiTenantRouter(&agentParameters{
+		host:          randomURL(),
+		dbParam:       &dbParam{dbType: databaseTypeMemOption},
+		tenantAPIKeys: []string{"acme@acme-key", "globex@globex-key"},
+		webhookURLs:   []string{"acme@http://localhost:8080/acme"},
+	})
+	require.NoError(t, err)
+
+	t.Run("test tenants have separate stores", func(t *testing.T) {
+		require.Empty(t, getDIDRecords(t, handler, "acme-key"))
+		require.Empty(t, getDIDRecords(t, handler, "globex-key"))
+
+		body, err := json.Marshal(map[string]interface{}{"did": json.RawMessage(tenantDIDDoc), "name": "acme-did"})
+		require.NoError(t, err)
+
+		rr := serveTenantRequest(handler, http.MethodPost, "/vdr/did", "acme-key", body)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		require.Len(t, getDIDRecords(t, handler, "acme-key"), 1)
+		require.Empty(t, getDIDRecords(t, handler, "globex-key"))
+	})
+
+	t.Run("test unauthenticated requests are rejected", func(t *testing.T) {
+		rr := serveTenantRequest(handler, http.MethodGet, "/vdr/did/records", "", nil)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+		rr = serveTenantRequest(handler, http.MethodGet, "/vdr/did/records", "initech-key", nil)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestStartAriesWithTenantsInbound(t *testing.T) {
+	inboundHost := randomURL()
+
+	_, err := createMultiTenantRouter(&agentParameters{
+		host:                 randomURL(),
+		dbParam:              &dbParam{dbType: databaseTypeMemOption},
+		tenantAPIKeys:        []string{"acme@acme-key", "globex@globex-key"},
+		inboundHostInternals: []string{httpProtocol + "@" + inboundHost},
+		inboundHostExternals: []string{httpProtocol + "@https://example.com/"},
+	})
+	require.NoError(t, err)
+
+	post := func(path string) int {
+		resp, err := http.Post("http://"+inboundHost+path, "text/plain", bytes.NewReader([]byte("{}")))
+		if err != nil {
+			return 0
+		}
+
+		require.NoError(t, resp.Body.Close())
+
+		return resp.StatusCode
+	}
+
+	// messages of the tenants are routed to the inbound handlers of their agents
+	require.Eventually(t, func() bool {
+		return post("/acme") == http.StatusUnsupportedMediaType
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, http.StatusUnsupportedMediaType, post("/globex"))
+	require.Equal(t, http.StatusNotFound, post("/initech"))
+	require.Equal(t, http.StatusNotFound, post("/"))
+
+	inbound, err := newSharedInbound(&agentParameters{
+		inboundHostInternals: []string{httpProtocol + "@" + randomURL()},
+		inboundHostExternals: []string{httpProtocol + "@https://example.com/"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/acme", inbound.tenantTransport("acme").Endpoint())
+
+	inbound, err = newSharedInbound(&agentParameters{inboundHostInternals: []string{httpProtocol + "@localhost:8081"}})
+	require.NoError(t, err)
+
+	acme := inbound.tenantTransport("acme")
+	require.Equal(t, "localhost:8081/acme", acme.Endpoint())
+	require.Error(t, acme.Start(nil))
+	require.NoError(t, acme.Stop())
+}
+
+func TestStartAriesWithOIDCTenants(t *testing.T) {
+	parameters := &agentParameters{
+		host:            randomURL(),
+		dbParam:         &dbParam{dbType: databaseTypeMemOption},
+		tenants:         []string{"acme", "globex"},
+		oidcIssuer:      "https://op.example.com",
+		oidcAudience:    "https://agent.example.com",
+		oidcTenantClaim: "org",
+	}
+
+	handler, err := createMultiTenantRouter(parameters)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/connections", nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	authenticators, err := createAuthenticators(parameters, nil)
+	require.NoError(t, err)
+	require.Len(t, authenticators, 1)
+}
+
+func TestStartAriesWithInvalidTenants(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters *agentParameters
+		err        string
+	}{
+		{
+			name:       "api token",
+			parameters: &agentParameters{token: "ABCD", tenants: []string{"acme"}},
+			err:        "api token can't be used in multi-tenant mode",
+		},
+		{
+			name: "websocket inbound transport",
+			parameters: &agentParameters{
+				inboundHostInternals: []string{websocketProtocol + "@" + randomURL()},
+				tenantAPIKeys:        []string{"acme@key"},
+			},
+			err: "inbound transport [ws] not supported in multi-tenant mode",
+		},
+		{
+			name: "invalid inbound host",
+			parameters: &agentParameters{
+				inboundHostInternals: []string{randomURL()},
+				tenantAPIKeys:        []string{"acme@key"},
+			},
+			err: "inbound internal host : invalid inbound host option",
+		},
+		{
+			name:       "no authenticators",
+			parameters: &agentParameters{tenants: []string{"acme"}},
+			err:        "multi-tenant mode requires tenant API keys or an OIDC issuer",
+		},
+		{
+			name:       "invalid API key format",
+			parameters: &agentParameters{tenantAPIKeys: []string{"acme-key"}},
+			err:        "invalid tenant API key: use tenant@value to pass the option",
+		},
+		{
+			name:       "duplicate API key",
+			parameters: &agentParameters{tenantAPIKeys: []string{"acme@key", "globex@key"}},
+			err:        "invalid tenant API key: key of tenant 'globex' is not unique",
+		},
+		{
+			name:       "invalid tenant ID",
+			parameters: &agentParameters{tenantAPIKeys: []string{"Acme@key"}},
+			err:        "invalid tenant API key: invalid tenant ID 'Acme'",
+		},
+		{
+			name: "invalid tenant ID without API key",
+			parameters: &agentParameters{
+				tenantAPIKeys: []string{"acme@key"}, tenants: []string{"acme_corp"},
+				dbParam: &dbParam{dbType: databaseTypeMemOption},
+			},
+			err: "invalid tenant ID 'acme_corp'",
+		},
+		{
+			name:       "OIDC without audience",
+			parameters: &agentParameters{tenants: []string{"acme"}, oidcIssuer: "https://op.example.com"},
+			err:        "OIDC issuer and audience are mandatory",
+		},
+		{
+			name:       "invalid webhook URL",
+			parameters: &agentParameters{tenantAPIKeys: []string{"acme@key"}, webhookURLs: []string{"http://example.com"}},
+			err:        "invalid webhook URL: use tenant@value to pass the option",
+		},
+		{
+			name: "webhook URL of unknown tenant",
+			parameters: &agentParameters{
+				tenantAPIKeys: []string{"acme@key"},
+				webhookURLs: []string{
+					"initech@http://example.com", "globex@http://example.com", "acme@http://example.com",
+				},
+				dbParam: &dbParam{dbType: databaseTypeMemOption},
+			},
+			err: "webhook URLs set for unknown tenants: globex, initech",
+		},
+		{
+			name:       "invalid database type",
+			parameters: &agentParameters{tenantAPIKeys: []string{"acme@key"}, dbParam: &dbParam{dbType: "data1"}},
+			err:        "database type not set to a valid type",
+		},
+	}
+
+	for _, tc := range tests {
+		require.True(t, tc.parameters.isMultiTenant(), tc.name)
+
+		_, err := createMultiTenantRouter(tc.parameters)
+		require.Error(t, err, tc.name)
+		require.Contains(t, err.Error(), tc.err, tc.name)
+	}
+
+	require.False(t, (&agentParameters{}).isMultiTenant())
+}
+
+func TestStartCmdWithTenants(t *testing.T) {
+	require.NoError(t, os.Unsetenv(agentInboundHostEnvKey))
+
+	startCmd, err := Cmd(&mockServer{})
+	require.NoError(t, err)
+
+	startCmd.SetArgs([]string{
+		"--" + agentHostFlagName, randomURL(),
+		"--" + databaseTypeFlagName, databaseTypeMemOption,
+		"--" + agentTenantsFlagName, "initech",
+		"--" + agentTenantAPIKeysFlagName, "acme@acme-key,globex@globex-key",
+		"--" + agentOIDCIssuerFlagName, "https://op.example.com",
+		"--" + agentOIDCAudienceFlagName, "https://agent.example.com",
+		"--" + agentOIDCTenantClaimFlagName, "org",
+		"--" + agentWebhookFlagName, "acme@http://localhost:8080/acme",
+	})
+
+	require.NoError(t, startCmd.Execute())
+}
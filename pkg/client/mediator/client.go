@@ -52,6 +52,15 @@ func WithTimeout(t time.Duration) mediator.ClientOption {
 	}
 }
 
+// WithAccept option is for the envelope formats (mediator.EnvelopeV1, mediator.EnvelopeV2) accepted by the agent,
+// in order of preference. The router wraps envelopes of other formats in a forward message packed in the preferred
+// format, which the agent unpacks in turn.
+func WithAccept(formats ...string) mediator.ClientOption {
+	return func(opts *mediator.ClientOptions) {
+		opts.Accept = formats
+	}
+}
+
 // New return new instance of route client.
 func New(ctx provider, options ...mediator.ClientOption) (*Client, error) {
 	svc, err := ctx.Service(mediator.Coordination)
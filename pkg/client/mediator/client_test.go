@@ -54,6 +54,14 @@ func TestNew(t *testing.T) {
 
 		require.Equal(t, timeout, opts.Timeout)
 	})
+
+	t.Run("test accepted envelope formats are applied to options", func(t *testing.T) {
+		option := WithAccept(mediator.EnvelopeV2, mediator.EnvelopeV1)
+		opts := &mediator.ClientOptions{}
+		option(opts)
+
+		require.Equal(t, []string{mediator.EnvelopeV2, mediator.EnvelopeV1}, opts.Accept)
+	})
 }
 
 func TestRegister(t *testing.T) {
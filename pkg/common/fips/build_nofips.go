@@ -0,0 +1,12 @@
+// +build !fips
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fips
+
+// buildTagEnabled is true when the framework is built with the `fips` build tag.
+const buildTagEnabled = false
@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package fips controls the FIPS 140 mode of the framework. When enabled, the crypto, KMS and packer implementations
+// of the framework only allow FIPS approved algorithms (AES-GCM, ECDSA over NIST curves, RSA and HMAC-SHA256) and fail
+// fast when non compliant ones (Ed25519, ChaCha20Poly1305, XChaCha20Poly1305, secp256k1) are requested.
+//
+// FIPS mode is always enabled when the framework is built with the `fips` build tag, otherwise it can be enabled at
+// runtime for an instance of the framework (aries.WithFIPSMode()), crypto (tinkcrypto.WithFIPSMode()) or KMS
+// (localkms.WithFIPSMode()), or for the whole process with SetEnabled(true).
+package fips
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrNotApproved is returned when an algorithm which is not FIPS approved is requested in FIPS mode.
+var ErrNotApproved = errors.New("algorithm is not FIPS approved")
+
+// nolint:gochecknoglobals
+var runtimeEnabled int32
+
+// Enabled returns true if FIPS mode is enabled either by the `fips` build tag or at runtime.
+func Enabled() bool {
+	return buildTagEnabled || atomic.LoadInt32(&runtimeEnabled) == 1
+}
+
+// SetEnabled enables or disables FIPS mode at runtime for the whole process. FIPS mode can't be disabled when
+// the framework has been built with the `fips` build tag.
+func SetEnabled(enabled bool) {
+	var v int32
+
+	if enabled {
+		v = 1
+	}
+
+	atomic.StoreInt32(&runtimeEnabled, v)
+}
+
+// Check returns an ErrNotApproved error for the given algorithm if FIPS mode is enabled and approved is false,
+// nil otherwise.
+func Check(alg string, approved bool) error {
+	return CheckMode(false, alg, approved)
+}
+
+// CheckMode is Check for an instance with its own FIPS mode: FIPS mode is enabled if instanceEnabled is true or it is
+// enabled for the whole process.
+func CheckMode(instanceEnabled bool, alg string, approved bool) error {
+	if (instanceEnabled || Enabled()) && !approved {
+		return fmt.Errorf("'%s' %w", alg, ErrNotApproved)
+	}
+
+	return nil
+}
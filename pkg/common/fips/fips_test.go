@@ -0,0 +1,38 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package fips
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFIPSMode(t *testing.T) {
+	defer SetEnabled(false)
+
+	t.Run("test FIPS mode - enable at runtime", func(t *testing.T) {
+		SetEnabled(true)
+		require.True(t, Enabled())
+
+		err := Check("ED25519", false)
+		require.True(t, errors.Is(err, ErrNotApproved))
+		require.EqualError(t, err, "'ED25519' algorithm is not FIPS approved")
+
+		require.NoError(t, Check("ECDSAP256DER", true))
+	})
+
+	t.Run("test FIPS mode - disable at runtime", func(t *testing.T) {
+		SetEnabled(false)
+		require.Equal(t, buildTagEnabled, Enabled())
+
+		if !buildTagEnabled {
+			require.NoError(t, Check("ED25519", false))
+		}
+	})
+}
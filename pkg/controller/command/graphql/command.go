@@ -0,0 +1,274 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/graphql"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	didstore "github.com/hyperledger/aries-framework-go/pkg/store/did"
+	verifiablestore "github.com/hyperledger/aries-framework-go/pkg/store/verifiable"
+)
+
+var logger = log.New("aries-framework/command/graphql")
+
+// Error codes.
+const (
+	// InvalidRequestErrorCode is typically a code for invalid requests.
+	InvalidRequestErrorCode = command.Code(iota + command.GraphQL)
+
+	// QueryErrorCode for errors while executing the query.
+	QueryErrorCode
+)
+
+// constants for the GraphQL query command.
+const (
+	// command name.
+	CommandName = "graphql"
+
+	// command methods.
+	QueryCommandMethod = "Query"
+
+	// MaxRequestSize is the maximum size in bytes of a query request.
+	MaxRequestSize = 1 << 20
+
+	// top level query fields.
+	credentialsField = "credentials"
+	connectionsField = "connections"
+	didsField        = "dids"
+
+	// error messages.
+	errEmptyQuery = "query is mandatory"
+)
+
+// provider contains dependencies for the graphql command and is typically created by using aries.Context().
+type provider interface {
+	StorageProvider() storage.Provider
+	ProtocolStateStorageProvider() storage.Provider
+}
+
+// Command contains GraphQL query operation over stored credentials, connections and DIDs.
+type Command struct {
+	verifiableStore  verifiablestore.Store
+	connectionLookup *connection.Lookup
+	didStore         *didstore.Store
+	resolvers        map[string]*listResolver
+}
+
+// New returns new graphql controller command instance.
+func New(p provider) (*Command, error) {
+	verifiableStore, err := verifiablestore.New(p)
+	if err != nil {
+		return nil, fmt.Errorf("new vc store : %w", err)
+	}
+
+	connectionLookup, err := connection.NewLookup(p)
+	if err != nil {
+		return nil, fmt.Errorf("new connection lookup : %w", err)
+	}
+
+	didStore, err := didstore.New(p)
+	if err != nil {
+		return nil, fmt.Errorf("new did store : %w", err)
+	}
+
+	cmd := &Command{
+		verifiableStore:  verifiableStore,
+		connectionLookup: connectionLookup,
+		didStore:         didStore,
+	}
+
+	cmd.resolvers = map[string]*listResolver{
+		credentialsField: {typeName: "Credential", sortKey: "name", fetch: cmd.credentialNodes},
+		connectionsField: {typeName: "Connection", sortKey: "connectionID", fetch: cmd.connectionNodes},
+		didsField:        {typeName: "DID", sortKey: "name", fetch: cmd.didNodes},
+	}
+
+	return cmd, nil
+}
+
+// GetHandlers returns list of all commands supported by this controller command.
+func (o *Command) GetHandlers() []command.Handler {
+	return []command.Handler{
+		cmdutil.NewCommandHandler(CommandName, QueryCommandMethod, o.Query),
+	}
+}
+
+// Query executes given GraphQL query over stored credentials, connections and DIDs.
+//
+// Each of `credentials`, `connections` and `dids` query fields accepts `filter`, `first` and `after`
+// arguments and selects `totalCount`, `pageInfo { hasNextPage endCursor }` and `nodes`. Requests larger than
+// MaxRequestSize are rejected.
+func (o *Command) Query(rw io.Writer, req io.Reader) command.Error {
+	var request QueryArgs
+
+	err := decodeRequest(req, &request)
+	if err != nil {
+		logutil.LogInfo(logger, CommandName, QueryCommandMethod, "request decode : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("request decode : %w", err))
+	}
+
+	if request.Query == "" {
+		logutil.LogDebug(logger, CommandName, QueryCommandMethod, errEmptyQuery)
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf(errEmptyQuery))
+	}
+
+	fields, err := graphql.Parse(request.Query, request.Variables)
+	if err != nil {
+		logutil.LogInfo(logger, CommandName, QueryCommandMethod, "parse query : "+err.Error())
+
+		return command.NewValidationError(InvalidRequestErrorCode, fmt.Errorf("parse query : %w", err))
+	}
+
+	data := make(map[string]interface{})
+
+	for _, field := range fields {
+		resolver, ok := o.resolvers[field.Name]
+		if !ok {
+			logutil.LogInfo(logger, CommandName, QueryCommandMethod, "unknown query field : "+field.Name)
+
+			return command.NewValidationError(InvalidRequestErrorCode,
+				fmt.Errorf("field '%s' is not defined on Query", field.Name))
+		}
+
+		data[field.ResponseKey()], err = resolver.resolve(field)
+		if err != nil {
+			logutil.LogError(logger, CommandName, QueryCommandMethod, "execute query : "+err.Error())
+
+			return command.NewExecuteError(QueryErrorCode, fmt.Errorf("execute query : %w", err))
+		}
+	}
+
+	command.WriteNillableResponse(rw, &QueryResult{Data: data}, logger)
+
+	logutil.LogDebug(logger, CommandName, QueryCommandMethod, "success")
+
+	return nil
+}
+
+// decodeRequest decodes the query request, reading at most MaxRequestSize bytes.
+func decodeRequest(req io.Reader, request *QueryArgs) error {
+	reqBytes, err := ioutil.ReadAll(io.LimitReader(req, MaxRequestSize+1))
+	if err != nil {
+		return err
+	}
+
+	if len(reqBytes) > MaxRequestSize {
+		return fmt.Errorf("request exceeds %d bytes", MaxRequestSize)
+	}
+
+	return json.Unmarshal(reqBytes, request)
+}
+
+func (o *Command) credentialNodes() ([]node, error) {
+	records, err := o.verifiableStore.GetCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("get credential records : %w", err)
+	}
+
+	nodes := make([]node, len(records))
+
+	for i, record := range records {
+		id := record.ID
+
+		nodes[i] = node{
+			"id":        record.ID,
+			"name":      record.Name,
+			"context":   record.Context,
+			"type":      record.Type,
+			"subjectId": record.SubjectID,
+			"myDID":     record.MyDID,
+			"theirDID":  record.TheirDID,
+			"credential": lazyValue(func() (interface{}, error) {
+				vc, e := o.verifiableStore.GetCredential(id)
+				if e != nil {
+					return nil, e
+				}
+
+				vcBytes, e := vc.MarshalJSON()
+				if e != nil {
+					return nil, e
+				}
+
+				return json.RawMessage(vcBytes), nil
+			}),
+		}
+	}
+
+	return nodes, nil
+}
+
+func (o *Command) connectionNodes() ([]node, error) {
+	records, err := o.connectionLookup.QueryConnectionRecords()
+	if err != nil {
+		return nil, fmt.Errorf("query connection records : %w", err)
+	}
+
+	nodes := make([]node, len(records))
+
+	for i, record := range records {
+		nodes[i] = node{
+			"connectionID":    record.ConnectionID,
+			"state":           record.State,
+			"threadID":        record.ThreadID,
+			"parentThreadID":  record.ParentThreadID,
+			"theirLabel":      record.TheirLabel,
+			"theirDID":        record.TheirDID,
+			"myDID":           record.MyDID,
+			"serviceEndPoint": record.ServiceEndPoint,
+			"recipientKeys":   record.RecipientKeys,
+			"routingKeys":     record.RoutingKeys,
+			"invitationID":    record.InvitationID,
+			"invitationDID":   record.InvitationDID,
+			"implicit":        record.Implicit,
+			"namespace":       record.Namespace,
+		}
+	}
+
+	return nodes, nil
+}
+
+func (o *Command) didNodes() ([]node, error) {
+	records := o.didStore.GetDIDRecords()
+
+	nodes := make([]node, len(records))
+
+	for i, record := range records {
+		id := record.ID
+
+		nodes[i] = node{
+			"id":   record.ID,
+			"name": record.Name,
+			"document": lazyValue(func() (interface{}, error) {
+				doc, e := o.didStore.GetDID(id)
+				if e != nil {
+					return nil, e
+				}
+
+				docBytes, e := doc.JSONBytes()
+				if e != nil {
+					return nil, e
+				}
+
+				return json.RawMessage(docBytes), nil
+			}),
+		}
+	}
+
+	return nodes, nil
+}
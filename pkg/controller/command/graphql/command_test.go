@@ -0,0 +1,338 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+	didstore "github.com/hyperledger/aries-framework-go/pkg/store/did"
+	verifiablestore "github.com/hyperledger/aries-framework-go/pkg/store/verifiable"
+)
+
+//nolint:lll
+const udCredential = `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1",
+    "https://www.w3.org/2018/credentials/examples/v1"
+  ],
+  "id": "http://example.edu/credentials/1872",
+  "type": [
+    "VerifiableCredential",
+    "UniversityDegreeCredential"
+  ],
+  "credentialSubject": {
+    "id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+    "degree": {
+      "type": "BachelorDegree"
+    },
+    "name": "Jayden Doe"
+  },
+  "issuer": {
+    "id": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+    "name": "Example University"
+  },
+  "issuanceDate": "2010-01-01T19:23:24Z"
+}`
+
+const didDoc = `{
+  "@context": ["https://w3id.org/did/v1"],
+  "id": "did:peer:21tDAKCERh95uGgKbJNHYp"
+}`
+
+func TestNew(t *testing.T) {
+	t.Run("test new command - success", func(t *testing.T) {
+		cmd, err := New(newMockProvider())
+		require.NoError(t, err)
+		require.NotNil(t, cmd)
+		require.Len(t, cmd.GetHandlers(), 1)
+	})
+
+	t.Run("test new command - store errors", func(t *testing.T) {
+		cmd, err := New(&mockprovider.Provider{
+			StorageProviderValue: &mockstore.MockStoreProvider{
+				ErrOpenStoreHandle: fmt.Errorf("error opening the store"),
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "new vc store")
+		require.Nil(t, cmd)
+
+		cmd, err = New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+			ProtocolStateStorageProviderValue: &mockstore.MockStoreProvider{
+				ErrOpenStoreHandle: fmt.Errorf("error opening the store"),
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "new connection lookup")
+		require.Nil(t, cmd)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	p := newMockProvider()
+	populateStores(t, p)
+
+	cmd, err := New(p)
+	require.NoError(t, err)
+
+	t.Run("test query - all wallet contents", func(t *testing.T) {
+		result := query(t, cmd, `{
+			credentials { totalCount nodes { id name type credential } }
+			connections(filter: {state: "completed"}) { totalCount nodes { connectionID theirLabel } }
+			dids { nodes { name document } }
+		}`, nil)
+
+		credentials := result.Data["credentials"].(map[string]interface{})
+		require.EqualValues(t, 1, credentials["totalCount"])
+		vc := credentials["nodes"].([]interface{})[0].(map[string]interface{})
+		require.Equal(t, "http://example.edu/credentials/1872", vc["id"])
+		require.Equal(t, "degree", vc["name"])
+		require.Contains(t, vc["type"], "UniversityDegreeCredential")
+		require.Equal(t, "http://example.edu/credentials/1872", vc["credential"].(map[string]interface{})["id"])
+
+		connections := result.Data["connections"].(map[string]interface{})
+		require.EqualValues(t, 2, connections["totalCount"])
+
+		dids := result.Data["dids"].(map[string]interface{})
+		doc := dids["nodes"].([]interface{})[0].(map[string]interface{})
+		require.Equal(t, "my-did", doc["name"])
+		require.Equal(t, "did:peer:21tDAKCERh95uGgKbJNHYp", doc["document"].(map[string]interface{})["id"])
+	})
+
+	t.Run("test query - filter by list field", func(t *testing.T) {
+		result := query(t, cmd, `{
+			degrees: credentials(filter: {type: "UniversityDegreeCredential"}) { totalCount }
+			other: credentials(filter: {type: "DriversLicense"}) { totalCount nodes { id } }
+		}`, nil)
+
+		require.EqualValues(t, 1, result.Data["degrees"].(map[string]interface{})["totalCount"])
+		require.EqualValues(t, 0, result.Data["other"].(map[string]interface{})["totalCount"])
+		require.Empty(t, result.Data["other"].(map[string]interface{})["nodes"])
+	})
+
+	t.Run("test query - typed and null filter criteria", func(t *testing.T) {
+		result := query(t, cmd, `query q($state: String) {
+			all: connections(filter: {state: $state}) { totalCount }
+			labelled: connections(filter: {theirLabel: null, implicit: null}) { totalCount }
+			explicit: connections(filter: {implicit: false, state: "invited"}) { totalCount }
+		}`, nil)
+
+		require.EqualValues(t, 3, result.Data["all"].(map[string]interface{})["totalCount"])
+		require.EqualValues(t, 3, result.Data["labelled"].(map[string]interface{})["totalCount"])
+		require.EqualValues(t, 1, result.Data["explicit"].(map[string]interface{})["totalCount"])
+	})
+
+	t.Run("test query - pagination", func(t *testing.T) {
+		q := `query Connections($after: String) {
+			connections(first: 2, after: $after) {
+				pageInfo { hasNextPage endCursor }
+				nodes { id: connectionID }
+			}
+		}`
+
+		result := query(t, cmd, q, nil)
+		page := result.Data["connections"].(map[string]interface{})
+		require.Len(t, page["nodes"], 2)
+		require.Equal(t, "conn-1", page["nodes"].([]interface{})[0].(map[string]interface{})["id"])
+
+		pageInfo := page["pageInfo"].(map[string]interface{})
+		require.Equal(t, true, pageInfo["hasNextPage"])
+
+		result = query(t, cmd, q, map[string]interface{}{"after": pageInfo["endCursor"]})
+		page = result.Data["connections"].(map[string]interface{})
+		require.Len(t, page["nodes"], 1)
+		require.Equal(t, "conn-3", page["nodes"].([]interface{})[0].(map[string]interface{})["id"])
+
+		pageInfo = page["pageInfo"].(map[string]interface{})
+		require.Equal(t, false, pageInfo["hasNextPage"])
+	})
+
+	t.Run("test query - validation errors", func(t *testing.T) {
+		var b bytes.Buffer
+
+		cmdErr := cmd.Query(&b, bytes.NewBufferString("--"))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "request decode")
+
+		largeQuery := strings.Repeat(" ", MaxRequestSize) + "{ dids { totalCount } }"
+
+		cmdErr = cmd.Query(&b, bytes.NewBufferString(`{"query": "`+largeQuery+`"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, InvalidRequestErrorCode, cmdErr.Code())
+		require.Contains(t, cmdErr.Error(), "request exceeds 1048576 bytes")
+
+		cmdErr = cmd.Query(&b, bytes.NewBufferString(`{}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), errEmptyQuery)
+
+		cmdErr = cmd.Query(&b, bytes.NewBufferString(`{"query": "{ credentials { "}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), "parse query")
+
+		cmdErr = cmd.Query(&b, bytes.NewBufferString(`{"query": "{ dids { nodes { `+strings.Repeat("a { ", 40)+`"}`))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), "maximum nesting depth")
+
+		cmdErr = cmd.Query(&b, bytes.NewBufferString(`{"query": "{ presentations { totalCount } }"}`))
+		require.Error(t, cmdErr)
+		require.Equal(t, command.ValidationError, cmdErr.Type())
+		require.Contains(t, cmdErr.Error(), "field 'presentations' is not defined on Query")
+	})
+
+	t.Run("test query - execute errors", func(t *testing.T) {
+		tests := []struct {
+			query string
+			err   string
+		}{
+			{query: `{ dids }`, err: "field 'dids' must have a selection of subfields"},
+			{query: `{ dids(last: 1) { totalCount } }`, err: "unknown argument 'last'"},
+			{query: `{ dids { edges { id } } }`, err: "field 'edges' is not defined on DID list"},
+			{query: `{ dids { nodes } }`, err: "field 'nodes' must have a selection of subfields"},
+			{query: `{ dids { pageInfo } }`, err: "field 'pageInfo' must have a selection of subfields"},
+			{query: `{ dids { pageInfo { startCursor } } }`, err: "field 'startCursor' is not defined on PageInfo"},
+			{query: `{ dids { nodes { did } } }`, err: "field 'did' is not defined on DID"},
+			{query: `{ dids { nodes { name { x } } } }`, err: "field 'name' on DID must not have a selection"},
+			{query: `{ dids(filter: "x") { totalCount } }`, err: "filter must be an input object"},
+			{query: `{ dids(filter: {did: "x"}) { totalCount } }`, err: "unknown filter field 'did'"},
+			{query: `{ connections(filter: {implicit: "x"}) { totalCount } }`, err: "must be a boolean"},
+			{query: `{ connections(filter: {state: 1}) { totalCount } }`, err: "'state' must be a string"},
+			{query: `{ credentials(filter: {type: true}) { totalCount } }`, err: "'type' must be a string"},
+			{query: `{ dids(first: "x") { totalCount } }`, err: "'first' must be an integer"},
+			{query: `{ dids(first: -1) { totalCount } }`, err: "'first' must not be negative"},
+			{query: `{ dids(after: 1) { totalCount } }`, err: "'after' must be a string cursor"},
+			{query: `{ dids(after: "x") { totalCount } }`, err: "invalid cursor"},
+		}
+
+		for _, tc := range tests {
+			req, err := json.Marshal(&QueryArgs{Query: tc.query})
+			require.NoError(t, err)
+
+			var b bytes.Buffer
+
+			cmdErr := cmd.Query(&b, bytes.NewBuffer(req))
+			require.Error(t, cmdErr, tc.query)
+			require.Equal(t, QueryErrorCode, cmdErr.Code(), tc.query)
+			require.Equal(t, command.ExecuteError, cmdErr.Type(), tc.query)
+			require.Contains(t, cmdErr.Error(), tc.err, tc.query)
+		}
+	})
+
+	t.Run("test query - lazy field resolution error", func(t *testing.T) {
+		require.NoError(t, cmd.didStore.SaveDID("missing", &did.Doc{ID: "did:peer:missing"}))
+
+		s, err := p.StorageProviderValue.OpenStore(didstore.NameSpace)
+		require.NoError(t, err)
+		require.NoError(t, s.Delete("did:peer:missing"))
+
+		req, err := json.Marshal(&QueryArgs{Query: `{ dids(filter: {name: "missing"}) { nodes { document } } }`})
+		require.NoError(t, err)
+
+		var b bytes.Buffer
+
+		cmdErr := cmd.Query(&b, bytes.NewBuffer(req))
+		require.Error(t, cmdErr)
+		require.Contains(t, cmdErr.Error(), "resolve field 'document' on DID")
+	})
+}
+
+func query(t *testing.T, cmd *Command, q string, variables map[string]interface{}) *QueryResult {
+	t.Helper()
+
+	req, err := json.Marshal(&QueryArgs{Query: q, Variables: variables})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+
+	cmdErr := cmd.Query(&b, bytes.NewBuffer(req))
+	require.NoError(t, cmdErr)
+
+	var result QueryResult
+
+	require.NoError(t, json.Unmarshal(b.Bytes(), &result))
+
+	return &result
+}
+
+func newMockProvider() *mockprovider.Provider {
+	return &mockprovider.Provider{
+		StorageProviderValue:              mockstore.NewMockStoreProvider(),
+		ProtocolStateStorageProviderValue: mockstore.NewMockStoreProvider(),
+	}
+}
+
+func populateStores(t *testing.T, p *mockprovider.Provider) {
+	t.Helper()
+
+	vcStore, err := verifiablestore.New(p)
+	require.NoError(t, err)
+
+	vc, err := verifiable.ParseCredential([]byte(udCredential),
+		verifiable.WithJSONLDDocumentLoader(examplesContextLoader(t)))
+	require.NoError(t, err)
+	require.NoError(t, vcStore.SaveCredential("degree", vc))
+
+	recorder, err := connection.NewRecorder(p)
+	require.NoError(t, err)
+
+	for i, state := range []string{"completed", "invited", "completed"} {
+		require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+			ConnectionID: fmt.Sprintf("conn-%d", i+1),
+			ThreadID:     fmt.Sprintf("thread-%d", i+1),
+			State:        state,
+			TheirLabel:   fmt.Sprintf("agent-%d", i+1),
+			Namespace:    "my",
+		}))
+	}
+
+	didStore, err := didstore.New(p)
+	require.NoError(t, err)
+
+	doc, err := did.ParseDocument([]byte(didDoc))
+	require.NoError(t, err)
+	require.NoError(t, didStore.SaveDID("my-did", doc))
+}
+
+// examplesContextLoader returns a JSON-LD document loader with the credentials examples context embedded, so that
+// the test credential is parsed offline.
+func examplesContextLoader(t *testing.T) *ld.CachingDocumentLoader {
+	t.Helper()
+
+	const examplesContext = `{
+  "@context": {
+    "@version": 1.1,
+    "ex": "https://example.org/examples#",
+    "schema": "http://schema.org/",
+    "BachelorDegree": "ex:BachelorDegree",
+    "UniversityDegreeCredential": "ex:UniversityDegreeCredential",
+    "degree": "ex:degree",
+    "name": "schema:name"
+  }
+}`
+
+	reader, err := ld.DocumentFromReader(strings.NewReader(examplesContext))
+	require.NoError(t, err)
+
+	loader := verifiable.CachingJSONLDLoader()
+	loader.AddDocument("https://www.w3.org/2018/credentials/examples/v1", reader)
+
+	return loader
+}
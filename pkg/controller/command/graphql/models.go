@@ -0,0 +1,28 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+// QueryArgs model
+//
+// This is used for executing a GraphQL query over wallet contents.
+//
+type QueryArgs struct {
+	// Query is the GraphQL query document.
+	Query string `json:"query"`
+
+	// Variables contains values for the variables referenced in the query.
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// QueryResult model
+//
+// This is used for returning GraphQL query results.
+//
+type QueryResult struct {
+	// Data contains result of each top level field of the query keyed by field alias or name.
+	Data map[string]interface{} `json:"data"`
+}
@@ -0,0 +1,318 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/graphql"
+)
+
+const (
+	// list field arguments.
+	filterArg = "filter"
+	firstArg  = "first"
+	afterArg  = "after"
+
+	// list field selections.
+	totalCountField = "totalCount"
+	pageInfoField   = "pageInfo"
+	nodesField      = "nodes"
+	hasNextField    = "hasNextPage"
+	endCursorField  = "endCursor"
+
+	cursorPrefix = "cursor:"
+)
+
+// node is a single item of a list field, values are either scalars or lazyValue for data requiring a store lookup.
+type node map[string]interface{}
+
+// lazyValue resolves value of a node field only when it has been selected by the query.
+type lazyValue func() (interface{}, error)
+
+// listResolver fetches all nodes of a top level list field, nodes are ordered by sortKey field
+// to keep cursors stable regardless of the iteration order of underlying store.
+type listResolver struct {
+	typeName string
+	sortKey  string
+	fetch    func() ([]node, error)
+}
+
+// resolve filters, paginates and projects nodes returned by the resolver as per given field.
+func (r *listResolver) resolve(field *graphql.Field) (interface{}, error) {
+	for name := range field.Arguments {
+		if name != filterArg && name != firstArg && name != afterArg {
+			return nil, fmt.Errorf("unknown argument '%s' on field '%s'", name, field.Name)
+		}
+	}
+
+	if len(field.Selections) == 0 {
+		return nil, fmt.Errorf("field '%s' must have a selection of subfields", field.Name)
+	}
+
+	nodes, err := r.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return fmt.Sprint(nodes[i][r.sortKey]) < fmt.Sprint(nodes[j][r.sortKey])
+	})
+
+	nodes, err = filterNodes(nodes, field.Arguments[filterArg])
+	if err != nil {
+		return nil, fmt.Errorf("field '%s': %w", field.Name, err)
+	}
+
+	start, end, err := pageBounds(len(nodes), field.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("field '%s': %w", field.Name, err)
+	}
+
+	result := make(map[string]interface{})
+
+	for _, sel := range field.Selections {
+		switch sel.Name {
+		case totalCountField:
+			result[sel.ResponseKey()] = len(nodes)
+		case pageInfoField:
+			pageInfo, e := projectPageInfo(sel, start, end, len(nodes))
+			if e != nil {
+				return nil, e
+			}
+
+			result[sel.ResponseKey()] = pageInfo
+		case nodesField:
+			projected := make([]interface{}, 0, end-start)
+
+			for _, n := range nodes[start:end] {
+				p, e := projectNode(n, sel, r.typeName)
+				if e != nil {
+					return nil, e
+				}
+
+				projected = append(projected, p)
+			}
+
+			result[sel.ResponseKey()] = projected
+		default:
+			return nil, fmt.Errorf("field '%s' is not defined on %s list", sel.Name, r.typeName)
+		}
+	}
+
+	return result, nil
+}
+
+func projectPageInfo(field *graphql.Field, start, end, total int) (map[string]interface{}, error) {
+	if len(field.Selections) == 0 {
+		return nil, fmt.Errorf("field '%s' must have a selection of subfields", field.Name)
+	}
+
+	pageInfo := make(map[string]interface{})
+
+	for _, sel := range field.Selections {
+		switch sel.Name {
+		case hasNextField:
+			pageInfo[sel.ResponseKey()] = end < total
+		case endCursorField:
+			if end > start {
+				pageInfo[sel.ResponseKey()] = encodeCursor(end)
+			} else {
+				pageInfo[sel.ResponseKey()] = nil
+			}
+		default:
+			return nil, fmt.Errorf("field '%s' is not defined on PageInfo", sel.Name)
+		}
+	}
+
+	return pageInfo, nil
+}
+
+func projectNode(n node, field *graphql.Field, typeName string) (map[string]interface{}, error) {
+	if len(field.Selections) == 0 {
+		return nil, fmt.Errorf("field '%s' must have a selection of subfields", field.Name)
+	}
+
+	projected := make(map[string]interface{})
+
+	for _, sel := range field.Selections {
+		value, ok := n[sel.Name]
+		if !ok {
+			return nil, fmt.Errorf("field '%s' is not defined on %s", sel.Name, typeName)
+		}
+
+		if len(sel.Selections) > 0 {
+			return nil, fmt.Errorf("field '%s' on %s must not have a selection", sel.Name, typeName)
+		}
+
+		if lazy, isLazy := value.(lazyValue); isLazy {
+			var err error
+
+			value, err = lazy()
+			if err != nil {
+				return nil, fmt.Errorf("resolve field '%s' on %s: %w", sel.Name, typeName, err)
+			}
+		}
+
+		projected[sel.ResponseKey()] = value
+	}
+
+	return projected, nil
+}
+
+// filterNodes returns nodes matching all criteria of the given filter. String criteria match
+// scalar fields by equality and list fields by containment, boolean criteria match boolean fields.
+// Null criteria are ignored.
+func filterNodes(nodes []node, filter interface{}) ([]node, error) {
+	if filter == nil {
+		return nodes, nil
+	}
+
+	criteria, ok := filter.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("filter must be an input object")
+	}
+
+	var filtered []node
+
+	for _, n := range nodes {
+		match, err := matches(n, criteria)
+		if err != nil {
+			return nil, err
+		}
+
+		if match {
+			filtered = append(filtered, n)
+		}
+	}
+
+	return filtered, nil
+}
+
+func matches(n node, criteria map[string]interface{}) (bool, error) {
+	for name, expected := range criteria {
+		value, ok := n[name]
+		if !ok {
+			return false, fmt.Errorf("unknown filter field '%s'", name)
+		}
+
+		if expected == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			s, isString := expected.(string)
+			if !isString {
+				return false, fmt.Errorf("filter field '%s' must be a string", name)
+			}
+
+			if v != s {
+				return false, nil
+			}
+		case bool:
+			b, isBool := expected.(bool)
+			if !isBool {
+				return false, fmt.Errorf("filter field '%s' must be a boolean", name)
+			}
+
+			if v != b {
+				return false, nil
+			}
+		case []string:
+			s, isString := expected.(string)
+			if !isString {
+				return false, fmt.Errorf("filter field '%s' must be a string", name)
+			}
+
+			if !contains(v, s) {
+				return false, nil
+			}
+		default:
+			return false, fmt.Errorf("filtering by field '%s' is not supported", name)
+		}
+	}
+
+	return true, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pageBounds returns start and end index of the requested page using `first` and `after` arguments.
+func pageBounds(total int, args map[string]interface{}) (int, int, error) {
+	start, end := 0, total
+
+	if after, ok := args[afterArg]; ok && after != nil {
+		cursor, isString := after.(string)
+		if !isString {
+			return 0, 0, fmt.Errorf("'%s' must be a string cursor", afterArg)
+		}
+
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		start = offset
+		if start > total {
+			start = total
+		}
+	}
+
+	if first, ok := args[firstArg]; ok && first != nil {
+		limit, isInt := first.(int)
+		if !isInt {
+			// variables are decoded from JSON as numbers
+			f, isFloat := first.(float64)
+			if !isFloat || f != float64(int(f)) {
+				return 0, 0, fmt.Errorf("'%s' must be an integer", firstArg)
+			}
+
+			limit = int(f)
+		}
+
+		if limit < 0 {
+			return 0, 0, fmt.Errorf("'%s' must not be negative", firstArg)
+		}
+
+		if start+limit < end {
+			end = start + limit
+		}
+	}
+
+	return start, end, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor '%s'", cursor)
+	}
+
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor '%s'", cursor)
+	}
+
+	return offset, nil
+}
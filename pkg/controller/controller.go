@@ -11,6 +11,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
 	didexchangecmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/didexchange"
+	graphqlcmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/graphql"
 	introducecmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/introduce"
 	issuecredentialcmd "github.com/hyperledger/aries-framework-go/pkg/controller/command/issuecredential"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/kms"
@@ -22,6 +23,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/controller/command/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
 	didexchangerest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/didexchange"
+	graphqlrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/graphql"
 	introducerest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/introduce"
 	issuecredentialrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/issuecredential"
 	kmsrest "github.com/hyperledger/aries-framework-go/pkg/controller/rest/kms"
@@ -39,6 +41,7 @@ type allOpts struct {
 	webhookURLs  []string
 	defaultLabel string
 	autoAccept   bool
+	graphQL      bool
 	msgHandler   command.MessageHandler
 	notifier     command.Notifier
 }
@@ -76,6 +79,13 @@ func WithAutoAccept(autoAccept bool) Opt {
 	}
 }
 
+// WithGraphQL is an option allowing to enable GraphQL query API over stored credentials, connections and DIDs.
+func WithGraphQL(enabled bool) Opt {
+	return func(opts *allOpts) {
+		opts.graphQL = enabled
+	}
+}
+
 // WithMessageHandler is an option allowing for the message handler to be set.
 func WithMessageHandler(handler command.MessageHandler) Opt {
 	return func(opts *allOpts) {
@@ -167,6 +177,16 @@ func GetRESTHandlers(ctx *context.Provider, opts ...Opt) ([]rest.Handler, error)
 	allHandlers = append(allHandlers, outofbandOp.GetRESTHandlers()...)
 	allHandlers = append(allHandlers, kmscmd.GetRESTHandlers()...)
 
+	if restAPIOpts.graphQL {
+		// graphql REST operation
+		graphqlOp, err := graphqlrest.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create graphql rest command : %w", err)
+		}
+
+		allHandlers = append(allHandlers, graphqlOp.GetRESTHandlers()...)
+	}
+
 	nhp, ok := notifier.(handlerProvider)
 	if ok {
 		allHandlers = append(allHandlers, nhp.GetRESTHandlers()...)
@@ -262,5 +282,15 @@ func GetCommandHandlers(ctx *context.Provider, opts ...Opt) ([]command.Handler,
 	allHandlers = append(allHandlers, introduce.GetHandlers()...)
 	allHandlers = append(allHandlers, outofband.GetHandlers()...)
 
+	if cmdOpts.graphQL {
+		// graphql command operation
+		graphql, err := graphqlcmd.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("create graphql command : %w", err)
+		}
+
+		allHandlers = append(allHandlers, graphql.GetHandlers()...)
+	}
+
 	return allHandlers, nil
 }
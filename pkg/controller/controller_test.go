@@ -67,6 +67,29 @@ func TestGetCommandHandlers_Success(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, handlers)
 	})
+
+	t.Run("With GraphQL", func(t *testing.T) {
+		framework, err := aries.New(defaults.WithInboundHTTPAddr(":26508", "", "", ""))
+		require.NoError(t, err)
+		require.NotNil(t, framework)
+
+		defer func() { require.NoError(t, framework.Close()) }()
+
+		ctx, err := framework.Context()
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+
+		handlers, err := GetCommandHandlers(ctx, WithGraphQL(true))
+		require.NoError(t, err)
+
+		var found bool
+
+		for _, h := range handlers {
+			found = found || h.Name() == "graphql"
+		}
+
+		require.True(t, found)
+	})
 }
 
 func TestGetRESTHandlers_Success(t *testing.T) {
@@ -102,6 +125,28 @@ func TestGetRESTHandlers_Success(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, handlers)
 	})
+	t.Run("With GraphQL", func(t *testing.T) {
+		framework, err := aries.New(defaults.WithInboundHTTPAddr(":26508", "", "", ""))
+		require.NoError(t, err)
+		require.NotNil(t, framework)
+
+		defer func() { require.NoError(t, framework.Close()) }()
+
+		ctx, err := framework.Context()
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+
+		handlers, err := GetRESTHandlers(ctx, WithGraphQL(true))
+		require.NoError(t, err)
+
+		var found bool
+
+		for _, h := range handlers {
+			found = found || h.Path() == "/graphql"
+		}
+
+		require.True(t, found)
+	})
 }
 
 func TestWithWebhookNotifierOption(t *testing.T) {
@@ -115,6 +160,14 @@ func TestWithWebhookNotifierOption(t *testing.T) {
 	require.Equal(t, webhookURLs, controllerOpts.webhookURLs)
 }
 
+func TestWithGraphQLOption(t *testing.T) {
+	controllerOpts := &allOpts{}
+
+	WithGraphQL(true)(controllerOpts)
+
+	require.True(t, controllerOpts.graphQL)
+}
+
 func TestWithDefaultLabelOption(t *testing.T) {
 	controllerOpts := &allOpts{}
 
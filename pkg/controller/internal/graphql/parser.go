@@ -0,0 +1,517 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxDepth is the maximum nesting depth of selection sets, lists and input objects in a query.
+const MaxDepth = 32
+
+// Field is a single field selection of a GraphQL query.
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]interface{}
+	Selections []*Field
+}
+
+// ResponseKey returns the key under which field value is written in the response (alias if given, otherwise name).
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+// Parse parses given GraphQL query document and returns top level field selections of its single query operation.
+//
+// Only the subset of the GraphQL language required for querying is supported: anonymous or named query operations,
+// aliases, arguments (scalars, enums, lists, input objects and variables) and nested selection sets.
+// Fragments, directives and mutations are not supported. Queries nested deeper than MaxDepth are rejected.
+func Parse(query string, variables map[string]interface{}) ([]*Field, error) {
+	p := &parser{lexer: newLexer(query), variables: variables, declared: make(map[string]bool)}
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName {
+		if p.tok.value != "query" {
+			return nil, fmt.Errorf("unsupported operation type '%s'", p.tok.value)
+		}
+
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		// optional operation name
+		if p.tok.kind == tokenName {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+
+		if p.tok.is(tokenPunct, "(") {
+			if err := p.parseVariableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fields, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected '%s' at position %d after query operation", p.tok.value, p.tok.pos)
+	}
+
+	return fields, nil
+}
+
+type parser struct {
+	lexer     *lexer
+	tok       token
+	variables map[string]interface{}
+	declared  map[string]bool
+	depth     int
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+
+	p.tok = tok
+
+	return nil
+}
+
+// enter enters a nested selection set, list or input object, leave must be called when it's parsed.
+func (p *parser) enter() error {
+	p.depth++
+
+	if p.depth > MaxDepth {
+		return fmt.Errorf("maximum nesting depth %d exceeded at position %d", MaxDepth, p.tok.pos)
+	}
+
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if !p.tok.is(kind, value) {
+		return fmt.Errorf("expected '%s' at position %d but found '%s'", value, p.tok.pos, p.tok.value)
+	}
+
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", fmt.Errorf("expected name at position %d but found '%s'", p.tok.pos, p.tok.value)
+	}
+
+	name := p.tok.value
+
+	return name, p.advance()
+}
+
+// parseVariableDefinitions records variables declared by the operation, types and default values are skipped
+// and values are taken from supplied variables. Declared variables which are not supplied resolve to null.
+func (p *parser) parseVariableDefinitions() error {
+	for !p.tok.is(tokenPunct, ")") {
+		if p.tok.kind == tokenEOF {
+			return fmt.Errorf("unterminated variable definitions")
+		}
+
+		if p.tok.kind == tokenVariable {
+			p.declared[p.tok.value] = true
+		}
+
+		if err := p.advance(); err != nil {
+			return err
+		}
+	}
+
+	return p.advance()
+}
+
+func (p *parser) parseSelectionSet() ([]*Field, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
+	defer p.leave()
+
+	if err := p.expect(tokenPunct, "{"); err != nil {
+		return nil, err
+	}
+
+	var fields []*Field
+
+	for !p.tok.is(tokenPunct, "}") {
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty selection set at position %d", p.tok.pos)
+	}
+
+	return fields, p.advance()
+}
+
+func (p *parser) parseField() (*Field, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{Name: name}
+
+	if p.tok.is(tokenPunct, ":") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+
+		field.Alias = name
+
+		field.Name, err = p.expectName()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.tok.is(tokenPunct, "(") {
+		field.Arguments, err = p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.tok.is(tokenPunct, "{") {
+		field.Selections, err = p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseArguments() (map[string]interface{}, error) {
+	if err := p.expect(tokenPunct, "("); err != nil {
+		return nil, err
+	}
+
+	return p.parseKeyValues(")")
+}
+
+// parseKeyValues parses `name: value` pairs up to and including given closing punctuator.
+func (p *parser) parseKeyValues(closing string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	for !p.tok.is(tokenPunct, closing) {
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+
+		values[name], err = p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, p.advance()
+}
+
+func (p *parser) parseValue() (interface{}, error) { //nolint:gocyclo
+	tok := p.tok
+
+	switch {
+	case tok.kind == tokenString:
+		return tok.value, p.advance()
+	case tok.kind == tokenInt:
+		v, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int value '%s': %w", tok.value, err)
+		}
+
+		return v, p.advance()
+	case tok.kind == tokenFloat:
+		v, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float value '%s': %w", tok.value, err)
+		}
+
+		return v, p.advance()
+	case tok.kind == tokenVariable:
+		v, ok := p.variables[tok.value]
+		if !ok && !p.declared[tok.value] {
+			return nil, fmt.Errorf("variable '$%s' is not provided", tok.value)
+		}
+
+		return v, p.advance()
+	case tok.kind == tokenName:
+		return nameValue(tok.value), p.advance()
+	case tok.is(tokenPunct, "["):
+		return p.parseList()
+	case tok.is(tokenPunct, "{"):
+		return p.parseObject()
+	default:
+		return nil, fmt.Errorf("unexpected '%s' at position %d", tok.value, tok.pos)
+	}
+}
+
+func (p *parser) parseObject() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
+	defer p.leave()
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	return p.parseKeyValues("}")
+}
+
+func (p *parser) parseList() (interface{}, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
+	defer p.leave()
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var list []interface{}
+
+	for !p.tok.is(tokenPunct, "]") {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, v)
+	}
+
+	return list, p.advance()
+}
+
+// nameValue converts boolean, null and enum literals.
+func nameValue(name string) interface{} {
+	switch name {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	default:
+		return name
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenVariable
+	tokenString
+	tokenInt
+	tokenFloat
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) is(kind tokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+
+	switch {
+	case strings.IndexByte("{}()[]:!=", c) >= 0:
+		l.pos++
+
+		return token{kind: tokenPunct, value: string(c), pos: start}, nil
+	case c == '$':
+		l.pos++
+
+		name := l.readName()
+		if name == "" {
+			return token{}, fmt.Errorf("invalid variable at position %d", start)
+		}
+
+		return token{kind: tokenVariable, value: name, pos: start}, nil
+	case c == '"':
+		return l.readString()
+	case c == '-' || isDigit(c):
+		return l.readNumber()
+	case isNameStart(c):
+		return token{kind: tokenName, value: l.readName(), pos: start}, nil
+	default:
+		return token{}, fmt.Errorf("unexpected character '%c' at position %d", c, start)
+	}
+}
+
+// skipIgnored skips white spaces, commas and comments.
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) readName() string {
+	start := l.pos
+
+	for l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+		l.pos++
+	}
+
+	return l.src[start:l.pos]
+}
+
+func (l *lexer) readNumber() (token, error) {
+	start := l.pos
+	kind := tokenInt
+
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch {
+		case isDigit(c):
+		case c == '.' || c == 'e' || c == 'E' || c == '+' || (c == '-' && kind == tokenFloat):
+			kind = tokenFloat
+		default:
+			return l.numberToken(kind, start)
+		}
+
+		l.pos++
+	}
+
+	return l.numberToken(kind, start)
+}
+
+func (l *lexer) numberToken(kind tokenKind, start int) (token, error) {
+	value := l.src[start:l.pos]
+	if value == "-" {
+		return token{}, fmt.Errorf("invalid number at position %d", start)
+	}
+
+	return token{kind: kind, value: value, pos: start}, nil
+}
+
+func (l *lexer) readString() (token, error) {
+	start := l.pos
+	l.pos++
+
+	var sb strings.Builder
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		switch c {
+		case '"':
+			l.pos++
+
+			return token{kind: tokenString, value: sb.String(), pos: start}, nil
+		case '\n':
+			return token{}, fmt.Errorf("unterminated string at position %d", start)
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, fmt.Errorf("unterminated string at position %d", start)
+			}
+
+			l.pos++
+
+			escaped, ok := escapes[l.src[l.pos]]
+			if !ok {
+				return token{}, fmt.Errorf("invalid escape sequence at position %d", l.pos-1)
+			}
+
+			sb.WriteByte(escaped)
+		default:
+			sb.WriteByte(c)
+		}
+
+		l.pos++
+	}
+
+	return token{}, fmt.Errorf("unterminated string at position %d", start)
+}
+
+// nolint:gochecknoglobals
+var escapes = map[byte]byte{'"': '"', '\\': '\\', '/': '/', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t'}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
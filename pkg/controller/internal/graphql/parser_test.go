@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("test parse - anonymous query", func(t *testing.T) {
+		fields, err := Parse(`{ credentials { nodes { id name } } }`, nil)
+		require.NoError(t, err)
+		require.Len(t, fields, 1)
+		require.Equal(t, "credentials", fields[0].Name)
+		require.Equal(t, "credentials", fields[0].ResponseKey())
+		require.Len(t, fields[0].Selections, 1)
+		require.Equal(t, "nodes", fields[0].Selections[0].Name)
+		require.Len(t, fields[0].Selections[0].Selections, 2)
+	})
+
+	t.Run("test parse - named query with aliases, arguments and comments", func(t *testing.T) {
+		fields, err := Parse(`
+			# wallet contents
+			query Wallet($after: String) {
+				vcs: credentials(first: 10, after: $after, filter: {type: "UniversityDegreeCredential"}) {
+					totalCount
+				}
+				dids(filter: {name: "my \"did\""}, flags: [true, false, null], ratio: -1.5e2, order: ASC) {
+					nodes { id }
+				}
+			}`, map[string]interface{}{"after": "MQ=="})
+		require.NoError(t, err)
+		require.Len(t, fields, 2)
+
+		require.Equal(t, "vcs", fields[0].ResponseKey())
+		require.Equal(t, "credentials", fields[0].Name)
+		require.Equal(t, 10, fields[0].Arguments["first"])
+		require.Equal(t, "MQ==", fields[0].Arguments["after"])
+		require.Equal(t, map[string]interface{}{"type": "UniversityDegreeCredential"}, fields[0].Arguments["filter"])
+
+		require.Equal(t, map[string]interface{}{"name": `my "did"`}, fields[1].Arguments["filter"])
+		require.Equal(t, []interface{}{true, false, nil}, fields[1].Arguments["flags"])
+		require.Equal(t, -150.0, fields[1].Arguments["ratio"])
+		require.Equal(t, "ASC", fields[1].Arguments["order"])
+	})
+
+	t.Run("test parse - declared variable without value", func(t *testing.T) {
+		fields, err := Parse(`query q($after: String) { dids(after: $after) { totalCount } }`, nil)
+		require.NoError(t, err)
+		require.Len(t, fields, 1)
+		require.Contains(t, fields[0].Arguments, "after")
+		require.Nil(t, fields[0].Arguments["after"])
+	})
+
+	t.Run("test parse - maximum nesting depth", func(t *testing.T) {
+		nested := func(depth int, open, value, closing string) string {
+			return strings.Repeat(open, depth) + value + strings.Repeat(closing, depth)
+		}
+
+		_, err := Parse(nested(MaxDepth, "{ a ", "", "}"), nil)
+		require.NoError(t, err)
+
+		_, err = Parse(`{ a(x: `+nested(MaxDepth-1, "[", "1", "]")+`) }`, nil)
+		require.NoError(t, err)
+
+		for _, query := range []string{
+			nested(MaxDepth+1, "{ a ", "", "}"),
+			`{ a(x: ` + nested(MaxDepth, "[", "1", "]") + `) }`,
+			`{ a(x: ` + nested(MaxDepth, "{ y: ", "1", "}") + `) }`,
+			`{ a(x: ` + strings.Repeat("[", 100000),
+		} {
+			_, err = Parse(query, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "maximum nesting depth 32 exceeded")
+		}
+	})
+
+	t.Run("test parse - errors", func(t *testing.T) {
+		tests := []struct {
+			query string
+			err   string
+		}{
+			{query: `mutation { x }`, err: "unsupported operation type 'mutation'"},
+			{query: `{ }`, err: "empty selection set"},
+			{query: `{ a } b`, err: "unexpected 'b'"},
+			{query: `{ a(x: $y) }`, err: "variable '$y' is not provided"},
+			{query: `{ a(x: "y) }`, err: "unterminated string"},
+			{query: `{ a(x: "\q") }`, err: "invalid escape sequence"},
+			{query: `{ a(x: -) }`, err: "invalid number"},
+			{query: `{ a(x: 1..2) }`, err: "invalid float value"},
+			{query: `{ a(x: }`, err: "unexpected '}'"},
+			{query: `{ a(: 1) }`, err: "expected name"},
+			{query: `{ a(x 1) }`, err: "expected ':'"},
+			{query: `{ a(x: $) }`, err: "invalid variable"},
+			{query: `{ a % }`, err: "unexpected character '%'"},
+			{query: `query q($a: Int`, err: "unterminated variable definitions"},
+			{query: `a`, err: "unsupported operation type 'a'"},
+		}
+
+		for _, tc := range tests {
+			_, err := Parse(tc.query, nil)
+			require.Error(t, err, tc.query)
+			require.Contains(t, err.Error(), tc.err, tc.query)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+)
+
+// APIKeyHeader is the request header carrying the API key.
+const APIKeyHeader = "X-API-Key"
+
+type apiKey struct {
+	hash     [sha256.Size]byte
+	tenantID string
+}
+
+// APIKeyAuthenticator authenticates requests with API keys issued to tenants.
+type APIKeyAuthenticator struct {
+	keys []apiKey
+}
+
+// NewAPIKeyAuthenticator creates a new APIKeyAuthenticator from a map of API keys to the IDs of the tenants
+// they were issued to.
+func NewAPIKeyAuthenticator(keys map[string]string) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{}
+
+	for key, tenantID := range keys {
+		if key == "" || tenantID == "" {
+			return nil, errors.New("API key and tenant ID are mandatory")
+		}
+
+		a.keys = append(a.keys, apiKey{hash: sha256.Sum256([]byte(key)), tenantID: tenantID})
+	}
+
+	return a, nil
+}
+
+// Authenticate authenticates the request with the API key in the X-API-Key header.
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// compare hashes of all keys in constant time so that neither key content nor key position leak
+	hash := sha256.Sum256([]byte(key))
+
+	var match *apiKey
+
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], a.keys[i].hash[:]) == 1 {
+			match = &a.keys[i]
+		}
+	}
+
+	if match == nil {
+		return nil, errors.New("invalid API key")
+	}
+
+	return &Principal{
+		Subject:  "api-key:" + hex.EncodeToString(match.hash[:4]),
+		TenantID: match.tenantID,
+		Method:   MethodAPIKey,
+	}, nil
+}
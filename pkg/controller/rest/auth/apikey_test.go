@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator(map[string]string{
+		"acme-key":   "acme",
+		"acme-key-2": "acme",
+		"globex-key": "globex",
+	})
+	require.NoError(t, err)
+
+	newRequest := func(key string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/connections", nil)
+
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+
+		return req
+	}
+
+	t.Run("test valid keys", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newRequest("acme-key"))
+		require.NoError(t, err)
+		require.Equal(t, "acme", principal.TenantID)
+		require.Equal(t, MethodAPIKey, principal.Method)
+		require.Contains(t, principal.Subject, "api-key:")
+		require.NotContains(t, principal.Subject, "acme-key")
+
+		other, err := authenticator.Authenticate(newRequest("acme-key-2"))
+		require.NoError(t, err)
+		require.Equal(t, "acme", other.TenantID)
+		require.NotEqual(t, principal.Subject, other.Subject)
+
+		principal, err = authenticator.Authenticate(newRequest("globex-key"))
+		require.NoError(t, err)
+		require.Equal(t, "globex", principal.TenantID)
+	})
+
+	t.Run("test missing key", func(t *testing.T) {
+		_, err := authenticator.Authenticate(newRequest(""))
+		require.True(t, errors.Is(err, ErrNoCredentials))
+	})
+
+	t.Run("test invalid key", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newRequest("acme-key-3"))
+		require.EqualError(t, err, "invalid API key")
+		require.Nil(t, principal)
+	})
+
+	t.Run("test invalid configuration", func(t *testing.T) {
+		_, err := NewAPIKeyAuthenticator(map[string]string{"": "acme"})
+		require.EqualError(t, err, "API key and tenant ID are mandatory")
+
+		_, err = NewAPIKeyAuthenticator(map[string]string{"key": ""})
+		require.EqualError(t, err, "API key and tenant ID are mandatory")
+	})
+}
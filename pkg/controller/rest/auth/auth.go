@@ -0,0 +1,133 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package auth provides authentication of REST controller requests with API keys or OIDC bearer tokens, and
+// routing of authenticated requests to the agent of the caller's tenant. Together with tenant scoped storage
+// (see pkg/storage/wrapper/tenant) it allows one hosted agent instance to serve multiple organizations.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+)
+
+var logger = log.New("aries-framework/rest/auth")
+
+// Method is the method used to authenticate a principal.
+type Method string
+
+const (
+	// MethodAPIKey is used for principals authenticated with an API key.
+	MethodAPIKey Method = "api-key"
+	// MethodOIDC is used for principals authenticated with an OIDC bearer token.
+	MethodOIDC Method = "oidc"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request doesn't carry credentials it handles, so
+// that the next authenticator can be tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is the authenticated caller of the REST API.
+type Principal struct {
+	Subject  string
+	TenantID string
+	Method   Method
+}
+
+// Authenticator authenticates REST API requests.
+type Authenticator interface {
+	// Authenticate returns the principal of the request, ErrNoCredentials if the request doesn't carry
+	// credentials for this authenticator, or another error if the credentials are invalid.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the context which carries the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of an authenticated request.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+
+	return principal, ok && principal != nil
+}
+
+// Middleware returns HTTP middleware which authenticates requests with the first authenticator which finds its
+// credentials in the request and stores the principal in the request context. Requests without valid
+// credentials are rejected with 401 Unauthorized.
+func Middleware(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(r)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+
+				if err != nil {
+					logger.Warnf("rejected request to %s: %s", r.URL.Path, err)
+
+					break
+				}
+
+				next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeStatus(w, http.StatusUnauthorized, "Unauthorised.\n")
+		})
+	}
+}
+
+// TenantRouter dispatches authenticated requests to the handler of the principal's tenant.
+type TenantRouter struct {
+	handlers map[string]http.Handler
+}
+
+// NewTenantRouter creates a new TenantRouter with handlers per tenant ID.
+func NewTenantRouter(handlers map[string]http.Handler) *TenantRouter {
+	router := &TenantRouter{handlers: make(map[string]http.Handler, len(handlers))}
+
+	for tenantID, handler := range handlers {
+		router.handlers[tenantID] = handler
+	}
+
+	return router
+}
+
+// ServeHTTP serves the request with the handler of the principal's tenant. Requests without a principal
+// are rejected with 401 Unauthorized, requests of unknown tenants with 403 Forbidden.
+func (t *TenantRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		writeStatus(w, http.StatusUnauthorized, "Unauthorised.\n")
+
+		return
+	}
+
+	handler, ok := t.handlers[principal.TenantID]
+	if !ok {
+		logger.Warnf("principal '%s' belongs to unknown tenant '%s'", principal.Subject, principal.TenantID)
+		writeStatus(w, http.StatusForbidden, "Forbidden.\n")
+
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+func writeStatus(w http.ResponseWriter, status int, msg string) {
+	w.WriteHeader(status)
+	w.Write([]byte(msg)) // nolint:gosec,errcheck
+}
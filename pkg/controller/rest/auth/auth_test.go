@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockAuthenticator struct {
+	principal *Principal
+	err       error
+}
+
+func (m *mockAuthenticator) Authenticate(*http.Request) (*Principal, error) {
+	return m.principal, m.err
+}
+
+func tenantHandler(tenantID string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok || principal.TenantID != tenantID {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		w.Write([]byte(tenantID)) // nolint:errcheck,gosec
+	})
+}
+
+func serve(handler http.Handler) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/connections", nil))
+
+	return rr
+}
+
+func TestMiddleware(t *testing.T) {
+	acme := &Principal{Subject: "alice", TenantID: "acme", Method: MethodOIDC}
+
+	t.Run("test first authenticator with credentials is used", func(t *testing.T) {
+		handler := Middleware(
+			&mockAuthenticator{err: ErrNoCredentials},
+			&mockAuthenticator{principal: acme},
+			&mockAuthenticator{err: errors.New("not reached")},
+		)(tenantHandler("acme"))
+
+		rr := serve(handler)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "acme", rr.Body.String())
+	})
+
+	t.Run("test no credentials", func(t *testing.T) {
+		rr := serve(Middleware(&mockAuthenticator{err: ErrNoCredentials})(tenantHandler("acme")))
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		require.Equal(t, "Bearer", rr.Header().Get("WWW-Authenticate"))
+		require.Equal(t, "Unauthorised.\n", rr.Body.String())
+
+		rr = serve(Middleware()(tenantHandler("acme")))
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("test invalid credentials are not passed to other authenticators", func(t *testing.T) {
+		handler := Middleware(
+			&mockAuthenticator{err: errors.New("invalid API key")},
+			&mockAuthenticator{principal: acme},
+		)(tenantHandler("acme"))
+
+		rr := serve(handler)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestPrincipalFromContext(t *testing.T) {
+	_, ok := PrincipalFromContext(context.Background())
+	require.False(t, ok)
+
+	_, ok = PrincipalFromContext(WithPrincipal(context.Background(), nil))
+	require.False(t, ok)
+
+	principal := &Principal{Subject: "alice", TenantID: "acme"}
+
+	p, ok := PrincipalFromContext(WithPrincipal(context.Background(), principal))
+	require.True(t, ok)
+	require.Equal(t, principal, p)
+}
+
+func TestTenantRouter(t *testing.T) {
+	handlers := map[string]http.Handler{
+		"acme":   tenantHandler("acme"),
+		"globex": tenantHandler("globex"),
+	}
+
+	router := NewTenantRouter(handlers)
+
+	// later changes of the map don't affect the router
+	delete(handlers, "globex")
+
+	for _, tenantID := range []string{"acme", "globex"} {
+		rr := serve(Middleware(&mockAuthenticator{principal: &Principal{TenantID: tenantID}})(router))
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, tenantID, rr.Body.String())
+	}
+
+	rr := serve(Middleware(&mockAuthenticator{principal: &Principal{TenantID: "initech"}})(router))
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Equal(t, "Forbidden.\n", rr.Body.String())
+
+	rr = serve(router)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// minRefreshInterval limits how often the key set is fetched again for tokens signed with unknown keys.
+	minRefreshInterval = time.Minute
+)
+
+// JWKSKeyResolver resolves token signing keys of an OpenID Provider from the JSON Web Key Set referenced by
+// the jwks_uri of its metadata. Keys are cached and the key set is fetched again when a token is signed with
+// an unknown key, to follow key rotation of the provider.
+type JWKSKeyResolver struct {
+	issuer      string
+	client      *http.Client
+	keys        map[string]*jose.JWK
+	lastRefresh time.Time
+	lock        sync.Mutex
+}
+
+// NewJWKSKeyResolver creates a new JWKSKeyResolver for the issuer.
+func NewJWKSKeyResolver(issuer string, client *http.Client) *JWKSKeyResolver {
+	return &JWKSKeyResolver{issuer: issuer, client: client}
+}
+
+// Resolve resolves the public key of the issuer with key ID kid. An empty kid is allowed only if the key set
+// holds a single key.
+func (r *JWKSKeyResolver) Resolve(issuer, kid string) (*verifier.PublicKey, error) {
+	if issuer != r.issuer {
+		return nil, fmt.Errorf("untrusted issuer '%s'", issuer)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := r.lookup(kid)
+	if key == nil && time.Since(r.lastRefresh) >= minRefreshInterval {
+		if err := r.refresh(); err != nil {
+			return nil, err
+		}
+
+		key = r.lookup(kid)
+	}
+
+	if key == nil {
+		return nil, fmt.Errorf("signing key '%s' of issuer '%s' not found", kid, issuer)
+	}
+
+	value, err := key.PublicKeyBytes()
+	if err != nil {
+		return nil, fmt.Errorf("signing key '%s': %w", kid, err)
+	}
+
+	return &verifier.PublicKey{Type: key.Kty, Value: value, JWK: key}, nil
+}
+
+func (r *JWKSKeyResolver) lookup(kid string) *jose.JWK {
+	if kid == "" && len(r.keys) == 1 {
+		for _, key := range r.keys {
+			return key
+		}
+	}
+
+	return r.keys[kid]
+}
+
+func (r *JWKSKeyResolver) refresh() error {
+	r.lastRefresh = time.Now()
+
+	metadata := struct {
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+
+	err := r.getJSON(strings.TrimSuffix(r.issuer, "/")+discoveryPath, &metadata)
+	if err != nil {
+		return fmt.Errorf("fetch OpenID Provider metadata: %w", err)
+	}
+
+	if metadata.JWKSURI == "" {
+		return fmt.Errorf("OpenID Provider metadata of '%s' has no jwks_uri", r.issuer)
+	}
+
+	keySet := struct {
+		Keys []*jose.JWK `json:"keys"`
+	}{}
+
+	err = r.getJSON(metadata.JWKSURI, &keySet)
+	if err != nil {
+		return fmt.Errorf("fetch JSON Web Key Set: %w", err)
+	}
+
+	r.keys = make(map[string]*jose.JWK, len(keySet.Keys))
+
+	for _, key := range keySet.Keys {
+		r.keys[key.KeyID] = key
+	}
+
+	return nil
+}
+
+func (r *JWKSKeyResolver) getJSON(url string, v interface{}) error {
+	resp, err := r.client.Get(url) // nolint:noctx
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logger.Warnf("failed to close response body: %s", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response of %s: %w", url, err)
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return fmt.Errorf("unmarshal response of %s: %w", url, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+)
+
+type testOP struct {
+	*httptest.Server
+
+	keys         map[string]ed25519.PublicKey
+	jwksRequests int
+	lock         sync.Mutex
+}
+
+func newTestOP(t *testing.T, keys map[string]ed25519.PublicKey) *testOP {
+	t.Helper()
+
+	op := &testOP{keys: keys}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issuer":"%s","jwks_uri":"%s/jwks"}`, op.URL, op.URL)
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		op.lock.Lock()
+		defer op.lock.Unlock()
+
+		op.jwksRequests++
+
+		keySet := struct {
+			Keys []*jose.JWK `json:"keys"`
+		}{}
+
+		for kid, pubKey := range op.keys {
+			jwk, err := jose.JWKFromPublicKey(pubKey)
+			require.NoError(t, err)
+
+			jwk.KeyID = kid
+			keySet.Keys = append(keySet.Keys, jwk)
+		}
+
+		require.NoError(t, json.NewEncoder(w).Encode(keySet))
+	})
+
+	op.Server = httptest.NewServer(mux)
+
+	return op
+}
+
+func (op *testOP) setKeys(keys map[string]ed25519.PublicKey) {
+	op.lock.Lock()
+	op.keys = keys
+	op.lock.Unlock()
+}
+
+func TestJWKSKeyResolver(t *testing.T) {
+	pubKey1, _ := newKeyPair(t)
+	pubKey2, _ := newKeyPair(t)
+
+	t.Run("test resolve keys with caching and key rotation", func(t *testing.T) {
+		op := newTestOP(t, map[string]ed25519.PublicKey{"key-1": pubKey1})
+		defer op.Close()
+
+		resolver := NewJWKSKeyResolver(op.URL+"/", op.Client())
+
+		key, err := resolver.Resolve(op.URL+"/", "key-1")
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey1), key.Value)
+		require.Equal(t, "OKP", key.Type)
+		require.NotNil(t, key.JWK)
+
+		// single key may be used without kid
+		key, err = resolver.Resolve(op.URL+"/", "")
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey1), key.Value)
+		require.Equal(t, 1, op.jwksRequests)
+
+		op.setKeys(map[string]ed25519.PublicKey{"key-1": pubKey1, "key-2": pubKey2})
+
+		// key set isn't fetched again before the refresh interval passes
+		_, err = resolver.Resolve(op.URL+"/", "key-2")
+		require.EqualError(t, err, fmt.Sprintf("signing key 'key-2' of issuer '%s/' not found", op.URL))
+		require.Equal(t, 1, op.jwksRequests)
+
+		resolver.lastRefresh = time.Now().Add(-minRefreshInterval)
+
+		key, err = resolver.Resolve(op.URL+"/", "key-2")
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey2), key.Value)
+		require.Equal(t, 2, op.jwksRequests)
+
+		// kid is mandatory with several keys
+		resolver.lastRefresh = time.Now()
+
+		_, err = resolver.Resolve(op.URL+"/", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("test untrusted issuer", func(t *testing.T) {
+		resolver := NewJWKSKeyResolver("https://op.example.com", http.DefaultClient)
+
+		_, err := resolver.Resolve("https://evil.example.com", "key-1")
+		require.EqualError(t, err, "untrusted issuer 'https://evil.example.com'")
+	})
+
+	t.Run("test fetch errors", func(t *testing.T) {
+		handlers := map[string]http.HandlerFunc{
+			"metadata not found": func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			"invalid metadata": func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("{")) // nolint:errcheck,gosec
+			},
+			"no jwks_uri": func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("{}")) // nolint:errcheck,gosec
+			},
+			"invalid key set": func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == discoveryPath {
+					fmt.Fprintf(w, `{"jwks_uri":"http://%s/jwks"}`, r.Host)
+
+					return
+				}
+
+				w.Write([]byte(`{"keys":[{"kty":"unknown"}]}`)) // nolint:errcheck,gosec
+			},
+		}
+
+		expected := map[string]string{
+			"metadata not found": "unexpected status 404",
+			"invalid metadata":   "unmarshal response",
+			"no jwks_uri":        "has no jwks_uri",
+			"invalid key set":    "fetch JSON Web Key Set",
+		}
+
+		for name, handler := range handlers {
+			server := httptest.NewServer(handler)
+
+			resolver := NewJWKSKeyResolver(server.URL, server.Client())
+
+			_, err := resolver.Resolve(server.URL, "key-1")
+			require.Error(t, err, name)
+			require.Contains(t, err.Error(), expected[name], name)
+
+			server.Close()
+		}
+
+		resolver := NewJWKSKeyResolver("http://127.0.0.1:0", http.DefaultClient)
+
+		_, err := resolver.Resolve("http://127.0.0.1:0", "key-1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "fetch OpenID Provider metadata")
+	})
+}
@@ -0,0 +1,158 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	josejwt "github.com/square/go-jose/v3/jwt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+)
+
+const (
+	// DefaultTenantClaim is the name of the token claim holding the tenant ID.
+	DefaultTenantClaim = "tenant"
+
+	bearerPrefix  = "Bearer "
+	defaultLeeway = time.Minute
+)
+
+// OIDCAuthenticator authenticates requests with OIDC bearer tokens (signed JWTs) issued by a trusted issuer.
+// The tenant of the principal is taken from the tenant claim of the token.
+type OIDCAuthenticator struct {
+	issuer      string
+	audience    string
+	tenantClaim string
+	resolver    jwt.KeyResolver
+	httpClient  *http.Client
+	leeway      time.Duration
+	now         func() time.Time
+}
+
+// OIDCOpt configures the OIDCAuthenticator.
+type OIDCOpt func(a *OIDCAuthenticator)
+
+// WithTenantClaim sets the name of the token claim holding the tenant ID (defaults to "tenant").
+func WithTenantClaim(name string) OIDCOpt {
+	return func(a *OIDCAuthenticator) {
+		a.tenantClaim = name
+	}
+}
+
+// WithKeyResolver sets the resolver of the issuer's token signing keys. By default the keys are fetched from
+// the JSON Web Key Set announced in the OpenID Provider metadata of the issuer.
+func WithKeyResolver(resolver jwt.KeyResolver) OIDCOpt {
+	return func(a *OIDCAuthenticator) {
+		a.resolver = resolver
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to fetch the issuer's metadata and keys.
+func WithHTTPClient(client *http.Client) OIDCOpt {
+	return func(a *OIDCAuthenticator) {
+		a.httpClient = client
+	}
+}
+
+// WithLeeway sets the clock skew tolerated when validating token times (defaults to one minute).
+func WithLeeway(leeway time.Duration) OIDCOpt {
+	return func(a *OIDCAuthenticator) {
+		a.leeway = leeway
+	}
+}
+
+// NewOIDCAuthenticator creates a new OIDCAuthenticator accepting tokens of the issuer for the audience.
+func NewOIDCAuthenticator(issuer, audience string, opts ...OIDCOpt) (*OIDCAuthenticator, error) {
+	if issuer == "" || audience == "" {
+		return nil, errors.New("OIDC issuer and audience are mandatory")
+	}
+
+	a := &OIDCAuthenticator{
+		issuer:      issuer,
+		audience:    audience,
+		tenantClaim: DefaultTenantClaim,
+		httpClient:  http.DefaultClient,
+		leeway:      defaultLeeway,
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.resolver == nil {
+		a.resolver = NewJWKSKeyResolver(issuer, a.httpClient)
+	}
+
+	return a, nil
+}
+
+// Authenticate authenticates the request with the bearer token in the Authorization header.
+func (a *OIDCAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	hdr := r.Header.Get("Authorization")
+	if !strings.HasPrefix(hdr, bearerPrefix) {
+		return nil, ErrNoCredentials
+	}
+
+	token, err := jwt.Parse(strings.TrimPrefix(hdr, bearerPrefix),
+		jwt.WithSignatureVerifier(jwt.NewVerifier(a.resolver)))
+	if err != nil {
+		return nil, fmt.Errorf("parse bearer token: %w", err)
+	}
+
+	claims := &josejwt.Claims{}
+
+	err = token.DecodeClaims(claims)
+	if err != nil {
+		return nil, fmt.Errorf("decode bearer token claims: %w", err)
+	}
+
+	if claims.Expiry == nil {
+		return nil, errors.New("bearer token has no expiry")
+	}
+
+	err = claims.ValidateWithLeeway(josejwt.Expected{
+		Issuer:   a.issuer,
+		Audience: josejwt.Audience{a.audience},
+		Time:     a.now(),
+	}, a.leeway)
+	if err != nil {
+		return nil, fmt.Errorf("validate bearer token claims: %w", err)
+	}
+
+	tenantID, err := a.tenantID(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		Subject:  claims.Subject,
+		TenantID: tenantID,
+		Method:   MethodOIDC,
+	}, nil
+}
+
+func (a *OIDCAuthenticator) tenantID(token *jwt.JSONWebToken) (string, error) {
+	claims := make(map[string]interface{})
+
+	err := token.DecodeClaims(&claims)
+	if err != nil {
+		return "", fmt.Errorf("decode bearer token claims: %w", err)
+	}
+
+	tenantID, ok := claims[a.tenantClaim].(string)
+	if !ok || tenantID == "" {
+		return "", fmt.Errorf("bearer token has no '%s' claim", a.tenantClaim)
+	}
+
+	return tenantID, nil
+}
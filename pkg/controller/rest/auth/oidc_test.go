@@ -0,0 +1,237 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	josejwt "github.com/square/go-jose/v3/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+const (
+	testAudience = "https://agent.example.com"
+	testKeyID    = "key-1"
+)
+
+type ed25519Signer struct {
+	privKey ed25519.PrivateKey
+	kid     string
+}
+
+func (s *ed25519Signer) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.privKey, data), nil
+}
+
+func (s *ed25519Signer) Headers() jose.Headers {
+	return jose.Headers{jose.HeaderAlgorithm: "EdDSA", jose.HeaderKeyID: s.kid}
+}
+
+type tokenClaims struct {
+	*josejwt.Claims
+
+	Tenant string `json:"tenant,omitempty"`
+	Org    string `json:"org,omitempty"`
+}
+
+func newKeyPair(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return pubKey, privKey
+}
+
+func newToken(t *testing.T, privKey ed25519.PrivateKey, kid string, claims *tokenClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewSigned(claims, nil, &ed25519Signer{privKey: privKey, kid: kid})
+	require.NoError(t, err)
+
+	serialized, err := token.Serialize(false)
+	require.NoError(t, err)
+
+	return serialized
+}
+
+func newClaims(issuer, tenant string) *tokenClaims {
+	return &tokenClaims{
+		Claims: &josejwt.Claims{
+			Issuer:   issuer,
+			Subject:  "alice",
+			Audience: josejwt.Audience{testAudience},
+			Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt: josejwt.NewNumericDate(time.Now()),
+		},
+		Tenant: tenant,
+	}
+}
+
+func newBearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/connections", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	const issuer = "https://op.example.com"
+
+	pubKey, privKey := newKeyPair(t)
+
+	resolver := jwt.KeyResolverFunc(func(what, kid string) (*verifier.PublicKey, error) {
+		if what != issuer || kid != testKeyID {
+			return nil, errors.New("key not found")
+		}
+
+		return &verifier.PublicKey{Type: "OKP", Value: pubKey}, nil
+	})
+
+	authenticator, err := NewOIDCAuthenticator(issuer, testAudience, WithKeyResolver(resolver))
+	require.NoError(t, err)
+
+	t.Run("test valid token", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(newBearerRequest(newToken(t, privKey, testKeyID,
+			newClaims(issuer, "acme"))))
+		require.NoError(t, err)
+		require.Equal(t, &Principal{Subject: "alice", TenantID: "acme", Method: MethodOIDC}, principal)
+	})
+
+	t.Run("test custom tenant claim", func(t *testing.T) {
+		a, err := NewOIDCAuthenticator(issuer, testAudience, WithKeyResolver(resolver), WithTenantClaim("org"))
+		require.NoError(t, err)
+
+		claims := newClaims(issuer, "acme")
+		claims.Org = "globex"
+
+		principal, err := a.Authenticate(newBearerRequest(newToken(t, privKey, testKeyID, claims)))
+		require.NoError(t, err)
+		require.Equal(t, "globex", principal.TenantID)
+	})
+
+	t.Run("test no bearer token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/connections", nil)
+
+		_, err := authenticator.Authenticate(req)
+		require.True(t, errors.Is(err, ErrNoCredentials))
+
+		req.Header.Set("Authorization", "Basic YWxpY2U6c2VjcmV0")
+
+		_, err = authenticator.Authenticate(req)
+		require.True(t, errors.Is(err, ErrNoCredentials))
+	})
+
+	t.Run("test invalid tokens", func(t *testing.T) {
+		_, otherPrivKey := newKeyPair(t)
+
+		expired := newClaims(issuer, "acme")
+		expired.Expiry = josejwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+		noExpiry := newClaims(issuer, "acme")
+		noExpiry.Expiry = nil
+
+		otherAudience := newClaims(issuer, "acme")
+		otherAudience.Audience = josejwt.Audience{"https://other.example.com"}
+
+		unsecured, err := jwt.NewUnsecured(newClaims(issuer, "acme"), nil)
+		require.NoError(t, err)
+
+		unsecuredToken, err := unsecured.Serialize(false)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name  string
+			token string
+			err   string
+		}{
+			{name: "malformed", token: "not-a-jwt", err: "parse bearer token"},
+			{name: "unsecured", token: unsecuredToken, err: "parse bearer token"},
+			{
+				name:  "wrong signing key",
+				token: newToken(t, otherPrivKey, testKeyID, newClaims(issuer, "acme")),
+				err:   "parse bearer token",
+			},
+			{
+				name:  "unknown key",
+				token: newToken(t, privKey, "key-2", newClaims(issuer, "acme")),
+				err:   "key not found",
+			},
+			{
+				name:  "other issuer",
+				token: newToken(t, privKey, testKeyID, newClaims("https://evil.example.com", "acme")),
+				err:   "key not found",
+			},
+			{name: "expired", token: newToken(t, privKey, testKeyID, expired), err: "token is expired"},
+			{name: "no expiry", token: newToken(t, privKey, testKeyID, noExpiry), err: "bearer token has no expiry"},
+			{
+				name:  "other audience",
+				token: newToken(t, privKey, testKeyID, otherAudience),
+				err:   "invalid audience claim",
+			},
+			{
+				name:  "no tenant",
+				token: newToken(t, privKey, testKeyID, newClaims(issuer, "")),
+				err:   "bearer token has no 'tenant' claim",
+			},
+		}
+
+		for _, tc := range tests {
+			principal, err := authenticator.Authenticate(newBearerRequest(tc.token))
+			require.Error(t, err, tc.name)
+			require.Contains(t, err.Error(), tc.err, tc.name)
+			require.False(t, errors.Is(err, ErrNoCredentials), tc.name)
+			require.Nil(t, principal, tc.name)
+		}
+	})
+
+	t.Run("test issuer must be checked even if key resolves", func(t *testing.T) {
+		a, err := NewOIDCAuthenticator(issuer, testAudience,
+			WithKeyResolver(jwt.KeyResolverFunc(func(_, _ string) (*verifier.PublicKey, error) {
+				return &verifier.PublicKey{Type: "OKP", Value: pubKey}, nil
+			})), WithLeeway(0))
+		require.NoError(t, err)
+
+		_, err = a.Authenticate(newBearerRequest(newToken(t, privKey, testKeyID,
+			newClaims("https://evil.example.com", "acme"))))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid issuer claim")
+	})
+
+	t.Run("test invalid configuration", func(t *testing.T) {
+		_, err := NewOIDCAuthenticator("", testAudience)
+		require.EqualError(t, err, "OIDC issuer and audience are mandatory")
+
+		_, err = NewOIDCAuthenticator(issuer, "")
+		require.EqualError(t, err, "OIDC issuer and audience are mandatory")
+	})
+}
+
+func TestOIDCAuthenticatorWithDiscovery(t *testing.T) {
+	pubKey, privKey := newKeyPair(t)
+
+	op := newTestOP(t, map[string]ed25519.PublicKey{testKeyID: pubKey})
+	defer op.Close()
+
+	authenticator, err := NewOIDCAuthenticator(op.URL, testAudience, WithHTTPClient(op.Client()))
+	require.NoError(t, err)
+
+	principal, err := authenticator.Authenticate(newBearerRequest(newToken(t, privKey, testKeyID,
+		newClaims(op.URL, "acme"))))
+	require.NoError(t, err)
+	require.Equal(t, "acme", principal.TenantID)
+}
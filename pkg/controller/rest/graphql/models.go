@@ -0,0 +1,33 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/graphql"
+)
+
+// queryReq model
+//
+// This is used to execute a GraphQL query over wallet contents.
+//
+// swagger:parameters queryReq
+type queryReq struct { // nolint: unused,deadcode
+	// Params for the query (query document and optional variables)
+	//
+	// in: body
+	Params graphql.QueryArgs
+}
+
+// queryRes model
+//
+// This is used for returning GraphQL query result.
+//
+// swagger:response queryRes
+type queryRes struct {
+	// in: body
+	graphql.QueryResult
+}
@@ -0,0 +1,70 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/graphql"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/internal/cmdutil"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// constants for the GraphQL operations.
+const (
+	QueryPath = "/graphql"
+)
+
+// provider contains dependencies for the graphql operation and is typically created by using aries.Context().
+type provider interface {
+	StorageProvider() storage.Provider
+	ProtocolStateStorageProvider() storage.Provider
+}
+
+// Operation contains GraphQL query operation provided by controller REST API.
+type Operation struct {
+	handlers []rest.Handler
+	command  *graphql.Command
+}
+
+// New returns new graphql rest client instance.
+func New(ctx provider) (*Operation, error) {
+	cmd, err := graphql.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("new graphql : %w", err)
+	}
+
+	o := &Operation{command: cmd}
+	o.registerHandler()
+
+	return o, nil
+}
+
+// GetRESTHandlers get all controller API handler available for this service.
+func (o *Operation) GetRESTHandlers() []rest.Handler {
+	return o.handlers
+}
+
+// registerHandler register handlers to be exposed from this service as REST API endpoints.
+func (o *Operation) registerHandler() {
+	o.handlers = []rest.Handler{
+		cmdutil.NewHTTPHandler(QueryPath, http.MethodPost, o.Query),
+	}
+}
+
+// Query swagger:route POST /graphql graphql queryReq
+//
+// Executes a GraphQL query over stored credentials, connections and DIDs.
+//
+// Responses:
+//    default: genericError
+//        200: queryRes
+func (o *Operation) Query(rw http.ResponseWriter, req *http.Request) {
+	rest.Execute(o.command.Query, rw, http.MaxBytesReader(rw, req.Body, graphql.MaxRequestSize))
+}
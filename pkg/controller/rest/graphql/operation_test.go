@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/command/graphql"
+	"github.com/hyperledger/aries-framework-go/pkg/controller/rest"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	didstore "github.com/hyperledger/aries-framework-go/pkg/store/did"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("test new operation - success", func(t *testing.T) {
+		op, err := New(newMockProvider())
+		require.NoError(t, err)
+		require.NotNil(t, op)
+		require.Len(t, op.GetRESTHandlers(), 1)
+	})
+
+	t.Run("test new operation - error", func(t *testing.T) {
+		op, err := New(&mockprovider.Provider{
+			StorageProviderValue: &mockstore.MockStoreProvider{
+				ErrOpenStoreHandle: fmt.Errorf("error opening the store"),
+			},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "new graphql")
+		require.Nil(t, op)
+	})
+}
+
+func TestQuery(t *testing.T) {
+	p := newMockProvider()
+
+	didStore, err := didstore.New(p)
+	require.NoError(t, err)
+	require.NoError(t, didStore.SaveDID("my-did", &did.Doc{Context: []string{did.Context}, ID: "did:peer:123"}))
+
+	op, err := New(p)
+	require.NoError(t, err)
+
+	t.Run("test query - success", func(t *testing.T) {
+		req, err := json.Marshal(&graphql.QueryArgs{Query: `{ dids { totalCount nodes { id name } } }`})
+		require.NoError(t, err)
+
+		handler := lookupHandler(t, op, QueryPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBuffer(req), handler.Path())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, code)
+
+		var response queryRes
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &response))
+
+		dids := response.Data["dids"].(map[string]interface{})
+		require.EqualValues(t, 1, dids["totalCount"])
+		require.Equal(t, map[string]interface{}{"id": "did:peer:123", "name": "my-did"},
+			dids["nodes"].([]interface{})[0])
+	})
+
+	t.Run("test query - invalid query", func(t *testing.T) {
+		handler := lookupHandler(t, op, QueryPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{"query": "{ dids {"}`),
+			handler.Path())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, graphql.InvalidRequestErrorCode, "parse query", buf.Bytes())
+	})
+
+	t.Run("test query - request too large", func(t *testing.T) {
+		body := `{"query": "` + strings.Repeat(" ", graphql.MaxRequestSize) + `{ dids { totalCount } }"}`
+
+		handler := lookupHandler(t, op, QueryPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(body), handler.Path())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, code)
+		verifyError(t, graphql.InvalidRequestErrorCode, "request decode", buf.Bytes())
+	})
+
+	t.Run("test query - execute error", func(t *testing.T) {
+		handler := lookupHandler(t, op, QueryPath, http.MethodPost)
+		buf, code, err := sendRequestToHandler(handler, bytes.NewBufferString(`{"query": "{ dids { nodes } }"}`),
+			handler.Path())
+		require.NoError(t, err)
+		require.Equal(t, http.StatusInternalServerError, code)
+		verifyError(t, graphql.QueryErrorCode, "must have a selection", buf.Bytes())
+	})
+}
+
+func newMockProvider() *mockprovider.Provider {
+	return &mockprovider.Provider{
+		StorageProviderValue:              mockstore.NewMockStoreProvider(),
+		ProtocolStateStorageProviderValue: mockstore.NewMockStoreProvider(),
+	}
+}
+
+func lookupHandler(t *testing.T, op *Operation, path, method string) rest.Handler {
+	handlers := op.GetRESTHandlers()
+	require.NotEmpty(t, handlers)
+
+	for _, h := range handlers {
+		if h.Path() == path && h.Method() == method {
+			return h
+		}
+	}
+
+	require.Fail(t, "unable to find handler")
+
+	return nil
+}
+
+// sendRequestToHandler reads response from given http handle func.
+func sendRequestToHandler(handler rest.Handler, requestBody io.Reader, path string) (*bytes.Buffer, int, error) {
+	// prepare request
+	req, err := http.NewRequest(handler.Method(), path, requestBody)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// prepare router
+	router := mux.NewRouter()
+
+	router.HandleFunc(handler.Path(), handler.Handle()).Methods(handler.Method())
+
+	// create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
+	rr := httptest.NewRecorder()
+
+	// serve http on given response and request
+	router.ServeHTTP(rr, req)
+
+	return rr.Body, rr.Code, nil
+}
+
+func verifyError(t *testing.T, expectedCode command.Code, expectedMsg string, data []byte) {
+	// Parser generic error response
+	errResponse := struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}{}
+	err := json.Unmarshal(data, &errResponse)
+	require.NoError(t, err)
+
+	// verify response
+	require.EqualValues(t, expectedCode, errResponse.Code)
+	require.NotEmpty(t, errResponse.Message)
+
+	if expectedMsg != "" {
+		require.Contains(t, errResponse.Message, expectedMsg)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/google/tink/go/aead"
 	aeadsubtle "github.com/google/tink/go/aead/subtle"
@@ -24,6 +25,7 @@ import (
 	"github.com/google/tink/go/signature"
 	"golang.org/x/crypto/chacha20poly1305"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
 )
 
@@ -36,6 +38,17 @@ const (
 
 var errBadKeyHandleFormat = errors.New("bad key handle format")
 
+// fipsApprovedKeyTypes are the Tink key types (last element of the type URL) allowed in FIPS mode.
+// nolint:gochecknoglobals
+var fipsApprovedKeyTypes = map[string]bool{
+	"AesGcmKey":             true,
+	"EcdsaPrivateKey":       true,
+	"EcdsaPublicKey":        true,
+	"EcdhAesAeadPrivateKey": true,
+	"EcdhAesAeadPublicKey":  true,
+	"HmacKey":               true,
+}
+
 // Package tinkcrypto includes the default implementation of pkg/crypto. It uses Tink for executing crypto primitives
 // and will be built as a framework option. It represents the main crypto service in the framework. `kh interface{}`
 // arguments in this implementation represent Tink's `*keyset.Handle`, using this type provides easy integration with
@@ -43,12 +56,30 @@ var errBadKeyHandleFormat = errors.New("bad key handle format")
 
 // Crypto is the default Crypto SPI implementation using Tink.
 type Crypto struct {
-	kw keyWrapper
+	kw       keyWrapper
+	fipsMode bool
+}
+
+// Opt configures the Crypto instance.
+type Opt func(t *Crypto)
+
+// WithFIPSMode restricts the crypto instance to keys of FIPS approved types. FIPS mode is always enabled when
+// enabled for the whole process (see package fips).
+func WithFIPSMode() Opt {
+	return func(t *Crypto) {
+		t.fipsMode = true
+	}
 }
 
 // New creates a new Crypto instance.
-func New() (*Crypto, error) {
-	return &Crypto{kw: &keyWrapperSupport{}}, nil
+func New(opts ...Opt) (*Crypto, error) {
+	t := &Crypto{kw: &keyWrapperSupport{}}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t, nil
 }
 
 // Encrypt will encrypt msg using the implementation's corresponding encryption key and primitive in kh of a public key.
@@ -58,6 +89,10 @@ func (t *Crypto) Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error
 		return nil, nil, errBadKeyHandleFormat
 	}
 
+	if err := t.checkFIPS(keyHandle); err != nil {
+		return nil, nil, err
+	}
+
 	ps, err := keyHandle.Primitives()
 	if err != nil {
 		return nil, nil, fmt.Errorf("get primitives: %w", err)
@@ -105,6 +140,10 @@ func (t *Crypto) Decrypt(cipher, aad, nonce []byte, kh interface{}) ([]byte, err
 		return nil, errBadKeyHandleFormat
 	}
 
+	if err := t.checkFIPS(keyHandle); err != nil {
+		return nil, err
+	}
+
 	ps, err := keyHandle.Primitives()
 	if err != nil {
 		return nil, fmt.Errorf("get primitives: %w", err)
@@ -136,6 +175,10 @@ func (t *Crypto) Sign(msg []byte, kh interface{}) ([]byte, error) {
 		return nil, errBadKeyHandleFormat
 	}
 
+	if err := t.checkFIPS(keyHandle); err != nil {
+		return nil, err
+	}
+
 	signer, err := signature.NewSigner(keyHandle)
 	if err != nil {
 		return nil, fmt.Errorf("create new signer: %w", err)
@@ -157,6 +200,10 @@ func (t *Crypto) Verify(sig, msg []byte, kh interface{}) error {
 		return errBadKeyHandleFormat
 	}
 
+	if err := t.checkFIPS(keyHandle); err != nil {
+		return err
+	}
+
 	verifier, err := signature.NewVerifier(keyHandle)
 	if err != nil {
 		return fmt.Errorf("create new verifier: %w", err)
@@ -170,6 +217,23 @@ func (t *Crypto) Verify(sig, msg []byte, kh interface{}) error {
 	return err
 }
 
+// checkFIPS returns an error in FIPS mode if keyHandle contains a key of a type which is not FIPS approved.
+func (t *Crypto) checkFIPS(keyHandle *keyset.Handle) error {
+	if !t.fipsMode && !fips.Enabled() {
+		return nil
+	}
+
+	for _, keyInfo := range keyHandle.KeysetInfo().KeyInfo {
+		keyType := keyInfo.TypeUrl[strings.LastIndex(keyInfo.TypeUrl, ".")+1:]
+
+		if err := fips.CheckMode(t.fipsMode, keyType, fipsApprovedKeyTypes[keyType]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ComputeMAC computes message authentication code (MAC) for code data
 // using a matching MAC primitive in kh key handle.
 func (t *Crypto) ComputeMAC(data []byte, kh interface{}) ([]byte, error) {
@@ -8,11 +8,13 @@ package tinkcrypto
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/google/tink/go/aead"
 	aeadsubtle "github.com/google/tink/go/aead/subtle"
+	"github.com/google/tink/go/daead"
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	"github.com/google/tink/go/keyset"
 	"github.com/google/tink/go/mac"
@@ -21,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 	chacha "golang.org/x/crypto/chacha20poly1305"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
@@ -419,3 +422,83 @@ func TestCrypto_ECDH1PU_Wrap_Unwrap_Key_Using_CryptoPubKey_as_SenderKey(t *testi
 	require.NoError(t, err)
 	require.EqualValues(t, cek, uCEK)
 }
+
+func TestCrypto_FIPSMode(t *testing.T) {
+	c, err := New()
+	require.NoError(t, err)
+
+	edKH, err := keyset.NewHandle(signature.ED25519KeyWithoutPrefixTemplate())
+	require.NoError(t, err)
+
+	xcKH, err := keyset.NewHandle(aead.XChaCha20Poly1305KeyTemplate())
+	require.NoError(t, err)
+
+	ecKH, err := keyset.NewHandle(signature.ECDSAP256KeyWithoutPrefixTemplate())
+	require.NoError(t, err)
+
+	gcmKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	edSig, err := c.Sign([]byte(testMessage), edKH)
+	require.NoError(t, err)
+
+	edPubKH, err := edKH.Public()
+	require.NoError(t, err)
+
+	sivKH, err := keyset.NewHandle(daead.AESSIVKeyTemplate())
+	require.NoError(t, err)
+
+	fipsCrypto, err := New(WithFIPSMode())
+	require.NoError(t, err)
+
+	t.Run("test FIPS mode - approved key types", func(t *testing.T) {
+		c := fipsCrypto
+
+		sig, err := c.Sign([]byte(testMessage), ecKH)
+		require.NoError(t, err)
+
+		pubKH, err := ecKH.Public()
+		require.NoError(t, err)
+		require.NoError(t, c.Verify(sig, []byte(testMessage), pubKH))
+
+		ct, nonce, err := c.Encrypt([]byte(testMessage), nil, gcmKH)
+		require.NoError(t, err)
+
+		pt, err := c.Decrypt(ct, nil, nonce, gcmKH)
+		require.NoError(t, err)
+		require.Equal(t, testMessage, string(pt))
+	})
+
+	t.Run("test FIPS mode - non approved key types", func(t *testing.T) {
+		c := fipsCrypto
+
+		_, err := c.Sign([]byte(testMessage), edKH)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		err = c.Verify(edSig, []byte(testMessage), edPubKH)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		_, _, err = c.Encrypt([]byte(testMessage), nil, xcKH)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		_, err = c.Decrypt([]byte(testMessage), nil, []byte("nonce"), xcKH)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		// key types which aren't known to be approved are rejected too
+		_, _, err = c.Encrypt([]byte(testMessage), nil, sivKH)
+		require.EqualError(t, err, "'AesSivKey' algorithm is not FIPS approved")
+	})
+
+	t.Run("test FIPS mode - other instances are not restricted", func(t *testing.T) {
+		_, err := c.Sign([]byte(testMessage), edKH)
+		require.NoError(t, err)
+	})
+
+	t.Run("test FIPS mode - enabled for the whole process", func(t *testing.T) {
+		fips.SetEnabled(true)
+		defer fips.SetEnabled(false)
+
+		_, err := c.Sign([]byte(testMessage), edKH)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+	})
+}
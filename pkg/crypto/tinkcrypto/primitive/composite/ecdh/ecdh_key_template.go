@@ -22,7 +22,7 @@ import (
 // Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS. The
 // recipient key represented in this key template uses NIST curve P-256.
 func ECDH256KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, nil)
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.AES256GCMKeyTemplate(), nil)
 }
 
 // ECDH384KWAES256GCMKeyTemplate is a KeyTemplate that generates a key that accepts a CEK for AES256-GCM encryption. CEK
@@ -32,7 +32,7 @@ func ECDH256KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 // Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS. The
 // recipient key represented in this key template uses NIST curve P-384.
 func ECDH384KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P384, nil)
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P384, aead.AES256GCMKeyTemplate(), nil)
 }
 
 // ECDH521KWAES256GCMKeyTemplate is a KeyTemplate that generates a key that accepts a CEK for AES256-GCM encryption. CEK
@@ -42,7 +42,7 @@ func ECDH384KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 // Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS. The
 // recipient key represented in this key template uses NIST curve P-521.
 func ECDH521KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521, nil)
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521, aead.AES256GCMKeyTemplate(), nil)
 }
 
 // AES256GCMKeyTemplateWithCEK is similar to ECDHAES256GCMKeyTemplate but adding the cek to execute the
@@ -53,13 +53,20 @@ func ECDH521KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 func AES256GCMKeyTemplateWithCEK(cek []byte) *tinkpb.KeyTemplate {
 	// the curve passed in the template below is ignored when executing the primitive, it's hardcoded to pass key
 	// key format validation only.
-	return createKeyTemplate(0, cek)
+	return createKeyTemplate(0, aead.AES256GCMKeyTemplate(), cek)
 }
 
-// TODO add chacha key templates as well https://github.com/hyperledger/aries-framework-go/issues/1637
+// XChaCha20Poly1305KeyTemplateWithCEK is similar to AES256GCMKeyTemplateWithCEK but for XChacha20Poly1305 content
+// encryption. Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored
+// in the KMS.
+func XChaCha20Poly1305KeyTemplateWithCEK(cek []byte) *tinkpb.KeyTemplate {
+	return createKeyTemplate(0, aead.XChaCha20Poly1305KeyTemplate(), cek)
+}
+
+// TODO add chacha recipient key templates as well https://github.com/hyperledger/aries-framework-go/issues/1637
 
 // createKeyTemplate creates a new ECDH-AEAD key template with the set cek for primitive execution.
-func createKeyTemplate(c commonpb.EllipticCurveType, cek []byte) *tinkpb.KeyTemplate {
+func createKeyTemplate(c commonpb.EllipticCurveType, aeadEnc *tinkpb.KeyTemplate, cek []byte) *tinkpb.KeyTemplate {
 	format := &ecdhpb.EcdhAeadKeyFormat{
 		Params: &ecdhpb.EcdhAeadParams{
 			KwParams: &ecdhpb.EcdhKwParams{
@@ -67,7 +74,7 @@ func createKeyTemplate(c commonpb.EllipticCurveType, cek []byte) *tinkpb.KeyTemp
 				KeyType:   ecdhpb.KeyType_EC,
 			},
 			EncParams: &ecdhpb.EcdhAeadEncParams{
-				AeadEnc: aead.AES256GCMKeyTemplate(),
+				AeadEnc: aeadEnc,
 				CEK:     cek,
 			},
 			EcPointFormat: commonpb.EcPointFormat_UNCOMPRESSED,
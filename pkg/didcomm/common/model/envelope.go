@@ -6,10 +6,18 @@ SPDX-License-Identifier: Apache-2.0
 
 package model
 
-// Envelope for the DIDComm transport messages.
+import "encoding/json"
+
+// Envelope for the DIDComm transport messages. Fields other than Protected, IV, CipherText and Tag are
+// only set for JWE (DIDComm v2) envelopes, which must be forwarded with all of their members.
 type Envelope struct {
-	Protected  string `json:"protected,omitempty"`
-	IV         string `json:"iv,omitempty"`
-	CipherText string `json:"ciphertext,omitempty"`
-	Tag        string `json:"tag,omitempty"`
+	Protected    string          `json:"protected,omitempty"`
+	Unprotected  json.RawMessage `json:"unprotected,omitempty"`
+	Recipients   json.RawMessage `json:"recipients,omitempty"`
+	EncryptedKey string          `json:"encrypted_key,omitempty"`
+	Header       json.RawMessage `json:"header,omitempty"`
+	AAD          string          `json:"aad,omitempty"`
+	IV           string          `json:"iv,omitempty"`
+	CipherText   string          `json:"ciphertext,omitempty"`
+	Tag          string          `json:"tag,omitempty"`
 }
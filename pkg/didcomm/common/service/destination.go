@@ -19,10 +19,14 @@ type Destination struct {
 	ServiceEndpoint      string
 	RoutingKeys          []string
 	TransportReturnRoute string
+	// Accept lists the 'accept' values of the DIDComm service, e.g. the content encryption algorithms accepted by
+	// the recipient, any if empty.
+	Accept []string
 }
 
 const (
-	didCommServiceType = "did-communication"
+	didCommServiceType   = "did-communication"
+	didCommServiceAccept = "accept"
 )
 
 // GetDestination constructs a Destination struct based on the given DID and parameters
@@ -59,5 +63,26 @@ func CreateDestination(didDoc *diddoc.Doc) (*Destination, error) {
 		RecipientKeys:   didCommService.RecipientKeys,
 		ServiceEndpoint: didCommService.ServiceEndpoint,
 		RoutingKeys:     didCommService.RoutingKeys,
+		Accept:          serviceAccept(didCommService),
 	}, nil
 }
+
+// serviceAccept returns the 'accept' values of the service, set in its properties.
+func serviceAccept(s *diddoc.Service) []string {
+	switch values := s.Properties[didCommServiceAccept].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		var accept []string
+
+		for _, v := range values {
+			if value, ok := v.(string); ok {
+				accept = append(accept, value)
+			}
+		}
+
+		return accept
+	default:
+		return nil
+	}
+}
@@ -90,6 +90,22 @@ func TestPrepareDestination(t *testing.T) {
 		require.NotNil(t, dest)
 		require.Equal(t, dest.ServiceEndpoint, "https://localhost:8090")
 		require.Equal(t, []string{"76HmFbj8sds7jjdnZ4hMVcQgtUYZpEN1HEmPnCrH2Bby"}, dest.RoutingKeys)
+		require.Empty(t, dest.Accept)
+	})
+
+	t.Run("successfully prepared destination with accept values", func(t *testing.T) {
+		didDoc := mockdiddoc.GetMockDIDDoc()
+		didDoc.Service[0].Properties = map[string]interface{}{"accept": []interface{}{"XC20P", 1, "A256GCM"}}
+
+		dest, err := CreateDestination(didDoc)
+		require.NoError(t, err)
+		require.Equal(t, []string{"XC20P", "A256GCM"}, dest.Accept)
+
+		didDoc.Service[0].Properties = map[string]interface{}{"accept": []string{"A256GCM"}}
+
+		dest, err = CreateDestination(didDoc)
+		require.NoError(t, err)
+		require.Equal(t, []string{"A256GCM"}, dest.Accept)
 	})
 
 	t.Run("error while getting service", func(t *testing.T) {
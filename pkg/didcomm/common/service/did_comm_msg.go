@@ -332,6 +332,10 @@ func decodeHook(rt1, rt2 reflect.Type, v interface{}) (interface{}, error) {
 		return json.Marshal(v)
 	}
 
+	if rt1.Kind() == reflect.Slice && rt2 == reflect.TypeOf(json.RawMessage{}) {
+		return json.Marshal(v)
+	}
+
 	if rt2 == reflect.TypeOf(did.Doc{}) {
 		didDoc, err := json.Marshal(v)
 		if err != nil {
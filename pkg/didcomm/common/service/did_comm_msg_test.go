@@ -224,7 +224,8 @@ func TestDIDCommMsgMap_ToJsonRawStruct(t *testing.T) {
 			"@id": "fh770bd8-58c2-596g-9610-de2f493cgf60",
             "created": "2020-10-08T16:22:23.2967447Z",
             "updated": "2020-10-08T16:22:23.2967447Z"
-        }
+        },
+        "recipients": [{"encrypted_key": "a2V5"}]
     },
     "~purpose": ["sample-purpose"],
     "~thread": {"thid": "ac881ac9-47b1-485f-8509-cd1e382bfe59"},
@@ -241,13 +242,15 @@ func TestDIDCommMsgMap_ToJsonRawStruct(t *testing.T) {
 		Type    string   `json:"@type"`
 		Purpose []string `json:"~purpose"`
 		Data    *struct {
-			Doc json.RawMessage `json:"doc"`
+			Doc        json.RawMessage `json:"doc"`
+			Recipients json.RawMessage `json:"recipients"`
 		} `json:"data"`
 	}{}
 
 	err = msg.Decode(&req)
 	require.NoError(t, err)
 	require.NotEmpty(t, req.Data.Doc)
+	require.JSONEq(t, `[{"encrypted_key": "a2V5"}]`, string(req.Data.Recipients))
 }
 
 func TestDIDCommMsgMap_MarshalJSON(t *testing.T) {
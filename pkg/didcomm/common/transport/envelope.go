@@ -16,4 +16,9 @@ type Envelope struct {
 	ToKey   []byte
 	FromDID string
 	ToDID   string
+	// EncAlg is the JWE content encryption algorithm of an outbound message, the default one of the packer if empty
+	EncAlg string
+	// EncodingType selects the packer of an outbound message by its encoding type (the `typ` header of the
+	// envelope), the primary packer if empty
+	EncodingType string
 }
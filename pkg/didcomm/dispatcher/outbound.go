@@ -17,6 +17,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/encpref"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
@@ -30,6 +31,7 @@ type provider interface {
 	TransportReturnRoute() string
 	VDRegistry() vdr.Registry
 	KMS() kms.KeyManager
+	EncryptionPreferences() *encpref.Preferences
 }
 
 // OutboundDispatcher dispatch msgs to destination.
@@ -39,6 +41,7 @@ type OutboundDispatcher struct {
 	transportReturnRoute string
 	vdRegistry           vdr.Registry
 	kms                  kms.KeyManager
+	encPreferences       *encpref.Preferences
 }
 
 // NewOutbound return new dispatcher outbound instance.
@@ -49,6 +52,7 @@ func NewOutbound(prov provider) *OutboundDispatcher {
 		transportReturnRoute: prov.TransportReturnRoute(),
 		vdRegistry:           prov.VDRegistry(),
 		kms:                  prov.KMS(),
+		encPreferences:       prov.EncryptionPreferences(),
 	}
 }
 
@@ -70,11 +74,37 @@ func (o *OutboundDispatcher) SendToDID(msg interface{}, myDID, theirDID string)
 	// TODO: relies on hardcoded key type
 	key := src.RecipientKeys[0]
 
-	return o.Send(msg, key, dest)
+	encAlg, err := o.negotiateEncAlg(myDID, theirDID, dest.Accept)
+	if err != nil {
+		return fmt.Errorf("outboundDispatcher.SendToDID failed to negotiate content encryption algorithm: %w", err)
+	}
+
+	return o.send(msg, key, dest, encAlg)
+}
+
+// negotiateEncAlg returns the content encryption algorithm negotiated for the connection between myDID and
+// theirDID with the values accepted by their DIDComm service, empty (the default algorithm of the packer) without
+// encryption preferences.
+func (o *OutboundDispatcher) negotiateEncAlg(myDID, theirDID string, accept []string) (string, error) {
+	if o.encPreferences == nil {
+		return "", nil
+	}
+
+	encAlg, err := o.encPreferences.NegotiateDIDs(myDID, theirDID, accept)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encAlg), nil
 }
 
 // Send sends the message after packing with the sender key and recipient keys.
 func (o *OutboundDispatcher) Send(msg interface{}, senderVerKey string, des *service.Destination) error {
+	return o.send(msg, senderVerKey, des, "")
+}
+
+func (o *OutboundDispatcher) send(msg interface{}, senderVerKey string, des *service.Destination,
+	encAlg string) error {
 	for _, v := range o.outboundTransports {
 		// check if outbound accepts routing keys, else use recipient keys
 		keys := des.RecipientKeys
@@ -99,8 +129,9 @@ func (o *OutboundDispatcher) Send(msg interface{}, senderVerKey string, des *ser
 			return fmt.Errorf("outboundDispatcher.Send: failed to add transport route options : %w", err)
 		}
 
-		packedMsg, err := o.packager.PackMessage(
-			&commontransport.Envelope{Message: req, FromKey: base58.Decode(senderVerKey), ToKeys: des.RecipientKeys})
+		packedMsg, err := o.packager.PackMessage(&commontransport.Envelope{
+			Message: req, FromKey: base58.Decode(senderVerKey), ToKeys: des.RecipientKeys, EncAlg: encAlg,
+		})
 		if err != nil {
 			return fmt.Errorf("outboundDispatcher.Send: failed to pack msg: %w", err)
 		}
@@ -12,21 +12,34 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/anoncrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/encpref"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	mockdidcomm "github.com/hyperledger/aries-framework-go/pkg/mock/didcomm"
 	mockpackager "github.com/hyperledger/aries-framework-go/pkg/mock/didcomm/packager"
 	mockdiddoc "github.com/hyperledger/aries-framework-go/pkg/mock/diddoc"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
 	mockvdr "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
 )
 
 func TestOutboundDispatcher_Send(t *testing.T) {
@@ -149,6 +162,128 @@ func TestOutboundDispatcher_SendToDID(t *testing.T) {
 		require.NoError(t, o.SendToDID("data", "", ""))
 	})
 
+	t.Run("negotiates the content encryption algorithm of the connection", func(t *testing.T) {
+		prov := &storageProvider{store: mockstore.NewMockStoreProvider()}
+
+		lookup, err := connection.NewLookup(prov)
+		require.NoError(t, err)
+
+		prefs, err := encpref.New(prov, encpref.WithConnectionLookup(lookup))
+		require.NoError(t, err)
+
+		recorder, err := connection.NewRecorder(prov)
+		require.NoError(t, err)
+
+		require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+			ConnectionID: "conn-1", State: connection.StateNameCompleted, MyDID: "myDID", TheirDID: "theirDID",
+		}))
+		require.NoError(t, prefs.SetConnectionPreference("conn-1", jose.A256GCM))
+
+		packager := &encAlgPackager{Packager: mockpackager.Packager{PackValue: createPackedMsgForForward(t)}}
+
+		o := NewOutbound(&mockProvider{
+			packagerValue:  packager,
+			encPreferences: prefs,
+			vdr: &mockvdr.MockVDRegistry{
+				ResolveValue: mockDoc,
+			},
+			outboundTransportsValue: []transport.OutboundTransport{
+				&mockdidcomm.MockOutboundTransport{AcceptValue: true},
+			},
+		})
+
+		// the forward message to the router of theirDID is packed with the default algorithm
+		require.NoError(t, o.SendToDID("data", "myDID", "theirDID"))
+		require.Equal(t, []string{jose.A256GCMALG, ""}, packager.encAlgs)
+
+		packager.encAlgs = nil
+
+		require.NoError(t, o.Send("data", "", &service.Destination{ServiceEndpoint: "url"}))
+		require.Equal(t, []string{""}, packager.encAlgs)
+	})
+
+	t.Run("negotiate content encryption algorithm err", func(t *testing.T) {
+		prov := &storageProvider{store: &mockstore.MockStoreProvider{Store: &mockstore.MockStore{
+			Store: map[string][]byte{}, ErrGet: fmt.Errorf("get error"),
+		}}}
+
+		lookup, err := connection.NewLookup(prov)
+		require.NoError(t, err)
+
+		prefs, err := encpref.New(prov, encpref.WithConnectionLookup(lookup))
+		require.NoError(t, err)
+
+		o := NewOutbound(&mockProvider{
+			packagerValue:  &mockpackager.Packager{},
+			encPreferences: prefs,
+			vdr: &mockvdr.MockVDRegistry{
+				ResolveValue: mockDoc,
+			},
+			outboundTransportsValue: []transport.OutboundTransport{
+				&mockdidcomm.MockOutboundTransport{AcceptValue: true},
+			},
+		})
+
+		err = o.SendToDID("data", "myDID", "theirDID")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to negotiate content encryption algorithm")
+		require.Contains(t, err.Error(), "get error")
+	})
+
+	t.Run("packs with the algorithm accepted by the DIDComm service of theirDID", func(t *testing.T) {
+		km, packager := newJWEPackager(t)
+
+		_, recKey, err := km.CreateAndExportPubKeyBytes(kms.ECDH256KWAES256GCMType)
+		require.NoError(t, err)
+
+		prefs, err := encpref.New(&storageProvider{store: mockstore.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		for _, accept := range [][]string{{jose.XC20PALG}, {jose.A256GCMALG}} {
+			didDoc := mockdiddoc.GetMockDIDDoc()
+			didDoc.Service[0].RecipientKeys = []string{base58.Encode(recKey)}
+			didDoc.Service[0].RoutingKeys = nil
+			didDoc.Service[0].Properties = map[string]interface{}{"accept": accept}
+
+			outbound := &recordingOutboundTransport{MockOutboundTransport: mockdidcomm.MockOutboundTransport{
+				AcceptValue: true,
+			}}
+
+			o := NewOutbound(&mockProvider{
+				packagerValue:           packager,
+				encPreferences:          prefs,
+				vdr:                     &mockvdr.MockVDRegistry{ResolveValue: didDoc},
+				outboundTransportsValue: []transport.OutboundTransport{outbound},
+			})
+
+			require.NoError(t, o.SendToDID("data", "myDID", "theirDID"))
+			require.Len(t, outbound.sent, 1)
+
+			jwe, err := jose.Deserialize(string(outbound.sent[0]))
+			require.NoError(t, err)
+
+			encAlg, ok := jwe.ProtectedHeaders.Encryption()
+			require.True(t, ok)
+			require.Equal(t, accept[0], encAlg)
+		}
+
+		didDoc := mockdiddoc.GetMockDIDDoc()
+		didDoc.Service[0].Properties = map[string]interface{}{"accept": []string{"A128CBC-HS256"}}
+
+		o := NewOutbound(&mockProvider{
+			packagerValue:  packager,
+			encPreferences: prefs,
+			vdr:            &mockvdr.MockVDRegistry{ResolveValue: didDoc},
+			outboundTransportsValue: []transport.OutboundTransport{
+				&mockdidcomm.MockOutboundTransport{AcceptValue: true},
+			},
+		})
+
+		err = o.SendToDID("data", "myDID", "theirDID")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, encpref.ErrNoAcceptableAlg))
+	})
+
 	t.Run("resolve err", func(t *testing.T) {
 		o := NewOutbound(&mockProvider{
 			packagerValue: &mockpackager.Packager{},
@@ -305,6 +440,28 @@ func createPackedMsgForForward(t *testing.T) []byte {
 	return msg
 }
 
+// newJWEPackager returns a KMS and a packager of which the primary packer is the anoncrypt JWE packer.
+func newJWEPackager(t *testing.T) (kms.KeyManager, commontransport.Packager) {
+	t.Helper()
+
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(mockstore.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	prov := &packerProvider{km: km, cr: cr, store: mockstore.NewMockStoreProvider()}
+
+	prov.primary, err = anoncrypt.New(prov, jose.A256GCM)
+	require.NoError(t, err)
+
+	p, err := packager.New(prov)
+	require.NoError(t, err)
+
+	return km, p
+}
+
 // mockProvider mock provider.
 type mockProvider struct {
 	packagerValue           commontransport.Packager
@@ -312,6 +469,7 @@ type mockProvider struct {
 	transportReturnRoute    string
 	vdr                     vdrapi.Registry
 	kms                     kms.KeyManager
+	encPreferences          *encpref.Preferences
 }
 
 func (p *mockProvider) Packager() commontransport.Packager {
@@ -375,3 +533,76 @@ func (m *mockPackager) PackMessage(e *commontransport.Envelope) ([]byte, error)
 func (m *mockPackager) UnpackMessage(encMessage []byte) (*commontransport.Envelope, error) {
 	return nil, nil
 }
+
+func (p *mockProvider) EncryptionPreferences() *encpref.Preferences {
+	return p.encPreferences
+}
+
+// storageProvider provides the stores of the encryption preferences and of the connections.
+type storageProvider struct {
+	store storage.Provider
+}
+
+func (p *storageProvider) StorageProvider() storage.Provider {
+	return p.store
+}
+
+func (p *storageProvider) ProtocolStateStorageProvider() storage.Provider {
+	return mockstore.NewMockStoreProvider()
+}
+
+// encAlgPackager records the content encryption algorithm of the packed envelopes.
+type encAlgPackager struct {
+	mockpackager.Packager
+	encAlgs []string
+}
+
+func (p *encAlgPackager) PackMessage(e *commontransport.Envelope) ([]byte, error) {
+	p.encAlgs = append(p.encAlgs, e.EncAlg)
+
+	return p.Packager.PackMessage(e)
+}
+
+// recordingOutboundTransport records the messages sent.
+type recordingOutboundTransport struct {
+	mockdidcomm.MockOutboundTransport
+	sent [][]byte
+}
+
+func (o *recordingOutboundTransport) Send(data []byte, _ *service.Destination) (string, error) {
+	o.sent = append(o.sent, data)
+
+	return "", nil
+}
+
+// packerProvider provides the packers and their dependencies to the packager.
+type packerProvider struct {
+	km      kms.KeyManager
+	cr      cryptoapi.Crypto
+	store   storage.Provider
+	primary packer.Packer
+}
+
+func (p *packerProvider) KMS() kms.KeyManager {
+	return p.km
+}
+
+func (p *packerProvider) Crypto() cryptoapi.Crypto {
+	return p.cr
+}
+
+func (p *packerProvider) StorageProvider() storage.Provider {
+	return p.store
+}
+
+func (p *packerProvider) VDRegistry() vdrapi.Registry {
+	return &mockvdr.MockVDRegistry{}
+}
+
+func (p *packerProvider) Packers() []packer.Packer {
+	return nil
+}
+
+func (p *packerProvider) PrimaryPacker() packer.Packer {
+	return p.primary
+}
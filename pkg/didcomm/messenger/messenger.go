@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
@@ -27,14 +28,18 @@ const (
 	jsonThread         = "~thread"
 	jsonThreadID       = "thid"
 	jsonParentThreadID = "pthid"
+	jsonTrace          = "~trace_context"
+	jsonTraceParent    = "traceparent"
+	jsonTraceState     = "tracestate"
 )
 
 // record is an internal structure and keeps payload about inbound message.
 type record struct {
-	MyDID          string `json:"my_did,omitempty"`
-	TheirDID       string `json:"their_did,omitempty"`
-	ThreadID       string `json:"thread_id,omitempty"`
-	ParentThreadID string `json:"parent_thread_id,omitempty"`
+	MyDID          string           `json:"my_did,omitempty"`
+	TheirDID       string           `json:"their_did,omitempty"`
+	ThreadID       string           `json:"thread_id,omitempty"`
+	ParentThreadID string           `json:"parent_thread_id,omitempty"`
+	Trace          *decorator.Trace `json:"trace,omitempty"`
 }
 
 // Provider contains dependencies for the Messenger.
@@ -43,25 +48,51 @@ type Provider interface {
 	StorageProvider() storage.Provider
 }
 
+// TraceListener is notified of the inbound and outbound messages together with their ~trace_context decorator,
+// for example to record them as OpenTelemetry spans with the trace and span IDs of the decorator (the framework
+// doesn't depend on OpenTelemetry: the listener bridges the decorator to the tracer of the application).
+// The DIDs are empty for messages sent to a destination.
+type TraceListener interface {
+	Inbound(msg service.DIDCommMsgMap, trace *decorator.Trace, myDID, theirDID string)
+	Outbound(msg service.DIDCommMsgMap, trace *decorator.Trace, myDID, theirDID string)
+}
+
+// Opt configures the Messenger.
+type Opt func(m *Messenger)
+
+// WithTraceListener sets the listener of traced messages.
+func WithTraceListener(l TraceListener) Opt {
+	return func(m *Messenger) {
+		m.traceListener = l
+	}
+}
+
 // Messenger describes the messenger structure.
 type Messenger struct {
-	store      storage.Store
-	dispatcher dispatcher.Outbound
+	store         storage.Store
+	dispatcher    dispatcher.Outbound
+	traceListener TraceListener
 }
 
 var logger = log.New("aries-framework/pkg/didcomm/messenger")
 
 // NewMessenger returns a new instance of the Messenger.
-func NewMessenger(ctx Provider) (*Messenger, error) {
+func NewMessenger(ctx Provider, opts ...Opt) (*Messenger, error) {
 	store, err := ctx.StorageProvider().OpenStore(MessengerStore)
 	if err != nil {
 		return nil, fmt.Errorf("open store: %w", err)
 	}
 
-	return &Messenger{
+	m := &Messenger{
 		store:      store,
 		dispatcher: ctx.OutboundDispatcher(),
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m, nil
 }
 
 // HandleInbound handles all inbound messages.
@@ -79,18 +110,30 @@ func (m *Messenger) HandleInbound(msg service.DIDCommMsgMap, myDID, theirDID str
 		return fmt.Errorf("threadID: %w", err)
 	}
 
+	trace := getTrace(msg)
+	if trace != nil {
+		logger.Debugf("inbound message %s of type %s: trace_id=%s span_id=%s",
+			msg.ID(), msg.Type(), trace.TraceID(), trace.SpanID())
+
+		if m.traceListener != nil {
+			m.traceListener.Inbound(msg, trace, myDID, theirDID)
+		}
+	}
+
 	// saves message payload
 	return m.saveRecord(msg.ID(), record{
 		ParentThreadID: msg.ParentThreadID(),
 		MyDID:          myDID,
 		TheirDID:       theirDID,
 		ThreadID:       thID,
+		Trace:          trace,
 	})
 }
 
 // Send sends the message by starting a new thread.
 // Do not provide a message with ~thread decorator. It will be removed.
 // Use ReplyTo function instead. It will keep ~thread decorator automatically.
+// The message is traced only if it has a ~trace_context decorator, e.g. set from the span of the application.
 func (m *Messenger) Send(msg service.DIDCommMsgMap, myDID, theirDID string) error {
 	// fills missing fields
 	fillIfMissing(msg)
@@ -99,6 +142,10 @@ func (m *Messenger) Send(msg service.DIDCommMsgMap, myDID, theirDID string) erro
 		jsonThreadID: msg.ID(),
 	}
 
+	if err := m.trace(msg, nil, myDID, theirDID); err != nil {
+		return err
+	}
+
 	return m.dispatcher.SendToDID(msg, myDID, theirDID)
 }
 
@@ -112,12 +159,17 @@ func (m *Messenger) SendToDestination(msg service.DIDCommMsgMap, sender string,
 
 	delete(msg, jsonThread)
 
+	if err := m.trace(msg, nil, "", ""); err != nil {
+		return err
+	}
+
 	return m.dispatcher.Send(msg, sender, destination)
 }
 
 // ReplyTo replies to the message by given msgID.
 // The function adds ~thread decorator to the message according to the given msgID.
 // Do not provide a message with ~thread decorator. It will be rewritten.
+// The trace of the received message, if any, is continued unless the message has a ~trace_context decorator.
 func (m *Messenger) ReplyTo(msgID string, msg service.DIDCommMsgMap) error {
 	// fills missing fields
 	fillIfMissing(msg)
@@ -139,12 +191,17 @@ func (m *Messenger) ReplyTo(msgID string, msg service.DIDCommMsgMap) error {
 
 	msg[jsonThread] = thread
 
+	if err = m.trace(msg, rec.Trace, rec.MyDID, rec.TheirDID); err != nil {
+		return err
+	}
+
 	return m.dispatcher.SendToDID(msg, rec.MyDID, rec.TheirDID)
 }
 
 // ReplyToMsg replies to the given message.
 // The function adds ~thread decorator to the message according to the given msgID.
 // Do not provide a message with ~thread decorator. It will be rewritten.
+// The trace of the given message, if any, is continued unless the reply has a ~trace_context decorator.
 func (m *Messenger) ReplyToMsg(in, out service.DIDCommMsgMap, myDID, theirDID string) error {
 	// fills missing fields
 	fillIfMissing(out)
@@ -166,6 +223,10 @@ func (m *Messenger) ReplyToMsg(in, out service.DIDCommMsgMap, myDID, theirDID st
 
 	out[jsonThread] = thread
 
+	if err = m.trace(out, getTrace(in), myDID, theirDID); err != nil {
+		return err
+	}
+
 	return m.dispatcher.SendToDID(out, myDID, theirDID)
 }
 
@@ -173,20 +234,75 @@ func (m *Messenger) ReplyToMsg(in, out service.DIDCommMsgMap, myDID, theirDID st
 // Do not provide a message with ~thread decorator. It will be rewritten.
 // The function adds ~thread decorator to the message according to the given threadID.
 // NOTE: Given threadID (from opts or from message record) becomes parent threadID.
+// The trace of the message record, if any, is continued unless the message has a ~trace_context decorator.
 func (m *Messenger) ReplyToNested(msg service.DIDCommMsgMap, opts *service.NestedReplyOpts) error {
 	// fills missing fields
 	fillIfMissing(msg)
 
-	if err := m.fillNestedReplyOption(opts); err != nil {
+	parentTrace, err := m.fillNestedReplyOption(opts)
+	if err != nil {
 		return fmt.Errorf("failed to prepare nested reply options: %w", err)
 	}
 
 	// sets parent threadID
 	msg[jsonThread] = map[string]interface{}{jsonParentThreadID: opts.ThreadID}
 
+	if err = m.trace(msg, parentTrace, opts.MyDID, opts.TheirDID); err != nil {
+		return err
+	}
+
 	return m.dispatcher.SendToDID(msg, opts.MyDID, opts.TheirDID)
 }
 
+// trace sets the ~trace_context decorator of the outbound message: the trace of the message is kept if it has one,
+// otherwise the parent trace is continued. Messages without trace context are not traced.
+func (m *Messenger) trace(msg service.DIDCommMsgMap, parent *decorator.Trace, myDID, theirDID string) error {
+	trace := getTrace(msg)
+	if trace == nil {
+		delete(msg, jsonTrace)
+
+		if parent == nil {
+			return nil
+		}
+
+		var err error
+
+		trace, err = parent.Child()
+		if err != nil {
+			return fmt.Errorf("trace: %w", err)
+		}
+
+		traceDecorator := map[string]interface{}{jsonTraceParent: trace.TraceParent}
+		if trace.TraceState != "" {
+			traceDecorator[jsonTraceState] = trace.TraceState
+		}
+
+		msg[jsonTrace] = traceDecorator
+	}
+
+	logger.Debugf("outbound message %s of type %s: trace_id=%s span_id=%s",
+		msg.ID(), msg.Type(), trace.TraceID(), trace.SpanID())
+
+	if m.traceListener != nil {
+		m.traceListener.Outbound(msg, trace, myDID, theirDID)
+	}
+
+	return nil
+}
+
+// getTrace returns the valid ~trace_context decorator of the message or nil.
+func getTrace(msg service.DIDCommMsgMap) *decorator.Trace {
+	v := struct {
+		Trace *decorator.Trace `json:"~trace_context"`
+	}{}
+
+	if err := msg.Decode(&v); err != nil || v.Trace == nil || v.Trace.Validate() != nil {
+		return nil
+	}
+
+	return v.Trace
+}
+
 // fillIfMissing populates message with common fields such as ID.
 func fillIfMissing(msg service.DIDCommMsgMap) {
 	// if ID is empty we will create a new one
@@ -220,20 +336,26 @@ func (m *Messenger) saveRecord(msgID string, rec record) error {
 	return m.store.Put(msgID, src)
 }
 
-// fillNestedReplyOption prefills missing nested reply options from record.
-func (m *Messenger) fillNestedReplyOption(opts *service.NestedReplyOpts) error {
-	if opts.ThreadID != "" && opts.TheirDID != "" && opts.MyDID != "" {
-		return nil
-	}
+// fillNestedReplyOption prefills missing nested reply options from record and returns the trace of the record.
+// The record is optional if the options are complete.
+func (m *Messenger) fillNestedReplyOption(opts *service.NestedReplyOpts) (*decorator.Trace, error) {
+	complete := opts.ThreadID != "" && opts.TheirDID != "" && opts.MyDID != ""
 
 	if opts.MsgID == "" { // nolint: staticcheck
-		logger.Debugf("failed to prepare fill nested reply options, missing message ID")
-		return nil
+		if !complete {
+			logger.Debugf("failed to prepare fill nested reply options, missing message ID")
+		}
+
+		return nil, nil
 	}
 
 	rec, err := m.getRecord(opts.MsgID) // nolint: staticcheck
 	if err != nil {
-		return err
+		if complete {
+			return nil, nil
+		}
+
+		return nil, err
 	}
 
 	if opts.ThreadID == "" {
@@ -248,5 +370,5 @@ func (m *Messenger) fillNestedReplyOption(opts *service.NestedReplyOpts) error {
 		opts.MyDID = rec.MyDID
 	}
 
-	return nil
+	return rec.Trace, nil
 }
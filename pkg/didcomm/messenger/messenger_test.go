@@ -19,6 +19,7 @@ import (
 	dispatcherMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/dispatcher"
 	messengerMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/messenger"
 	storageMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
 )
 
 const (
@@ -409,3 +410,116 @@ func TestMessenger_ReplyToMsg(t *testing.T) {
 		}, service.DIDCommMsgMap{}, "", ""), "get threadID: invalid message")
 	})
 }
+
+type traceListener struct {
+	inbound  []*decorator.Trace
+	outbound []*decorator.Trace
+}
+
+func (l *traceListener) Inbound(_ service.DIDCommMsgMap, trace *decorator.Trace, _, _ string) {
+	l.inbound = append(l.inbound, trace)
+}
+
+func (l *traceListener) Outbound(_ service.DIDCommMsgMap, trace *decorator.Trace, _, _ string) {
+	l.outbound = append(l.outbound, trace)
+}
+
+func TestMessenger_Trace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const (
+		traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+		traceParent = "00-" + traceID + "-00f067aa0ba902b7-01"
+	)
+
+	inbound := service.DIDCommMsgMap{
+		jsonID:    ID,
+		jsonTrace: map[string]interface{}{jsonTraceParent: traceParent, jsonTraceState: "vendor=1"},
+	}
+
+	var sent []service.DIDCommMsgMap
+
+	outbound := dispatcherMocks.NewMockOutbound(ctrl)
+	outbound.EXPECT().SendToDID(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(msg service.DIDCommMsgMap, _, _ string) error {
+			sent = append(sent, msg)
+
+			return nil
+		}).AnyTimes()
+
+	store, err := mem.NewProvider().OpenStore(MessengerStore)
+	require.NoError(t, err)
+
+	storageProvider := storageMocks.NewMockProvider(ctrl)
+	storageProvider.EXPECT().OpenStore(gomock.Any()).Return(store, nil)
+
+	provider := messengerMocks.NewMockProvider(ctrl)
+	provider.EXPECT().StorageProvider().Return(storageProvider)
+	provider.EXPECT().OutboundDispatcher().Return(outbound)
+
+	listener := &traceListener{}
+
+	msgr, err := NewMessenger(provider, WithTraceListener(listener))
+	require.NoError(t, err)
+
+	require.NoError(t, msgr.HandleInbound(inbound, myDID, theirDID))
+	require.Len(t, listener.inbound, 1)
+	require.Equal(t, traceParent, listener.inbound[0].TraceParent)
+
+	getTrace := func(msg service.DIDCommMsgMap) *decorator.Trace {
+		v := struct {
+			Trace decorator.Trace `json:"~trace_context"`
+		}{}
+
+		require.NoError(t, msg.Decode(&v))
+		require.NoError(t, v.Trace.Validate())
+
+		return &v.Trace
+	}
+
+	requireChild := func(msg service.DIDCommMsgMap) {
+		trace := getTrace(msg)
+		require.Equal(t, traceID, trace.TraceID())
+		require.NotEqual(t, "00f067aa0ba902b7", trace.SpanID())
+		require.Equal(t, "vendor=1", trace.TraceState)
+	}
+
+	require.NoError(t, msgr.ReplyTo(ID, service.DIDCommMsgMap{}))
+	requireChild(sent[0])
+
+	require.NoError(t, msgr.ReplyToMsg(inbound, service.DIDCommMsgMap{}, myDID, theirDID))
+	requireChild(sent[1])
+
+	require.NoError(t, msgr.ReplyToNested(service.DIDCommMsgMap{}, &service.NestedReplyOpts{MsgID: ID}))
+	requireChild(sent[2])
+
+	// the trace of the record is continued with complete options
+	require.NoError(t, msgr.ReplyToNested(service.DIDCommMsgMap{}, &service.NestedReplyOpts{
+		MsgID: ID, ThreadID: "thID", MyDID: myDID, TheirDID: theirDID,
+	}))
+	requireChild(sent[3])
+
+	// a new thread is not traced without trace context
+	require.NoError(t, msgr.Send(service.DIDCommMsgMap{}, myDID, theirDID))
+	require.NotContains(t, sent[4], jsonTrace)
+
+	// the trace of the message is kept
+	require.NoError(t, msgr.Send(service.DIDCommMsgMap{
+		jsonTrace: map[string]interface{}{jsonTraceParent: traceParent},
+	}, myDID, theirDID))
+	require.Equal(t, traceParent, getTrace(sent[5]).TraceParent)
+
+	require.Len(t, listener.outbound, 5)
+
+	// invalid trace is ignored on inbound and removed on outbound
+	invalid := service.DIDCommMsgMap{jsonID: "invalid", jsonTrace: map[string]interface{}{jsonTraceParent: "invalid"}}
+	require.NoError(t, msgr.HandleInbound(invalid, myDID, theirDID))
+	require.Len(t, listener.inbound, 1)
+
+	require.NoError(t, msgr.ReplyTo("invalid", service.DIDCommMsgMap{
+		jsonTrace: map[string]interface{}{jsonTraceParent: "invalid"},
+	}))
+	require.NotContains(t, sent[6], jsonTrace)
+	require.Len(t, listener.outbound, 5)
+}
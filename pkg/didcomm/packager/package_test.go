@@ -8,6 +8,7 @@ package packager_test
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -19,7 +20,9 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	. "github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/anoncrypt"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/authcrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/encpref"
 	legacy "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/legacy/authcrypt"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
@@ -331,6 +334,104 @@ func TestBaseKMSInPackager_UnpackMessage(t *testing.T) {
 		unpackedMsg, err = packager.UnpackMessage(packMsg)
 		require.NoError(t, err)
 		require.Equal(t, unpackedMsg.Message, []byte("msg2"))
+
+		// pack with legacy selected by its encoding type, the primary packer being JWE
+		packMsg, err = packager.PackMessage(&transport.Envelope{
+			Message:      []byte("msg3"),
+			FromKey:      fromKey,
+			ToKeys:       []string{base58.Encode(toKey)},
+			EncodingType: legacyPacker.EncodingType(),
+		})
+		require.NoError(t, err)
+
+		legacyEnv := &struct {
+			Protected string `json:"protected"`
+		}{}
+		require.NoError(t, json.Unmarshal(packMsg, legacyEnv))
+
+		protected, err := base64.URLEncoding.DecodeString(legacyEnv.Protected)
+		require.NoError(t, err)
+		require.Contains(t, string(protected), `"typ":"JWM/1.0"`)
+
+		unpackedMsg, err = packager.UnpackMessage(packMsg)
+		require.NoError(t, err)
+		require.Equal(t, unpackedMsg.Message, []byte("msg3"))
+
+		_, err = packager.PackMessage(&transport.Envelope{
+			Message:      []byte("msg3"),
+			FromKey:      fromKey,
+			ToKeys:       []string{base58.Encode(toKey)},
+			EncodingType: "unknown",
+		})
+		require.EqualError(t, err, "packMessage: no packer for encoding type unknown")
+	})
+
+	t.Run("test Pack/Unpack success with each supported content encryption algorithm", func(t *testing.T) {
+		customKMS, err := localkms.New(localKeyURI,
+			newMockKMSProvider(mockstorage.NewMockStoreProvider()))
+		require.NoError(t, err)
+
+		thirdPartyKeyStore := make(map[string][]byte)
+
+		mockedProviders := &mockProvider{
+			storage:       mockstorage.NewCustomMockStoreProvider(&mockstorage.MockStore{Store: thirdPartyKeyStore}),
+			kms:           customKMS,
+			primaryPacker: nil,
+			packers:       nil,
+			crypto:        cryptoSvc,
+		}
+
+		authPacker, err := authcrypt.New(mockedProviders, jose.A256GCM)
+		require.NoError(t, err)
+
+		anonPacker, err := anoncrypt.New(mockedProviders, jose.A256GCM)
+		require.NoError(t, err)
+
+		fromKID, fromKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ECDH256KWAES256GCMType)
+		require.NoError(t, err)
+
+		thirdPartyKeyStore[prefix.StorageKIDPrefix+fromKID] = fromKey
+
+		_, toKey, err := customKMS.CreateAndExportPubKeyBytes(kms.ECDH256KWAES256GCMType)
+		require.NoError(t, err)
+
+		for _, primary := range []packer.Packer{authPacker, anonPacker} {
+			mockedProviders.primaryPacker = primary
+			mockedProviders.packers = []packer.Packer{authPacker, anonPacker}
+
+			packager, err := New(mockedProviders)
+			require.NoError(t, err)
+
+			for _, encAlg := range encpref.Supported() {
+				packMsg, err := packager.PackMessage(&transport.Envelope{
+					Message: []byte("msg1"),
+					FromKey: []byte(fromKID),
+					ToKeys:  []string{base58.Encode(toKey)},
+					EncAlg:  string(encAlg),
+				})
+				require.NoError(t, err)
+
+				jwe, err := jose.Deserialize(string(packMsg))
+				require.NoError(t, err)
+
+				enc, ok := jwe.ProtectedHeaders.Encryption()
+				require.True(t, ok)
+				require.Equal(t, string(encAlg), enc)
+
+				unpackedMsg, err := packager.UnpackMessage(packMsg)
+				require.NoError(t, err)
+				require.Equal(t, []byte("msg1"), unpackedMsg.Message)
+			}
+
+			_, err = packager.PackMessage(&transport.Envelope{
+				Message: []byte("msg1"),
+				FromKey: []byte(fromKID),
+				ToKeys:  []string{base58.Encode(toKey)},
+				EncAlg:  "A256CBC-HS512",
+			})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "encryption algorithm 'A256CBC-HS512' not supported")
+		}
 	})
 
 	t.Run("test success - dids not found", func(t *testing.T) {
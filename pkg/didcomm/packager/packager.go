@@ -18,6 +18,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/authcrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 	"github.com/hyperledger/aries-framework-go/pkg/store/did"
@@ -113,7 +114,29 @@ func (bp *Packager) PackMessage(messageEnvelope *transport.Envelope) ([]byte, er
 
 	// TODO find a way to dynamically select a packer based on FromKey, recipients and their types.
 	//      https://github.com/hyperledger/aries-framework-go/issues/1112 Configurable packing
-	bytes, err := bp.primaryPacker.Pack(messageEnvelope.Message, messageEnvelope.FromKey, recipients)
+	var (
+		bytes []byte
+		err   error
+	)
+
+	p := bp.primaryPacker
+
+	if messageEnvelope.EncodingType != "" && messageEnvelope.EncodingType != p.EncodingType() {
+		var ok bool
+
+		p, ok = bp.packers[messageEnvelope.EncodingType]
+		if !ok {
+			return nil, fmt.Errorf("packMessage: no packer for encoding type %s", messageEnvelope.EncodingType)
+		}
+	}
+
+	if encAlgPacker, ok := p.(packer.EncAlgPacker); ok && messageEnvelope.EncAlg != "" {
+		bytes, err = encAlgPacker.PackWithEncAlg(jose.EncAlg(messageEnvelope.EncAlg), messageEnvelope.Message,
+			messageEnvelope.FromKey, recipients)
+	} else {
+		bytes, err = p.Pack(messageEnvelope.Message, messageEnvelope.FromKey, recipients)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("packMessage: failed to pack: %w", err)
 	}
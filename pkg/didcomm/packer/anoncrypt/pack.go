@@ -62,7 +62,12 @@ func New(ctx packer.Provider, encAlg jose.EncAlg) (*Packer, error) {
 // Pack will encode the payload argument
 // Using the protocol defined by the Anoncrypt message of Aries RFC 0334
 // Anoncrypt ignores the sender argument, it's added to meet the Packer interface.
-func (p *Packer) Pack(payload, _ []byte, recipientsPubKeys [][]byte) ([]byte, error) {
+func (p *Packer) Pack(payload, sender []byte, recipientsPubKeys [][]byte) ([]byte, error) {
+	return p.PackWithEncAlg(p.encAlg, payload, sender, recipientsPubKeys)
+}
+
+// PackWithEncAlg packs the payload like Pack, with the given content encryption algorithm.
+func (p *Packer) PackWithEncAlg(encAlg jose.EncAlg, payload, _ []byte, recipientsPubKeys [][]byte) ([]byte, error) {
 	if len(recipientsPubKeys) == 0 {
 		return nil, fmt.Errorf("anoncrypt Pack: empty recipientsPubKeys")
 	}
@@ -72,7 +77,7 @@ func (p *Packer) Pack(payload, _ []byte, recipientsPubKeys [][]byte) ([]byte, er
 		return nil, fmt.Errorf("anoncrypt Pack: failed to convert recipient keys: %w", err)
 	}
 
-	jweEncrypter, err := jose.NewJWEEncrypt(p.encAlg, encodingType, "", nil, recECKeys, p.cryptoService)
+	jweEncrypter, err := jose.NewJWEEncrypt(encAlg, encodingType, "", nil, recECKeys, p.cryptoService)
 	if err != nil {
 		return nil, fmt.Errorf("anoncrypt Pack: failed to new JWEEncrypt instance: %w", err)
 	}
@@ -9,6 +9,7 @@ package packer
 import (
 	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
@@ -45,3 +46,12 @@ type Packer interface {
 	// Encoding returns the type of the encoding, as found in the header `Typ` field
 	EncodingType() string
 }
+
+// EncAlgPacker is a Packer of JWE envelopes which can pack a payload with a given content encryption algorithm,
+// e.g. negotiated for the connection, instead of its default one.
+type EncAlgPacker interface {
+	Packer
+
+	// PackWithEncAlg packs a payload like Pack, with the given content encryption algorithm.
+	PackWithEncAlg(encAlg jose.EncAlg, payload []byte, senderKey []byte, recipients [][]byte) ([]byte, error)
+}
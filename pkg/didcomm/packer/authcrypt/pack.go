@@ -92,6 +92,12 @@ func New(ctx packer.Provider, encAlg jose.EncAlg) (*Packer, error) {
 // senderID: the key id of the sender (stored in the KMS)
 // recipientsPubKeys: public keys.
 func (p *Packer) Pack(payload, senderID []byte, recipientsPubKeys [][]byte) ([]byte, error) {
+	return p.PackWithEncAlg(p.encAlg, payload, senderID, recipientsPubKeys)
+}
+
+// PackWithEncAlg packs the payload like Pack, with the given content encryption algorithm.
+func (p *Packer) PackWithEncAlg(encAlg jose.EncAlg, payload, senderID []byte,
+	recipientsPubKeys [][]byte) ([]byte, error) {
 	if len(recipientsPubKeys) == 0 {
 		return nil, fmt.Errorf("authcrypt Pack: empty recipientsPubKeys")
 	}
@@ -106,7 +112,7 @@ func (p *Packer) Pack(payload, senderID []byte, recipientsPubKeys [][]byte) ([]b
 		return nil, fmt.Errorf("authcrypt Pack: failed to get sender key from KMS: %w", err)
 	}
 
-	jweEncrypter, err := jose.NewJWEEncrypt(p.encAlg, encodingType, string(senderID), kh.(*keyset.Handle), recECKeys,
+	jweEncrypter, err := jose.NewJWEEncrypt(encAlg, encodingType, string(senderID), kh.(*keyset.Handle), recECKeys,
 		p.cryptoService)
 	if err != nil {
 		return nil, fmt.Errorf("authcrypt Pack: failed to new JWEEncrypt instance: %w", err)
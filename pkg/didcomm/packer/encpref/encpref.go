@@ -0,0 +1,270 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package encpref manages the preferred JWE content encryption algorithms used to pack DIDComm messages. Preferences
+// are set globally and can be overridden per connection, the algorithm used for a message is negotiated from these
+// preferences and the 'accept' values advertised by the peer.
+package encpref
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// StoreName is the name of the store holding per connection preferences.
+const StoreName = "encpref"
+
+// ErrNoAcceptableAlg signals that none of the preferred algorithms is accepted by the peer.
+var ErrNoAcceptableAlg = errors.New("no acceptable content encryption algorithm")
+
+// nolint:gochecknoglobals
+var (
+	// supportedAlgs lists the content encryption algorithms supported by the JWE packers (see jose.NewJWEEncrypt),
+	// in default order of preference.
+	supportedAlgs = []jose.EncAlg{jose.A256GCM, jose.XC20P}
+
+	// fipsApprovedAlgs lists the supported content encryption algorithms approved for FIPS 140 deployments.
+	fipsApprovedAlgs = map[jose.EncAlg]bool{jose.A256GCM: true}
+)
+
+// Provider contains dependencies for the encryption preferences and is typically created by using aries.Context().
+type Provider interface {
+	StorageProvider() storage.Provider
+}
+
+// ConnectionLookup finds the ID of the connection between two DIDs (e.g. connection.Lookup).
+type ConnectionLookup interface {
+	GetConnectionIDByDIDs(myDID, theirDID string) (string, error)
+}
+
+// Opt configures Preferences.
+type Opt func(*Preferences)
+
+// WithDefaults sets the global preferred content encryption algorithms, most preferred first.
+func WithDefaults(algs ...jose.EncAlg) Opt {
+	return func(p *Preferences) {
+		p.defaults = algs
+	}
+}
+
+// WithConnectionLookup sets the lookup of connections used to negotiate with the preferences of the connection
+// between two DIDs. Without it, NegotiateDIDs negotiates with the global preferences.
+func WithConnectionLookup(lookup ConnectionLookup) Opt {
+	return func(p *Preferences) {
+		p.connections = lookup
+	}
+}
+
+// WithFIPSOnly restricts negotiation and preferences to FIPS approved content encryption algorithms. This is
+// always the case when the framework runs in FIPS mode.
+func WithFIPSOnly() Opt {
+	return func(p *Preferences) {
+		p.fipsOnly = true
+	}
+}
+
+// Preferences holds global and per connection content encryption algorithm preferences.
+type Preferences struct {
+	store       storage.Store
+	connections ConnectionLookup
+	defaults    []jose.EncAlg
+	fipsOnly    bool
+}
+
+// New returns content encryption preferences. Without WithDefaults option all supported (FIPS approved in FIPS
+// only mode) algorithms are preferred: A256GCM, then XC20P. Other algorithms are rejected.
+func New(p Provider, opts ...Opt) (*Preferences, error) {
+	store, err := p.StorageProvider().OpenStore(StoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open encryption preferences store: %w", err)
+	}
+
+	prefs := &Preferences{store: store, fipsOnly: fips.Enabled()}
+
+	for _, opt := range opts {
+		opt(prefs)
+	}
+
+	if prefs.defaults == nil {
+		prefs.defaults = prefs.allowed(supportedAlgs)
+	}
+
+	if err = prefs.validate(prefs.defaults); err != nil {
+		return nil, fmt.Errorf("invalid default preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// FIPSOnly returns true if only FIPS approved content encryption algorithms are allowed.
+func (p *Preferences) FIPSOnly() bool {
+	return p.fipsOnly
+}
+
+// Defaults returns the global preferred content encryption algorithms, most preferred first.
+func (p *Preferences) Defaults() []jose.EncAlg {
+	return p.defaults
+}
+
+// SetConnectionPreference sets the preferred content encryption algorithms of the given connection,
+// most preferred first.
+func (p *Preferences) SetConnectionPreference(connectionID string, algs ...jose.EncAlg) error {
+	if connectionID == "" {
+		return errors.New("connection ID is mandatory")
+	}
+
+	if err := p.validate(algs); err != nil {
+		return err
+	}
+
+	bytes, err := json.Marshal(algs)
+	if err != nil {
+		return fmt.Errorf("marshal preferences: %w", err)
+	}
+
+	return p.store.Put(connectionID, bytes)
+}
+
+// RemoveConnectionPreference removes preferences of the given connection, global preferences apply afterwards.
+func (p *Preferences) RemoveConnectionPreference(connectionID string) error {
+	err := p.store.Delete(connectionID)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("remove preferences: %w", err)
+	}
+
+	return nil
+}
+
+// ConnectionPreference returns the preferred content encryption algorithms of the given connection,
+// falls back to global preferences if none has been set for the connection.
+func (p *Preferences) ConnectionPreference(connectionID string) ([]jose.EncAlg, error) {
+	bytes, err := p.store.Get(connectionID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return p.Defaults(), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get preferences: %w", err)
+	}
+
+	var algs []jose.EncAlg
+
+	err = json.Unmarshal(bytes, &algs)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal preferences: %w", err)
+	}
+
+	// FIPS mode might have been enabled, or an algorithm dropped, after the preferences were saved.
+	return p.allowed(algs), nil
+}
+
+// Negotiate returns the content encryption algorithm to use with the given connection: the most preferred
+// algorithm of the connection which is listed in the peer's accept values. An empty accept list means
+// that the peer accepts any algorithm.
+func (p *Preferences) Negotiate(connectionID string, accept []string) (jose.EncAlg, error) {
+	algs, err := p.ConnectionPreference(connectionID)
+	if err != nil {
+		return "", err
+	}
+
+	return Select(algs, accept)
+}
+
+// NegotiateDIDs returns the content encryption algorithm to use for a message from myDID to theirDID, negotiated
+// with the preferences of their connection (see Negotiate). Global preferences apply without connection.
+func (p *Preferences) NegotiateDIDs(myDID, theirDID string, accept []string) (jose.EncAlg, error) {
+	if p.connections == nil {
+		return Select(p.Defaults(), accept)
+	}
+
+	connectionID, err := p.connections.GetConnectionIDByDIDs(myDID, theirDID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return Select(p.Defaults(), accept)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("get connection: %w", err)
+	}
+
+	return p.Negotiate(connectionID, accept)
+}
+
+// Select returns the first of preferred algorithms which is listed in accept values. An empty accept list
+// means that any algorithm is accepted.
+func Select(preferred []jose.EncAlg, accept []string) (jose.EncAlg, error) {
+	for _, alg := range preferred {
+		if len(accept) == 0 || contains(accept, string(alg)) {
+			return alg, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: preferred %v, accepted %v", ErrNoAcceptableAlg, preferred, accept)
+}
+
+// Supported returns the content encryption algorithms supported by the JWE packers, in default order of preference.
+func Supported() []jose.EncAlg {
+	return append([]jose.EncAlg(nil), supportedAlgs...)
+}
+
+// IsFIPSApproved returns true if the given content encryption algorithm is approved for FIPS 140 deployments.
+func IsFIPSApproved(alg jose.EncAlg) bool {
+	return fipsApprovedAlgs[alg]
+}
+
+func (p *Preferences) validate(algs []jose.EncAlg) error {
+	if len(algs) == 0 {
+		return errors.New("at least one content encryption algorithm is required")
+	}
+
+	for _, alg := range algs {
+		if !isSupported(alg) {
+			return fmt.Errorf("content encryption algorithm '%s' not supported", alg)
+		}
+
+		if p.fipsOnly && !IsFIPSApproved(alg) {
+			return fmt.Errorf("content encryption algorithm '%s' is not FIPS approved", alg)
+		}
+	}
+
+	return nil
+}
+
+func (p *Preferences) allowed(algs []jose.EncAlg) []jose.EncAlg {
+	var allowed []jose.EncAlg
+
+	for _, alg := range algs {
+		if isSupported(alg) && (!p.fipsOnly || IsFIPSApproved(alg)) {
+			allowed = append(allowed, alg)
+		}
+	}
+
+	return allowed
+}
+
+func isSupported(alg jose.EncAlg) bool {
+	for _, supported := range supportedAlgs {
+		if alg == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
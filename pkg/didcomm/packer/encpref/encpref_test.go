@@ -0,0 +1,251 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encpref
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
+)
+
+const a256cbcHS512 = jose.EncAlg("A256CBC-HS512")
+
+func TestNew(t *testing.T) {
+	t.Run("test new - default preferences", func(t *testing.T) {
+		prefs, err := New(newMockProvider())
+		require.NoError(t, err)
+		require.False(t, prefs.FIPSOnly())
+		require.Equal(t, []jose.EncAlg{jose.A256GCM, jose.XC20P}, prefs.Defaults())
+	})
+
+	t.Run("test new - default FIPS only preferences", func(t *testing.T) {
+		prefs, err := New(newMockProvider(), WithFIPSOnly())
+		require.NoError(t, err)
+		require.True(t, prefs.FIPSOnly())
+		require.Equal(t, []jose.EncAlg{jose.A256GCM}, prefs.Defaults())
+	})
+
+	t.Run("test new - custom defaults", func(t *testing.T) {
+		prefs, err := New(newMockProvider(), WithDefaults(jose.XC20P, jose.A256GCM))
+		require.NoError(t, err)
+		require.Equal(t, []jose.EncAlg{jose.XC20P, jose.A256GCM}, prefs.Defaults())
+	})
+
+	t.Run("test new - unsupported defaults", func(t *testing.T) {
+		for _, alg := range []jose.EncAlg{a256cbcHS512, "A128GCM"} {
+			_, err := New(newMockProvider(), WithDefaults(jose.A256GCM, alg))
+			require.EqualError(t, err,
+				fmt.Sprintf("invalid default preferences: content encryption algorithm '%s' not supported", alg))
+		}
+	})
+
+	t.Run("test new - open store error", func(t *testing.T) {
+		_, err := New(&mockProvider{
+			storeProvider: &mockstorage.MockStoreProvider{ErrOpenStoreHandle: fmt.Errorf("open error")},
+		})
+		require.EqualError(t, err, "open encryption preferences store: open error")
+	})
+}
+
+func TestConnectionPreference(t *testing.T) {
+	t.Run("test connection preference - set, get and remove", func(t *testing.T) {
+		prefs, err := New(newMockProvider())
+		require.NoError(t, err)
+
+		algs, err := prefs.ConnectionPreference("conn-1")
+		require.NoError(t, err)
+		require.Equal(t, prefs.Defaults(), algs)
+
+		require.NoError(t, prefs.SetConnectionPreference("conn-1", jose.A256GCM))
+
+		algs, err = prefs.ConnectionPreference("conn-1")
+		require.NoError(t, err)
+		require.Equal(t, []jose.EncAlg{jose.A256GCM}, algs)
+
+		require.NoError(t, prefs.RemoveConnectionPreference("conn-1"))
+		require.NoError(t, prefs.RemoveConnectionPreference("conn-1"))
+
+		algs, err = prefs.ConnectionPreference("conn-1")
+		require.NoError(t, err)
+		require.Equal(t, prefs.Defaults(), algs)
+	})
+
+	t.Run("test connection preference - validation errors", func(t *testing.T) {
+		prefs, err := New(newMockProvider(), WithFIPSOnly())
+		require.NoError(t, err)
+
+		require.EqualError(t, prefs.SetConnectionPreference("", jose.A256GCM), "connection ID is mandatory")
+		require.EqualError(t, prefs.SetConnectionPreference("conn-1"),
+			"at least one content encryption algorithm is required")
+		require.EqualError(t, prefs.SetConnectionPreference("conn-1", jose.A256GCM, a256cbcHS512),
+			"content encryption algorithm 'A256CBC-HS512' not supported")
+		require.EqualError(t, prefs.SetConnectionPreference("conn-1", jose.A256GCM, jose.XC20P),
+			"content encryption algorithm 'XC20P' is not FIPS approved")
+	})
+
+	t.Run("test connection preference - disallowed saved preferences are dropped", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{"conn-1": []byte(`["XC20P","A256CBC-HS512","A256GCM"]`)}}
+
+		prefs, err := New(&mockProvider{
+			storeProvider:              &mockstorage.MockStoreProvider{Store: store},
+			protocolStateStoreProvider: mockstorage.NewMockStoreProvider(),
+		}, WithFIPSOnly())
+		require.NoError(t, err)
+
+		algs, err := prefs.ConnectionPreference("conn-1")
+		require.NoError(t, err)
+		require.Equal(t, []jose.EncAlg{jose.A256GCM}, algs)
+	})
+
+	t.Run("test connection preference - store errors", func(t *testing.T) {
+		store := &mockstorage.MockStore{Store: map[string][]byte{"conn-1": []byte("{")}}
+		p := &mockProvider{
+			storeProvider:              &mockstorage.MockStoreProvider{Store: store},
+			protocolStateStoreProvider: mockstorage.NewMockStoreProvider(),
+		}
+
+		lookup, err := connection.NewLookup(p)
+		require.NoError(t, err)
+
+		prefs, err := New(p, WithConnectionLookup(lookup))
+		require.NoError(t, err)
+
+		_, err = prefs.ConnectionPreference("conn-1")
+		require.Contains(t, err.Error(), "unmarshal preferences")
+
+		_, err = prefs.Negotiate("conn-1", nil)
+		require.Contains(t, err.Error(), "unmarshal preferences")
+
+		store.ErrGet = fmt.Errorf("get error")
+		_, err = prefs.ConnectionPreference("conn-2")
+		require.EqualError(t, err, "get preferences: get error")
+
+		_, err = prefs.NegotiateDIDs("did:example:alice", "did:example:bob", nil)
+		require.EqualError(t, err, "get connection: get did-connection map : get error")
+
+		store.ErrDelete = fmt.Errorf("delete error")
+		require.EqualError(t, prefs.RemoveConnectionPreference("conn-1"), "remove preferences: delete error")
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+	p := newMockProvider()
+
+	lookup, err := connection.NewLookup(p)
+	require.NoError(t, err)
+
+	prefs, err := New(p, WithConnectionLookup(lookup))
+	require.NoError(t, err)
+
+	require.NoError(t, prefs.SetConnectionPreference("conn-1", jose.XC20P, jose.A256GCM))
+
+	tests := []struct {
+		name         string
+		connectionID string
+		accept       []string
+		expected     jose.EncAlg
+	}{
+		{name: "global preference, peer accepts any", connectionID: "conn-2", expected: jose.A256GCM},
+		{name: "global preference, peer accepts XC20P", connectionID: "conn-2",
+			accept: []string{jose.XC20PALG}, expected: jose.XC20P},
+		{name: "connection preference, peer accepts any", connectionID: "conn-1", expected: jose.XC20P},
+		{name: "connection preference, peer accepts GCM", connectionID: "conn-1",
+			accept: []string{jose.A256GCMALG}, expected: jose.A256GCM},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			alg, err := prefs.Negotiate(tc.connectionID, tc.accept)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, alg)
+		})
+	}
+
+	t.Run("no acceptable algorithm", func(t *testing.T) {
+		_, err := prefs.Negotiate("conn-1", []string{string(a256cbcHS512)})
+		require.True(t, errors.Is(err, ErrNoAcceptableAlg))
+	})
+
+	t.Run("negotiate with the connection of the DIDs", func(t *testing.T) {
+		recorder, err := connection.NewRecorder(p)
+		require.NoError(t, err)
+
+		require.NoError(t, recorder.SaveConnectionRecord(&connection.Record{
+			ConnectionID: "conn-1", State: connection.StateNameCompleted,
+			MyDID: "did:example:alice", TheirDID: "did:example:bob",
+		}))
+
+		alg, err := prefs.NegotiateDIDs("did:example:alice", "did:example:bob", nil)
+		require.NoError(t, err)
+		require.Equal(t, jose.XC20P, alg)
+
+		_, err = prefs.NegotiateDIDs("did:example:alice", "did:example:bob", []string{string(a256cbcHS512)})
+		require.True(t, errors.Is(err, ErrNoAcceptableAlg))
+
+		// no connection, global preferences apply
+		alg, err = prefs.NegotiateDIDs("did:example:alice", "did:example:carol", nil)
+		require.NoError(t, err)
+		require.Equal(t, jose.A256GCM, alg)
+
+		// no connection lookup, global preferences apply
+		globalPrefs, err := New(p)
+		require.NoError(t, err)
+
+		_, err = globalPrefs.NegotiateDIDs("did:example:alice", "did:example:bob", []string{string(a256cbcHS512)})
+		require.True(t, errors.Is(err, ErrNoAcceptableAlg))
+
+		alg, err = globalPrefs.NegotiateDIDs("did:example:alice", "did:example:bob", nil)
+		require.NoError(t, err)
+		require.Equal(t, jose.A256GCM, alg)
+	})
+}
+
+func TestSelect(t *testing.T) {
+	alg, err := Select([]jose.EncAlg{jose.XC20P, jose.A256GCM}, []string{jose.A256GCMALG})
+	require.NoError(t, err)
+	require.Equal(t, jose.A256GCM, alg)
+
+	alg, err = Select([]jose.EncAlg{jose.XC20P, jose.A256GCM}, nil)
+	require.NoError(t, err)
+	require.Equal(t, jose.XC20P, alg)
+
+	_, err = Select(nil, nil)
+	require.True(t, errors.Is(err, ErrNoAcceptableAlg))
+}
+
+func TestIsFIPSApproved(t *testing.T) {
+	require.True(t, IsFIPSApproved(jose.A256GCM))
+	require.False(t, IsFIPSApproved(jose.XC20P))
+}
+
+type mockProvider struct {
+	storeProvider              storage.Provider
+	protocolStateStoreProvider storage.Provider
+}
+
+func (p *mockProvider) StorageProvider() storage.Provider {
+	return p.storeProvider
+}
+
+func (p *mockProvider) ProtocolStateStorageProvider() storage.Provider {
+	return p.protocolStateStoreProvider
+}
+
+func newMockProvider() *mockProvider {
+	return &mockProvider{
+		storeProvider:              mockstorage.NewMockStoreProvider(),
+		protocolStateStoreProvider: mockstorage.NewMockStoreProvider(),
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
@@ -677,3 +678,18 @@ func Test_newCryptoBox(t *testing.T) {
 	_, err = newCryptoBox(&webkms.RemoteKMS{})
 	require.NoError(t, err)
 }
+
+func TestFIPSMode(t *testing.T) {
+	testingKMS, _ := newKMS(t)
+	senderKey := createKey(t, testingKMS)
+	packer := newWithKMSAndCrypto(t, testingKMS)
+
+	fips.SetEnabled(true)
+	defer fips.SetEnabled(false)
+
+	_, err := packer.Pack([]byte("msg"), senderKey, [][]byte{senderKey})
+	require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+	_, err = packer.Unpack([]byte("{}"))
+	require.True(t, errors.Is(err, fips.ErrNotApproved))
+}
@@ -16,6 +16,7 @@ import (
 	chacha "golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/poly1305"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
@@ -27,6 +28,10 @@ import (
 func (p *Packer) Pack(payload, sender []byte, recipientPubKeys [][]byte) ([]byte, error) {
 	var err error
 
+	if err = fips.Check("legacy authcrypt", false); err != nil {
+		return nil, err
+	}
+
 	if len(recipientPubKeys) == 0 {
 		return nil, errors.New("empty recipients keys, must have at least one recipient")
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/btcsuite/btcutil/base58"
 	chacha "golang.org/x/crypto/chacha20poly1305"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
@@ -24,9 +25,14 @@ import (
 // Unpack will decode the envelope using the legacy format
 // Using (X)Chacha20 encryption algorithm and Poly1035 authenticator.
 func (p *Packer) Unpack(envelope []byte) (*transport.Envelope, error) {
+	err := fips.Check("legacy authcrypt", false)
+	if err != nil {
+		return nil, err
+	}
+
 	var envelopeData legacyEnvelope
 
-	err := json.Unmarshal(envelope, &envelopeData)
+	err = json.Unmarshal(envelope, &envelopeData)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chunking
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+)
+
+// ErrHashMismatch is returned when reassembled attachment content does not match the announced hash or size.
+var ErrHashMismatch = errors.New("reassembled attachment does not match its hash")
+
+// Split splits content of the attachment into chunks of at most chunkSize bytes.
+func Split(att *decorator.Attachment, chunkSize int) ([]*Chunk, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("chunk size must be positive")
+	}
+
+	content, err := att.Data.Fetch()
+	if err != nil {
+		return nil, fmt.Errorf("fetch attachment content: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	descriptor := *att
+	descriptor.Data = decorator.AttachmentData{}
+	descriptor.ByteCount = int64(len(content))
+
+	total := (len(content) + chunkSize - 1) / chunkSize
+	if total == 0 {
+		total = 1
+	}
+
+	chunks := make([]*Chunk, total)
+
+	for i := range chunks {
+		end := (i + 1) * chunkSize
+		if end > len(content) {
+			end = len(content)
+		}
+
+		chunks[i] = &Chunk{
+			Type:       ChunkMsgType,
+			ID:         uuid.New().String(),
+			Sequence:   i,
+			Total:      total,
+			ByteCount:  descriptor.ByteCount,
+			Sha256:     hash,
+			Attachment: &descriptor,
+			Data:       base64.StdEncoding.EncodeToString(content[i*chunkSize : end]),
+		}
+	}
+
+	return chunks, nil
+}
+
+// Assemble reassembles the attachment from all of its chunks given in any order and verifies
+// the reassembled content against the hash and size announced by the chunks.
+func Assemble(chunks []*Chunk) (*decorator.Attachment, error) {
+	if len(chunks) == 0 {
+		return nil, errors.New("no chunks to assemble")
+	}
+
+	ordered := append(chunks[:0:0], chunks...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Sequence < ordered[j].Sequence })
+
+	first := ordered[0]
+
+	var content []byte
+
+	for i, chunk := range ordered {
+		if chunk.Sequence != i || chunk.Total != len(ordered) {
+			return nil, fmt.Errorf("chunk %d of %d is missing or duplicated", i, first.Total)
+		}
+
+		if chunk.Sha256 != first.Sha256 || chunk.ByteCount != first.ByteCount {
+			return nil, fmt.Errorf("chunk %d belongs to another attachment", i)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(chunk.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decode chunk %d: %w", i, err)
+		}
+
+		content = append(content, data...)
+	}
+
+	sum := sha256.Sum256(content)
+
+	if int64(len(content)) != first.ByteCount || hex.EncodeToString(sum[:]) != first.Sha256 {
+		return nil, ErrHashMismatch
+	}
+
+	att := &decorator.Attachment{}
+	if first.Attachment != nil {
+		*att = *first.Attachment
+	}
+
+	att.ByteCount = first.ByteCount
+	att.Data = decorator.AttachmentData{
+		Sha256: first.Sha256,
+		Base64: base64.StdEncoding.EncodeToString(content),
+	}
+
+	return att, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chunking
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+)
+
+func newTestAttachment(size int) *decorator.Attachment {
+	return &decorator.Attachment{
+		ID:       "biometric-template",
+		MimeType: "application/octet-stream",
+		FileName: "template.bin",
+		Data: decorator.AttachmentData{
+			Base64: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'x', 'y', 'z'}, size/3+1)[:size]),
+		},
+	}
+}
+
+func TestSplitAndAssemble(t *testing.T) {
+	t.Run("test split and assemble in any order", func(t *testing.T) {
+		att := newTestAttachment(1000)
+
+		chunks, err := Split(att, 300)
+		require.NoError(t, err)
+		require.Len(t, chunks, 4)
+
+		for i, chunk := range chunks {
+			require.Equal(t, ChunkMsgType, chunk.Type)
+			require.NotEmpty(t, chunk.ID)
+			require.Equal(t, i, chunk.Sequence)
+			require.Equal(t, 4, chunk.Total)
+			require.EqualValues(t, 1000, chunk.ByteCount)
+			require.Len(t, chunk.Sha256, 64)
+			require.Equal(t, "biometric-template", chunk.Attachment.ID)
+			require.Empty(t, chunk.Attachment.Data.Base64)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(chunks[3].Data)
+		require.NoError(t, err)
+		require.Len(t, data, 100)
+
+		reassembled, err := Assemble([]*Chunk{chunks[2], chunks[0], chunks[3], chunks[1]})
+		require.NoError(t, err)
+		require.Equal(t, att.Data.Base64, reassembled.Data.Base64)
+		require.Equal(t, chunks[0].Sha256, reassembled.Data.Sha256)
+		require.EqualValues(t, 1000, reassembled.ByteCount)
+		require.Equal(t, att.FileName, reassembled.FileName)
+		require.Equal(t, att.MimeType, reassembled.MimeType)
+	})
+
+	t.Run("test JSON attachment", func(t *testing.T) {
+		chunks, err := Split(&decorator.Attachment{Data: decorator.AttachmentData{
+			JSON: map[string]interface{}{"name": "Jayden Doe"},
+		}}, 10)
+		require.NoError(t, err)
+		require.Len(t, chunks, 3)
+
+		chunks[0].Attachment = nil
+
+		att, err := Assemble(chunks)
+		require.NoError(t, err)
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{"name":"Jayden Doe"}`)), att.Data.Base64)
+	})
+
+	t.Run("test split errors", func(t *testing.T) {
+		_, err := Split(newTestAttachment(10), 0)
+		require.EqualError(t, err, "chunk size must be positive")
+
+		_, err = Split(&decorator.Attachment{Data: decorator.AttachmentData{Base64: "!"}}, 10)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "fetch attachment content")
+	})
+
+	t.Run("test assemble errors", func(t *testing.T) {
+		_, err := Assemble(nil)
+		require.EqualError(t, err, "no chunks to assemble")
+
+		chunks, err := Split(newTestAttachment(100), 30)
+		require.NoError(t, err)
+
+		_, err = Assemble(chunks[1:])
+		require.EqualError(t, err, "chunk 0 of 4 is missing or duplicated")
+
+		_, err = Assemble([]*Chunk{chunks[0], chunks[1], chunks[1], chunks[3]})
+		require.EqualError(t, err, "chunk 2 of 4 is missing or duplicated")
+
+		other, err := Split(newTestAttachment(101), 30)
+		require.NoError(t, err)
+
+		_, err = Assemble([]*Chunk{chunks[0], other[1], chunks[2], chunks[3]})
+		require.EqualError(t, err, "chunk 1 belongs to another attachment")
+
+		invalid := *chunks[2]
+		invalid.Data = "!"
+
+		_, err = Assemble([]*Chunk{chunks[0], chunks[1], &invalid, chunks[3]})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode chunk 2")
+
+		tampered := *chunks[2]
+		tampered.Data = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{'a'}, 30))
+
+		_, err = Assemble([]*Chunk{chunks[0], chunks[1], &tampered, chunks[3]})
+		require.True(t, errors.Is(err, ErrHashMismatch))
+	})
+}
@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chunking
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+)
+
+// Chunk carries a single part of an attachment split for transfer. All chunks of a transfer share the thread ID
+// (transfer ID) along with the total number of chunks, byte count and SHA-256 hash of the whole attachment content.
+type Chunk struct {
+	Type   string            `json:"@type,omitempty"`
+	ID     string            `json:"@id,omitempty"`
+	Thread *decorator.Thread `json:"~thread,omitempty"`
+	// Sequence is the zero based position of the chunk.
+	Sequence int `json:"sequence"`
+	// Total is the number of chunks of the attachment.
+	Total int `json:"total"`
+	// ByteCount is the size of the reassembled attachment content.
+	ByteCount int64 `json:"byte_count"`
+	// Sha256 is the hex encoded SHA-256 hash of the reassembled attachment content.
+	Sha256 string `json:"sha256"`
+	// Attachment describes the attachment being transferred, its data is always empty.
+	Attachment *decorator.Attachment `json:"attachment,omitempty"`
+	// Data is the base64 encoded part of the attachment content.
+	Data string `json:"data"`
+}
+
+// Status is sent by the receiver of an attachment to acknowledge a completed transfer, to report the chunks
+// received so far (the sender resends all others) or to report a failed transfer.
+type Status struct {
+	Type     string            `json:"@type,omitempty"`
+	ID       string            `json:"@id,omitempty"`
+	Thread   *decorator.Thread `json:"~thread,omitempty"`
+	Complete bool              `json:"complete"`
+	Received []int             `json:"received,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// StatusRequest is sent by the sender of an attachment to resume an interrupted transfer,
+// the receiver replies with a status message.
+type StatusRequest struct {
+	Type   string            `json:"@type,omitempty"`
+	ID     string            `json:"@id,omitempty"`
+	Thread *decorator.Thread `json:"~thread,omitempty"`
+}
@@ -0,0 +1,73 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chunking
+
+import "github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+
+const (
+	transferIDPropKey   = "transferID"
+	attachmentIDPropKey = "attachmentID"
+	attachmentPropKey   = "attachment"
+	errorPropKey        = "error"
+)
+
+type eventProps struct {
+	transferID   string
+	attachmentID string
+	attachment   *decorator.Attachment
+	err          error
+}
+
+func newEventProps(transferID, attachmentID string, err error) *eventProps {
+	return &eventProps{
+		transferID:   transferID,
+		attachmentID: attachmentID,
+		err:          err,
+	}
+}
+
+// TransferID returns the ID of the transfer.
+func (e *eventProps) TransferID() string {
+	return e.transferID
+}
+
+// AttachmentID returns the @id of the transferred attachment, if any.
+func (e *eventProps) AttachmentID() string {
+	return e.attachmentID
+}
+
+// Attachment returns the reassembled attachment of a completed transfer. The service doesn't keep it:
+// consumers of the completed event must store it if needed.
+func (e *eventProps) Attachment() *decorator.Attachment {
+	return e.attachment
+}
+
+// Err returns the reason of a failed transfer.
+func (e *eventProps) Err() error {
+	return e.err
+}
+
+// All implements EventProperties interface.
+func (e *eventProps) All() map[string]interface{} {
+	all := map[string]interface{}{
+		transferIDPropKey: e.transferID,
+	}
+
+	if e.attachmentID != "" {
+		all[attachmentIDPropKey] = e.attachmentID
+	}
+
+	if e.attachment != nil {
+		all[attachmentPropKey] = e.attachment
+	}
+
+	if e.err != nil {
+		all[errorPropKey] = e.err
+	}
+
+	return all
+}
@@ -0,0 +1,725 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chunking
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// Chunking defines the protocol name.
+	Chunking = "attachment-chunking"
+	// Spec defines the protocol spec.
+	Spec = "https://didcomm.org/attachment-chunking/1.0/"
+	// ChunkMsgType defines the protocol chunk message type.
+	ChunkMsgType = Spec + "chunk"
+	// StatusMsgType defines the protocol status message type.
+	StatusMsgType = Spec + "status"
+	// StatusRequestMsgType defines the protocol status-request message type.
+	StatusRequestMsgType = Spec + "status-request"
+
+	// DefaultChunkSize is the default maximum size of attachment content carried by a single chunk (64 KiB).
+	DefaultChunkSize = 64 * 1024
+
+	// DefaultMaxChunks is the default maximum number of chunks of an incoming attachment.
+	DefaultMaxChunks = 4096
+
+	// DefaultMaxAttachmentSize is the default maximum size of the content of an incoming attachment (256 MiB).
+	DefaultMaxAttachmentSize = DefaultMaxChunks * DefaultChunkSize
+
+	// DefaultTransferTTL is the default time incoming transfers are kept after their last chunk.
+	DefaultTransferTTL = 24 * time.Hour
+
+	// Namespace is namespace of the chunking store name.
+	Namespace = "chunking"
+)
+
+// states of the transfer reported by message events.
+const (
+	// StateCompleted is reported by the receiver when the attachment has been reassembled and verified,
+	// the attachment is passed with the event properties.
+	StateCompleted = "completed"
+	// StateDelivered is reported by the sender when the receiver has acknowledged the transfer.
+	StateDelivered = "delivered"
+	// StateFailed is reported by both parties when the reassembled attachment does not match its hash.
+	StateFailed = "failed"
+)
+
+const (
+	outgoingKeyPrefix = "out_"
+	incomingKeyPrefix = "in_"
+	chunkKeyPrefix    = "chunk_"
+)
+
+// ErrTransferNotFound is returned when the transfer is unknown.
+var ErrTransferNotFound = errors.New("transfer not found")
+
+var logger = log.New("aries-framework/chunking")
+
+type provider interface {
+	OutboundDispatcher() dispatcher.Outbound
+	StorageProvider() storage.Provider
+}
+
+// Opt configures the chunking service.
+type Opt func(s *Service)
+
+// WithChunkSize sets the maximum size of attachment content carried by a single chunk. It must be chosen
+// so that a packed chunk message fits into the envelope size limit of the transport.
+func WithChunkSize(size int) Opt {
+	return func(s *Service) {
+		s.chunkSize = size
+	}
+}
+
+// WithMaxChunks sets the maximum number of chunks of an incoming attachment, transfers announcing more chunks
+// are rejected. Defaults to DefaultMaxChunks.
+func WithMaxChunks(maxChunks int) Opt {
+	return func(s *Service) {
+		s.maxChunks = maxChunks
+	}
+}
+
+// WithMaxAttachmentSize sets the maximum size in bytes of the content of an incoming attachment, transfers
+// announcing or carrying more content are rejected. Defaults to DefaultMaxAttachmentSize.
+func WithMaxAttachmentSize(size int64) Opt {
+	return func(s *Service) {
+		s.maxAttachmentSize = size
+	}
+}
+
+// WithTransferTTL sets the time incoming transfers are kept after their last chunk. Abandoned transfers, and
+// completed ones which are kept to acknowledge chunks sent again, are deleted when a new transfer starts
+// after that time. Defaults to DefaultTransferTTL.
+func WithTransferTTL(ttl time.Duration) Opt {
+	return func(s *Service) {
+		s.transferTTL = ttl
+	}
+}
+
+// parties binds a transfer to the connection it was started on, messages of the transfer thread from
+// other connections are rejected.
+type parties struct {
+	MyDID    string `json:"myDID"`
+	TheirDID string `json:"theirDID"`
+}
+
+func (p *parties) check(transferID, myDID, theirDID string) error {
+	if p.MyDID != myDID || p.TheirDID != theirDID {
+		return fmt.Errorf("transfer %s belongs to another connection", transferID)
+	}
+
+	return nil
+}
+
+// outgoingTransfer keeps chunks of a sent attachment until the receiver acknowledges them.
+type outgoingTransfer struct {
+	parties
+	Chunks []*Chunk `json:"chunks"`
+}
+
+// incomingTransfer tracks chunks received so far, chunks themselves are stored separately.
+type incomingTransfer struct {
+	parties
+	Total     int       `json:"total"`
+	ByteCount int64     `json:"byteCount"`
+	Sha256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	Received  []bool    `json:"received"`
+	Complete  bool      `json:"complete"`
+	Updated   time.Time `json:"updated"`
+}
+
+func (t *incomingTransfer) received() []int {
+	received := []int{}
+
+	for i, ok := range t.Received {
+		if ok {
+			received = append(received, i)
+		}
+	}
+
+	return received
+}
+
+// pending collects the messages and the events of an inbound message, which are sent once the service
+// is unlocked.
+type pending struct {
+	messages []*pendingMsg
+	events   []service.StateMsg
+}
+
+type pendingMsg struct {
+	msg      interface{}
+	myDID    string
+	theirDID string
+	// action describes the message in errors.
+	action string
+}
+
+func (p *pending) send(action string, msg interface{}, myDID, theirDID string) {
+	p.messages = append(p.messages, &pendingMsg{msg: msg, myDID: myDID, theirDID: theirDID, action: action})
+}
+
+func (p *pending) notify(msg service.DIDCommMsg, stateID string, props *eventProps) {
+	p.events = append(p.events, service.StateMsg{
+		ProtocolName: Chunking,
+		Type:         service.PostState,
+		StateID:      stateID,
+		Msg:          msg,
+		Properties:   props,
+	})
+}
+
+// Service for the attachment chunking protocol. It splits attachments exceeding the envelope size limit into
+// chunks, resends chunks missing on the receiver side (also when resuming an interrupted transfer) and
+// reassembles and hash-verifies attachments on the receiver side.
+type Service struct {
+	service.Message
+	outbound          dispatcher.Outbound
+	store             storage.Store
+	chunkSize         int
+	maxChunks         int
+	maxAttachmentSize int64
+	transferTTL       time.Duration
+	lock              sync.Mutex
+}
+
+// New returns the chunking service.
+func New(prov provider, opts ...Opt) (*Service, error) {
+	store, err := prov.StorageProvider().OpenStore(Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("open chunking store : %w", err)
+	}
+
+	svc := &Service{
+		outbound:          prov.OutboundDispatcher(),
+		store:             store,
+		chunkSize:         DefaultChunkSize,
+		maxChunks:         DefaultMaxChunks,
+		maxAttachmentSize: DefaultMaxAttachmentSize,
+		transferTTL:       DefaultTransferTTL,
+	}
+
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	if svc.chunkSize <= 0 {
+		return nil, errors.New("chunk size must be positive")
+	}
+
+	if svc.maxChunks <= 0 || svc.maxAttachmentSize <= 0 {
+		return nil, errors.New("maximum chunks and attachment size must be positive")
+	}
+
+	if svc.transferTTL <= 0 {
+		return nil, errors.New("transfer TTL must be positive")
+	}
+
+	return svc, nil
+}
+
+// HandleInbound handles inbound chunking messages. The replies and the message events are sent once
+// the transfer records are updated, outside of the lock of the service.
+func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) {
+	transferID, err := msg.ThreadID()
+	if err != nil {
+		return "", fmt.Errorf("threadID: %w", err)
+	}
+
+	p := &pending{}
+
+	s.lock.Lock()
+
+	switch msg.Type() {
+	case ChunkMsgType:
+		err = s.handleChunk(p, msg, transferID, myDID, theirDID)
+	case StatusMsgType:
+		err = s.handleStatus(p, msg, transferID, myDID, theirDID)
+	case StatusRequestMsgType:
+		err = s.handleStatusRequest(p, transferID, myDID, theirDID)
+	default:
+		err = fmt.Errorf("unsupported message type %s", msg.Type())
+	}
+
+	s.lock.Unlock()
+
+	s.emit(p.events)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.send(p.messages); err != nil {
+		return "", err
+	}
+
+	return msg.ID(), nil
+}
+
+// HandleOutbound adherence to dispatcher.ProtocolService.
+func (s *Service) HandleOutbound(_ service.DIDCommMsg, _, _ string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+// Accept checks whether the service can handle the message type.
+func (s *Service) Accept(msgType string) bool {
+	switch msgType {
+	case ChunkMsgType, StatusMsgType, StatusRequestMsgType:
+		return true
+	}
+
+	return false
+}
+
+// Name of the service.
+func (s *Service) Name() string {
+	return Chunking
+}
+
+// SendAttachment splits the attachment into chunks and sends them to theirDID. It returns the transfer ID which
+// is the thread ID of all chunk messages. When sending fails, the transfer ID is returned along with the error
+// and the transfer can be completed later by calling Resume.
+func (s *Service) SendAttachment(att *decorator.Attachment, myDID, theirDID string) (string, error) {
+	chunks, err := Split(att, s.chunkSize)
+	if err != nil {
+		return "", err
+	}
+
+	transferID := uuid.New().String()
+
+	for _, chunk := range chunks {
+		chunk.Thread = &decorator.Thread{ID: transferID}
+	}
+
+	transfer := &outgoingTransfer{parties: parties{MyDID: myDID, TheirDID: theirDID}, Chunks: chunks}
+
+	if err = s.put(outgoingKeyPrefix+transferID, transfer); err != nil {
+		return "", err
+	}
+
+	for _, chunk := range chunks {
+		if err = s.outbound.SendToDID(chunk, myDID, theirDID); err != nil {
+			return transferID, fmt.Errorf("send chunk %d of %d: %w", chunk.Sequence, chunk.Total, err)
+		}
+	}
+
+	return transferID, nil
+}
+
+// Resume asks the receiver of an outgoing transfer which chunks it is missing, the missing chunks are resent
+// once the receiver replies.
+func (s *Service) Resume(transferID string) error {
+	transfer := &outgoingTransfer{}
+
+	if err := s.get(outgoingKeyPrefix+transferID, transfer); err != nil {
+		return err
+	}
+
+	req := &StatusRequest{
+		Type:   StatusRequestMsgType,
+		ID:     uuid.New().String(),
+		Thread: &decorator.Thread{ID: transferID},
+	}
+
+	if err := s.outbound.SendToDID(req, transfer.MyDID, transfer.TheirDID); err != nil {
+		return fmt.Errorf("send status request: %w", err)
+	}
+
+	return nil
+}
+
+// RequestMissingChunks asks the sender of an incoming transfer to resend the chunks which have not been received.
+func (s *Service) RequestMissingChunks(transferID string) error {
+	p := &pending{}
+	transfer := &incomingTransfer{}
+
+	s.lock.Lock()
+	err := s.get(incomingKeyPrefix+transferID, transfer)
+	s.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	s.sendStatus(p, transferID, transfer, transfer.MyDID, transfer.TheirDID)
+
+	return s.send(p.messages)
+}
+
+func (s *Service) handleChunk(p *pending, msg service.DIDCommMsg, transferID, myDID, theirDID string) error {
+	chunk := &Chunk{}
+
+	if err := msg.Decode(chunk); err != nil {
+		return fmt.Errorf("chunk message unmarshal: %w", err)
+	}
+
+	if err := s.checkChunk(chunk); err != nil {
+		return err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(chunk.Data)
+	if err != nil {
+		return fmt.Errorf("decode chunk %d: %w", chunk.Sequence, err)
+	}
+
+	transfer := &incomingTransfer{}
+
+	err = s.get(incomingKeyPrefix+transferID, transfer)
+
+	switch {
+	case errors.Is(err, ErrTransferNotFound):
+		s.sweep()
+
+		transfer = &incomingTransfer{
+			parties:   parties{MyDID: myDID, TheirDID: theirDID},
+			Total:     chunk.Total,
+			ByteCount: chunk.ByteCount,
+			Sha256:    chunk.Sha256,
+			Received:  make([]bool, chunk.Total),
+		}
+	case err != nil:
+		return err
+	}
+
+	if err = transfer.check(transferID, myDID, theirDID); err != nil {
+		return err
+	}
+
+	if transfer.Total != chunk.Total || transfer.ByteCount != chunk.ByteCount || transfer.Sha256 != chunk.Sha256 {
+		return fmt.Errorf("chunk %d of %d does not belong to transfer %s", chunk.Sequence, chunk.Total, transferID)
+	}
+
+	if transfer.Complete {
+		// the sender has not received the acknowledgment
+		s.sendStatus(p, transferID, transfer, myDID, theirDID)
+
+		return nil
+	}
+
+	// chunks sent again are ignored, the first one received is kept
+	if !transfer.Received[chunk.Sequence] {
+		if transfer.Size+int64(len(data)) > transfer.ByteCount {
+			return fmt.Errorf("chunks of transfer %s exceed its byte count %d", transferID, transfer.ByteCount)
+		}
+
+		if err = s.put(chunkKey(transferID, chunk.Sequence), chunk); err != nil {
+			return err
+		}
+
+		transfer.Size += int64(len(data))
+		transfer.Received[chunk.Sequence] = true
+
+		if err = s.putIncoming(transferID, transfer); err != nil {
+			return err
+		}
+	}
+
+	if len(transfer.received()) == transfer.Total {
+		return s.complete(p, msg, transferID, transfer, myDID, theirDID)
+	}
+
+	// the last chunk has arrived while some are missing, ask for them right away
+	if chunk.Sequence == chunk.Total-1 {
+		s.sendStatus(p, transferID, transfer, myDID, theirDID)
+	}
+
+	return nil
+}
+
+// checkChunk checks the position of the chunk and the size of the attachment against the limits of the service,
+// every chunk but the only one of an empty attachment carries at least one byte.
+func (s *Service) checkChunk(chunk *Chunk) error {
+	if chunk.Total <= 0 || chunk.Sequence < 0 || chunk.Sequence >= chunk.Total {
+		return fmt.Errorf("invalid chunk %d of %d", chunk.Sequence, chunk.Total)
+	}
+
+	if chunk.Total > s.maxChunks {
+		return fmt.Errorf("chunk total %d exceeds the maximum of %d chunks", chunk.Total, s.maxChunks)
+	}
+
+	if chunk.ByteCount < 0 || chunk.ByteCount > s.maxAttachmentSize {
+		return fmt.Errorf("attachment byte count %d exceeds the maximum of %d bytes", chunk.ByteCount,
+			s.maxAttachmentSize)
+	}
+
+	if chunk.Total > 1 && int64(chunk.Total) > chunk.ByteCount {
+		return fmt.Errorf("chunk total %d exceeds attachment byte count %d", chunk.Total, chunk.ByteCount)
+	}
+
+	return nil
+}
+
+// complete reassembles the attachment, which is passed to the consumers of the completed event. Its chunks are
+// deleted and the transfer is kept as completed to acknowledge chunks sent again, until its TTL has elapsed.
+func (s *Service) complete(p *pending, msg service.DIDCommMsg, transferID string, transfer *incomingTransfer,
+	myDID, theirDID string) error {
+	chunks := make([]*Chunk, transfer.Total)
+
+	for i := range chunks {
+		chunks[i] = &Chunk{}
+
+		if err := s.get(chunkKey(transferID, i), chunks[i]); err != nil {
+			return err
+		}
+	}
+
+	att, err := Assemble(chunks)
+	if err != nil {
+		logger.Errorf("transfer %s failed: %s", transferID, err)
+
+		// drop received chunks, the sender may send the attachment again
+		s.deleteIncoming(transferID, transfer.Total)
+
+		p.notify(msg, StateFailed, newEventProps(transferID, "", err))
+		p.send("send status", &Status{
+			Type:   StatusMsgType,
+			ID:     uuid.New().String(),
+			Thread: &decorator.Thread{ID: transferID},
+			Error:  err.Error(),
+		}, myDID, theirDID)
+
+		return nil
+	}
+
+	transfer.Complete = true
+	transfer.Received = nil
+
+	if err = s.putIncoming(transferID, transfer); err != nil {
+		return err
+	}
+
+	for i := range chunks {
+		s.deleteKey(chunkKey(transferID, i))
+	}
+
+	props := newEventProps(transferID, att.ID, nil)
+	props.attachment = att
+
+	p.notify(msg, StateCompleted, props)
+	s.sendStatus(p, transferID, transfer, myDID, theirDID)
+
+	return nil
+}
+
+func (s *Service) handleStatus(p *pending, msg service.DIDCommMsg, transferID, myDID, theirDID string) error {
+	status := &Status{}
+
+	if err := msg.Decode(status); err != nil {
+		return fmt.Errorf("status message unmarshal: %w", err)
+	}
+
+	transfer := &outgoingTransfer{}
+
+	if err := s.get(outgoingKeyPrefix+transferID, transfer); err != nil {
+		return err
+	}
+
+	if err := transfer.check(transferID, myDID, theirDID); err != nil {
+		return err
+	}
+
+	var attachmentID string
+	if len(transfer.Chunks) > 0 && transfer.Chunks[0].Attachment != nil {
+		attachmentID = transfer.Chunks[0].Attachment.ID
+	}
+
+	if status.Error != "" {
+		s.deleteKey(outgoingKeyPrefix + transferID)
+		p.notify(msg, StateFailed, newEventProps(transferID, attachmentID, errors.New(status.Error)))
+
+		return nil
+	}
+
+	if status.Complete {
+		s.deleteKey(outgoingKeyPrefix + transferID)
+		p.notify(msg, StateDelivered, newEventProps(transferID, attachmentID, nil))
+
+		return nil
+	}
+
+	received := make(map[int]bool, len(status.Received))
+	for _, i := range status.Received {
+		received[i] = true
+	}
+
+	for _, chunk := range transfer.Chunks {
+		if received[chunk.Sequence] {
+			continue
+		}
+
+		p.send(fmt.Sprintf("resend chunk %d of %d", chunk.Sequence, chunk.Total), chunk, transfer.MyDID,
+			transfer.TheirDID)
+	}
+
+	return nil
+}
+
+func (s *Service) handleStatusRequest(p *pending, transferID, myDID, theirDID string) error {
+	transfer := &incomingTransfer{}
+
+	err := s.get(incomingKeyPrefix+transferID, transfer)
+
+	switch {
+	case errors.Is(err, ErrTransferNotFound):
+	case err != nil:
+		return err
+	default:
+		if err = transfer.check(transferID, myDID, theirDID); err != nil {
+			return err
+		}
+	}
+
+	// an unknown transfer is reported with no chunks received, so the sender resends all of them
+	s.sendStatus(p, transferID, transfer, myDID, theirDID)
+
+	return nil
+}
+
+func (s *Service) sendStatus(p *pending, transferID string, transfer *incomingTransfer, myDID, theirDID string) {
+	status := &Status{
+		Type:     StatusMsgType,
+		ID:       uuid.New().String(),
+		Thread:   &decorator.Thread{ID: transferID},
+		Complete: transfer.Complete,
+	}
+
+	if !transfer.Complete {
+		status.Received = transfer.received()
+	}
+
+	p.send("send status", status, myDID, theirDID)
+}
+
+// send sends the pending messages, it stops at the first error.
+func (s *Service) send(messages []*pendingMsg) error {
+	for _, m := range messages {
+		if err := s.outbound.SendToDID(m.msg, m.myDID, m.theirDID); err != nil {
+			return fmt.Errorf("%s: %w", m.action, err)
+		}
+	}
+
+	return nil
+}
+
+// emit triggers the pending message events, once the service is unlocked.
+func (s *Service) emit(events []service.StateMsg) {
+	if len(events) == 0 {
+		return
+	}
+
+	handlers := s.MsgEvents()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler <- event
+		}
+	}
+}
+
+// sweep deletes the incoming transfers which have not been updated within the TTL, with their chunks.
+func (s *Service) sweep() {
+	records := s.store.Iterator(incomingKeyPrefix, incomingKeyPrefix+storage.EndKeySuffix)
+
+	expired := make(map[string]int)
+	deadline := time.Now().Add(-s.transferTTL)
+
+	for records.Next() {
+		key := string(records.Key())
+		transfer := &incomingTransfer{}
+
+		if err := json.Unmarshal(records.Value(), transfer); err != nil {
+			logger.Warnf("failed to unmarshal %s: %s", key, err)
+
+			continue
+		}
+
+		if transfer.Updated.Before(deadline) {
+			expired[strings.TrimPrefix(key, incomingKeyPrefix)] = transfer.Total
+		}
+	}
+
+	if err := records.Error(); err != nil {
+		logger.Warnf("failed to iterate incoming transfers: %s", err)
+	}
+
+	records.Release()
+
+	for transferID, total := range expired {
+		s.deleteIncoming(transferID, total)
+	}
+}
+
+func (s *Service) deleteIncoming(transferID string, total int) {
+	for i := 0; i < total; i++ {
+		s.deleteKey(chunkKey(transferID, i))
+	}
+
+	s.deleteKey(incomingKeyPrefix + transferID)
+}
+
+func (s *Service) deleteKey(key string) {
+	if err := s.store.Delete(key); err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		logger.Warnf("failed to delete %s: %s", key, err)
+	}
+}
+
+func (s *Service) putIncoming(transferID string, transfer *incomingTransfer) error {
+	transfer.Updated = time.Now()
+
+	return s.put(incomingKeyPrefix+transferID, transfer)
+}
+
+func (s *Service) put(key string, v interface{}) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", key, err)
+	}
+
+	if err = s.store.Put(key, bytes); err != nil {
+		return fmt.Errorf("store %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *Service) get(key string, v interface{}) error {
+	bytes, err := s.store.Get(key)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("%w: %s", ErrTransferNotFound, key)
+	}
+
+	if err != nil {
+		return fmt.Errorf("get %s: %w", key, err)
+	}
+
+	if err = json.Unmarshal(bytes, v); err != nil {
+		return fmt.Errorf("unmarshal %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func chunkKey(transferID string, sequence int) string {
+	return chunkKeyPrefix + transferID + "_" + strconv.Itoa(sequence)
+}
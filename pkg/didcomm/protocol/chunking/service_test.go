@@ -0,0 +1,695 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package chunking
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/mock/didcomm/dispatcher"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+)
+
+const (
+	aliceDID = "did:example:alice"
+	bobDID   = "did:example:bob"
+)
+
+// delivery is a message in transit between two services.
+type delivery struct {
+	to       *Service
+	msg      service.DIDCommMsgMap
+	myDID    string
+	theirDID string
+}
+
+// network queues messages sent by alice and bob until they are delivered by pump.
+type network struct {
+	queue []*delivery
+}
+
+func (n *network) outbound(to func() *Service) *mockdispatcher.MockOutbound {
+	return &mockdispatcher.MockOutbound{
+		ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+			n.queue = append(n.queue, &delivery{
+				to:       to(),
+				msg:      service.NewDIDCommMsgMap(msg),
+				myDID:    theirDID,
+				theirDID: myDID,
+			})
+
+			return nil
+		},
+	}
+}
+
+// pump delivers queued messages until the queue is empty, messages matching drop are lost.
+func (n *network) pump(t *testing.T, drop func(d *delivery) bool) {
+	t.Helper()
+
+	for len(n.queue) > 0 {
+		d := n.queue[0]
+		n.queue = n.queue[1:]
+
+		if drop != nil && drop(d) {
+			continue
+		}
+
+		_, err := d.to.HandleInbound(d.msg, d.myDID, d.theirDID)
+		require.NoError(t, err)
+	}
+}
+
+func newParties(t *testing.T) (*network, *Service, *Service) {
+	t.Helper()
+
+	n := &network{}
+
+	var alice, bob *Service
+
+	alice, err := New(&mockprovider.Provider{
+		StorageProviderValue:    mockstore.NewMockStoreProvider(),
+		OutboundDispatcherValue: n.outbound(func() *Service { return bob }),
+	}, WithChunkSize(300))
+	require.NoError(t, err)
+
+	bob, err = New(&mockprovider.Provider{
+		StorageProviderValue:    mockstore.NewMockStoreProvider(),
+		OutboundDispatcherValue: n.outbound(func() *Service { return alice }),
+	}, WithChunkSize(300))
+	require.NoError(t, err)
+
+	return n, alice, bob
+}
+
+func registerEvents(t *testing.T, s *Service) chan service.StateMsg {
+	t.Helper()
+
+	events := make(chan service.StateMsg, 10)
+	require.NoError(t, s.RegisterMsgEvent(events))
+
+	return events
+}
+
+// storeKeys returns the sorted keys of the records of the service.
+func storeKeys(s *Service) []string {
+	keys := []string{}
+
+	for key := range s.store.(*mockstore.MockStore).Store {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sequence(d *delivery) int {
+	if d.msg.Type() != ChunkMsgType {
+		return -1
+	}
+
+	chunk := &Chunk{}
+	if err := d.msg.Decode(chunk); err != nil {
+		return -1
+	}
+
+	return chunk.Sequence
+}
+
+func TestNew(t *testing.T) {
+	t.Run("test new - success", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()})
+		require.NoError(t, err)
+		require.Equal(t, DefaultChunkSize, svc.chunkSize)
+		require.Equal(t, Chunking, svc.Name())
+		require.True(t, svc.Accept(ChunkMsgType))
+		require.True(t, svc.Accept(StatusMsgType))
+		require.True(t, svc.Accept(StatusRequestMsgType))
+		require.False(t, svc.Accept("unsupported"))
+
+		_, err = svc.HandleOutbound(nil, "", "")
+		require.EqualError(t, err, "not implemented")
+	})
+
+	t.Run("test new - errors", func(t *testing.T) {
+		_, err := New(&mockprovider.Provider{StorageProviderValue: &mockstore.MockStoreProvider{
+			ErrOpenStoreHandle: errors.New("store error"),
+		}})
+		require.EqualError(t, err, "open chunking store : store error")
+
+		_, err = New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()}, WithChunkSize(0))
+		require.EqualError(t, err, "chunk size must be positive")
+
+		_, err = New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()}, WithMaxChunks(0))
+		require.EqualError(t, err, "maximum chunks and attachment size must be positive")
+
+		_, err = New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()},
+			WithMaxAttachmentSize(0))
+		require.EqualError(t, err, "maximum chunks and attachment size must be positive")
+
+		_, err = New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()}, WithTransferTTL(0))
+		require.EqualError(t, err, "transfer TTL must be positive")
+	})
+}
+
+func TestTransfer(t *testing.T) {
+	att := newTestAttachment(1000)
+
+	t.Run("test transfer completed", func(t *testing.T) {
+		n, alice, bob := newParties(t)
+		aliceEvents, bobEvents := registerEvents(t, alice), registerEvents(t, bob)
+
+		transferID, err := alice.SendAttachment(att, aliceDID, bobDID)
+		require.NoError(t, err)
+		require.Len(t, n.queue, 4)
+
+		n.pump(t, nil)
+
+		received := <-bobEvents
+		require.Equal(t, StateCompleted, received.StateID)
+		require.Equal(t, Chunking, received.ProtocolName)
+		require.Equal(t, service.PostState, received.Type)
+
+		props, ok := received.Properties.(*eventProps)
+		require.True(t, ok)
+		require.Equal(t, transferID, props.TransferID())
+		require.Equal(t, att.ID, props.AttachmentID())
+		require.NoError(t, props.Err())
+
+		reassembled := props.Attachment()
+		require.NotNil(t, reassembled)
+		require.Equal(t, att.Data.Base64, reassembled.Data.Base64)
+		require.Equal(t, att.FileName, reassembled.FileName)
+		require.Equal(t, map[string]interface{}{
+			"transferID": transferID, "attachmentID": att.ID, "attachment": reassembled,
+		}, props.All())
+
+		// the receiver only keeps the completed transfer, without its chunks and attachment
+		require.Equal(t, []string{"in_" + transferID}, storeKeys(bob))
+
+		delivered := <-aliceEvents
+		require.Equal(t, StateDelivered, delivered.StateID)
+		require.Equal(t, transferID, delivered.Properties.All()["transferID"])
+
+		// acknowledged transfers are forgotten by the sender
+		require.True(t, errors.Is(alice.Resume(transferID), ErrTransferNotFound))
+
+		// a duplicate chunk is acknowledged again
+		chunks, err := Split(att, 300)
+		require.NoError(t, err)
+
+		chunks[0].Thread = &decorator.Thread{ID: transferID}
+
+		_, err = bob.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+		require.Len(t, n.queue, 1)
+		require.Equal(t, true, n.queue[0].msg["complete"])
+	})
+
+	t.Run("test lost chunks are requested when the last one arrives", func(t *testing.T) {
+		n, alice, bob := newParties(t)
+		bobEvents := registerEvents(t, bob)
+
+		transferID, err := alice.SendAttachment(att, aliceDID, bobDID)
+		require.NoError(t, err)
+
+		lost := true
+
+		n.pump(t, func(d *delivery) bool {
+			if lost && sequence(d) == 1 {
+				lost = false
+
+				return true
+			}
+
+			return false
+		})
+
+		completed := <-bobEvents
+		require.Equal(t, StateCompleted, completed.StateID)
+		require.Equal(t, transferID, completed.Properties.All()["transferID"])
+		require.Equal(t, att.Data.Base64, completed.Properties.(*eventProps).Attachment().Data.Base64)
+	})
+
+	t.Run("test interrupted transfer is resumed", func(t *testing.T) {
+		n, alice, bob := newParties(t)
+		bobEvents := registerEvents(t, bob)
+
+		transferID, err := alice.SendAttachment(att, aliceDID, bobDID)
+		require.NoError(t, err)
+
+		// connection dropped after the first two chunks
+		n.pump(t, func(d *delivery) bool { return sequence(d) > 1 })
+		require.Empty(t, bobEvents)
+
+		require.NoError(t, alice.Resume(transferID))
+		require.Len(t, n.queue, 1)
+		require.Equal(t, StatusRequestMsgType, n.queue[0].msg.Type())
+
+		n.pump(t, nil)
+
+		completed := <-bobEvents
+		require.Equal(t, StateCompleted, completed.StateID)
+		require.Equal(t, transferID, completed.Properties.All()["transferID"])
+	})
+
+	t.Run("test receiver requests missing chunks", func(t *testing.T) {
+		n, alice, bob := newParties(t)
+		bobEvents := registerEvents(t, bob)
+
+		transferID, err := alice.SendAttachment(att, aliceDID, bobDID)
+		require.NoError(t, err)
+
+		n.pump(t, func(d *delivery) bool { return sequence(d) > 0 })
+
+		require.NoError(t, bob.RequestMissingChunks(transferID))
+		require.Len(t, n.queue, 1)
+
+		status := &Status{}
+		require.NoError(t, n.queue[0].msg.Decode(status))
+		require.Equal(t, []int{0}, status.Received)
+
+		n.pump(t, nil)
+
+		require.Equal(t, StateCompleted, (<-bobEvents).StateID)
+
+		require.True(t, errors.Is(bob.RequestMissingChunks("unknown"), ErrTransferNotFound))
+	})
+
+	t.Run("test unknown transfer is sent again on resume", func(t *testing.T) {
+		n, alice, bob := newParties(t)
+		bobEvents := registerEvents(t, bob)
+
+		transferID, err := alice.SendAttachment(att, aliceDID, bobDID)
+		require.NoError(t, err)
+
+		n.queue = nil
+
+		require.NoError(t, alice.Resume(transferID))
+		n.pump(t, nil)
+
+		require.Equal(t, StateCompleted, (<-bobEvents).StateID)
+	})
+
+	t.Run("test tampered chunk fails the transfer", func(t *testing.T) {
+		n, alice, bob := newParties(t)
+		aliceEvents, bobEvents := registerEvents(t, alice), registerEvents(t, bob)
+
+		transferID, err := alice.SendAttachment(att, aliceDID, bobDID)
+		require.NoError(t, err)
+
+		n.queue[2].msg["data"] = base64.StdEncoding.EncodeToString(make([]byte, 300))
+
+		n.pump(t, nil)
+
+		failed := <-bobEvents
+		require.Equal(t, StateFailed, failed.StateID)
+		require.Contains(t, failed.Properties.All()["error"].(error).Error(), ErrHashMismatch.Error())
+
+		failed = <-aliceEvents
+		require.Equal(t, StateFailed, failed.StateID)
+		require.Equal(t, att.ID, failed.Properties.All()["attachmentID"])
+
+		require.Empty(t, storeKeys(bob))
+
+		require.True(t, errors.Is(alice.Resume(transferID), ErrTransferNotFound))
+	})
+}
+
+func TestTransferRecords(t *testing.T) {
+	chunks, err := Split(newTestAttachment(100), 30)
+	require.NoError(t, err)
+
+	transferChunks := func(transferID string) []*Chunk {
+		transfer := make([]*Chunk, len(chunks))
+
+		for i, chunk := range chunks {
+			c := *chunk
+			c.Thread = &decorator.Thread{ID: transferID}
+			transfer[i] = &c
+		}
+
+		return transfer
+	}
+
+	t.Run("test abandoned transfers are deleted after the TTL", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:    mockstore.NewMockStoreProvider(),
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{},
+		}, WithTransferTTL(50*time.Millisecond))
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(transferChunks("abandoned")[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+
+		for _, chunk := range transferChunks("completed") {
+			_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunk), bobDID, aliceDID)
+			require.NoError(t, err)
+		}
+
+		// transfers are kept within the TTL
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(transferChunks("recent")[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"chunk_abandoned_0", "chunk_recent_0", "in_abandoned", "in_completed", "in_recent"},
+			storeKeys(svc))
+
+		time.Sleep(100 * time.Millisecond)
+
+		// the continued transfer is kept
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(transferChunks("recent")[1]), bobDID, aliceDID)
+		require.NoError(t, err)
+
+		// expired transfers are deleted when a new one starts
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(transferChunks("new")[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"chunk_new_0", "chunk_recent_0", "chunk_recent_1", "in_new", "in_recent"},
+			storeKeys(svc))
+
+		// an expired transfer starts again
+		require.True(t, errors.Is(svc.RequestMissingChunks("abandoned"), ErrTransferNotFound))
+	})
+
+	t.Run("test replies and events are sent unlocked", func(t *testing.T) {
+		var svc *Service
+
+		statuses := 0
+
+		svc, err = New(&mockprovider.Provider{
+			StorageProviderValue: mockstore.NewMockStoreProvider(),
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{
+				ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+					statuses++
+
+					// the service can be called while a reply is sent
+					if statuses == 1 {
+						return svc.RequestMissingChunks("transfer-1")
+					}
+
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		events := make(chan service.StateMsg)
+		require.NoError(t, svc.RegisterMsgEvent(events))
+
+		transfer := transferChunks("transfer-1")
+
+		for _, chunk := range transfer[:len(transfer)-1] {
+			_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunk), bobDID, aliceDID)
+			require.NoError(t, err)
+		}
+
+		done := make(chan error)
+
+		go func() {
+			_, e := svc.HandleInbound(service.NewDIDCommMsgMap(transfer[len(transfer)-1]), bobDID, aliceDID)
+			done <- e
+		}()
+
+		// the service can be called while the consumer has not received the completed event
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(transferChunks("transfer-2")[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+
+		completed := <-events
+		require.Equal(t, StateCompleted, completed.StateID)
+		require.NoError(t, <-done)
+		require.Equal(t, 2, statuses)
+	})
+}
+
+func TestSendErrors(t *testing.T) {
+	t.Run("test split error", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{StorageProviderValue: mockstore.NewMockStoreProvider()})
+		require.NoError(t, err)
+
+		_, err = svc.SendAttachment(&decorator.Attachment{Data: decorator.AttachmentData{Base64: "!"}}, aliceDID, bobDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "fetch attachment content")
+	})
+
+	t.Run("test store error", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{StorageProviderValue: &mockstore.MockStoreProvider{
+			Store: &mockstore.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")},
+		}})
+		require.NoError(t, err)
+
+		_, err = svc.SendAttachment(newTestAttachment(10), aliceDID, bobDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "put error")
+	})
+
+	t.Run("test send error can be resumed", func(t *testing.T) {
+		svc, err := New(&mockprovider.Provider{
+			StorageProviderValue:    mockstore.NewMockStoreProvider(),
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{SendErr: errors.New("send error")},
+		})
+		require.NoError(t, err)
+
+		transferID, err := svc.SendAttachment(newTestAttachment(10), aliceDID, bobDID)
+		require.EqualError(t, err, "send chunk 0 of 1: send error")
+		require.NotEmpty(t, transferID)
+
+		require.EqualError(t, svc.Resume(transferID), "send status request: send error")
+	})
+}
+
+func TestHandleInboundErrors(t *testing.T) {
+	chunks, err := Split(newTestAttachment(100), 30)
+	require.NoError(t, err)
+
+	for _, chunk := range chunks {
+		chunk.Thread = &decorator.Thread{ID: "transfer-1"}
+	}
+
+	newService := func(outbound *mockdispatcher.MockOutbound, store *mockstore.MockStore) *Service {
+		if store == nil {
+			store = &mockstore.MockStore{Store: map[string][]byte{}}
+		}
+
+		svc, e := New(&mockprovider.Provider{
+			StorageProviderValue:    &mockstore.MockStoreProvider{Store: store},
+			OutboundDispatcherValue: outbound,
+		})
+		require.NoError(t, e)
+
+		return svc
+	}
+
+	t.Run("test invalid messages", func(t *testing.T) {
+		svc := newService(&mockdispatcher.MockOutbound{}, nil)
+
+		_, err := svc.HandleInbound(service.DIDCommMsgMap{"@type": ChunkMsgType}, bobDID, aliceDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "threadID")
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&Chunk{Type: "unknown", ID: "1"}), bobDID, aliceDID)
+		require.EqualError(t, err, "unsupported message type unknown")
+
+		msg := service.NewDIDCommMsgMap(chunks[0])
+		msg["total"] = "x"
+
+		_, err = svc.HandleInbound(msg, bobDID, aliceDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "chunk message unmarshal")
+
+		invalid := *chunks[0]
+		invalid.Sequence = 4
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&invalid), bobDID, aliceDID)
+		require.EqualError(t, err, "invalid chunk 4 of 4")
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+
+		invalid = *chunks[1]
+		invalid.Total = 5
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&invalid), bobDID, aliceDID)
+		require.EqualError(t, err, "chunk 1 of 5 does not belong to transfer transfer-1")
+
+		invalid = *chunks[1]
+		invalid.Data = "!"
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&invalid), bobDID, aliceDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode chunk 1")
+
+		msg = service.NewDIDCommMsgMap(&Status{Type: StatusMsgType, ID: "1", Thread: &decorator.Thread{ID: "transfer-1"}})
+		msg["complete"] = "x"
+
+		_, err = svc.HandleInbound(msg, aliceDID, bobDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status message unmarshal")
+
+		msg = service.NewDIDCommMsgMap(&Status{Type: StatusMsgType, ID: "1", Thread: &decorator.Thread{ID: "unknown"}})
+
+		_, err = svc.HandleInbound(msg, aliceDID, bobDID)
+		require.True(t, errors.Is(err, ErrTransferNotFound))
+	})
+
+	t.Run("test oversized transfers are rejected", func(t *testing.T) {
+		svc := newService(&mockdispatcher.MockOutbound{}, nil)
+
+		oversized := *chunks[0]
+		oversized.Total = DefaultMaxChunks + 1
+		oversized.ByteCount = DefaultMaxChunks + 1
+
+		_, err := svc.HandleInbound(service.NewDIDCommMsgMap(&oversized), bobDID, aliceDID)
+		require.EqualError(t, err, "chunk total 4097 exceeds the maximum of 4096 chunks")
+
+		oversized = *chunks[0]
+		oversized.ByteCount = DefaultMaxAttachmentSize + 1
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&oversized), bobDID, aliceDID)
+		require.EqualError(t, err, "attachment byte count 268435457 exceeds the maximum of 268435456 bytes")
+
+		oversized = *chunks[0]
+		oversized.ByteCount = 3
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&oversized), bobDID, aliceDID)
+		require.EqualError(t, err, "chunk total 4 exceeds attachment byte count 3")
+
+		svc, err = New(&mockprovider.Provider{
+			StorageProviderValue:    mockstore.NewMockStoreProvider(),
+			OutboundDispatcherValue: &mockdispatcher.MockOutbound{},
+		}, WithMaxChunks(3), WithMaxAttachmentSize(50))
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.EqualError(t, err, "chunk total 4 exceeds the maximum of 3 chunks")
+
+		svc = newService(&mockdispatcher.MockOutbound{}, nil)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+
+		// the content of the chunks can't exceed the announced byte count
+		oversized = *chunks[1]
+		oversized.Data = base64.StdEncoding.EncodeToString(make([]byte, 71))
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&oversized), bobDID, aliceDID)
+		require.EqualError(t, err, "chunks of transfer transfer-1 exceed its byte count 100")
+	})
+
+	t.Run("test messages from another connection are rejected", func(t *testing.T) {
+		const malloryDID = "did:example:mallory"
+
+		svc := newService(&mockdispatcher.MockOutbound{}, nil)
+		events := registerEvents(t, svc)
+
+		_, err := svc.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[1]), bobDID, malloryDID)
+		require.EqualError(t, err, "transfer transfer-1 belongs to another connection")
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[1]), "did:example:other", aliceDID)
+		require.EqualError(t, err, "transfer transfer-1 belongs to another connection")
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&StatusRequest{
+			Type: StatusRequestMsgType, ID: "1", Thread: &decorator.Thread{ID: "transfer-1"},
+		}), bobDID, malloryDID)
+		require.EqualError(t, err, "transfer transfer-1 belongs to another connection")
+
+		transferID, err := svc.SendAttachment(newTestAttachment(10), aliceDID, bobDID)
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&Status{
+			Type: StatusMsgType, ID: "1", Thread: &decorator.Thread{ID: transferID}, Complete: true,
+		}), aliceDID, malloryDID)
+		require.EqualError(t, err, "transfer "+transferID+" belongs to another connection")
+
+		// the transfer goes on with the right connection
+		for _, chunk := range chunks[1:] {
+			_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunk), bobDID, aliceDID)
+			require.NoError(t, err)
+		}
+
+		completed := <-events
+		require.Equal(t, StateCompleted, completed.StateID)
+		require.Equal(t, int64(100), completed.Properties.(*eventProps).Attachment().ByteCount)
+	})
+
+	t.Run("test send status errors", func(t *testing.T) {
+		svc := newService(&mockdispatcher.MockOutbound{SendErr: errors.New("send error")}, nil)
+
+		for _, chunk := range chunks[:3] {
+			_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunk), bobDID, aliceDID)
+			require.NoError(t, err)
+		}
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[3]), bobDID, aliceDID)
+		require.EqualError(t, err, "send status: send error")
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&StatusRequest{
+			Type: StatusRequestMsgType, ID: "1", Thread: &decorator.Thread{ID: "transfer-1"},
+		}), bobDID, aliceDID)
+		require.EqualError(t, err, "send status: send error")
+	})
+
+	t.Run("test resend error", func(t *testing.T) {
+		sent := 0
+
+		svc := newService(&mockdispatcher.MockOutbound{
+			ValidateSendToDID: func(msg interface{}, myDID, theirDID string) error {
+				sent++
+				if sent > 1 {
+					return errors.New("send error")
+				}
+
+				return nil
+			},
+		}, nil)
+
+		transferID, err := svc.SendAttachment(newTestAttachment(10), aliceDID, bobDID)
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&Status{
+			Type: StatusMsgType, ID: "1", Thread: &decorator.Thread{ID: transferID},
+		}), aliceDID, bobDID)
+		require.EqualError(t, err, "resend chunk 0 of 1: send error")
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		store := &mockstore.MockStore{Store: map[string][]byte{}, ErrGet: errors.New("get error")}
+		svc := newService(&mockdispatcher.MockOutbound{}, store)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.EqualError(t, err, "get in_transfer-1: get error")
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(&StatusRequest{
+			Type: StatusRequestMsgType, ID: "1", Thread: &decorator.Thread{ID: "transfer-1"},
+		}), bobDID, aliceDID)
+		require.EqualError(t, err, "get in_transfer-1: get error")
+
+		require.EqualError(t, svc.RequestMissingChunks("transfer-1"), "get in_transfer-1: get error")
+
+		store = &mockstore.MockStore{Store: map[string][]byte{"in_transfer-1": []byte("{")}}
+		svc = newService(&mockdispatcher.MockOutbound{}, store)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal in_transfer-1")
+
+		store = &mockstore.MockStore{Store: map[string][]byte{}, ErrPut: errors.New("put error")}
+		svc = newService(&mockdispatcher.MockOutbound{}, store)
+
+		_, err = svc.HandleInbound(service.NewDIDCommMsgMap(chunks[0]), bobDID, aliceDID)
+		require.EqualError(t, err, "store chunk_transfer-1_0: put error")
+	})
+}
@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decorator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	traceVersion = "00"
+	traceSampled = "01"
+
+	traceIDSize = 16
+	spanIDSize  = 8
+)
+
+// nolint:gochecknoglobals
+var traceParentRegex = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// Trace is the ~trace_context decorator: it carries correlation IDs of a message across agents in the W3C trace
+// context format (https://www.w3.org/TR/trace-context/), so that the spans and logs of the sender and the receiver
+// of the message can be correlated. The trace ID is kept for the whole exchange and each message has its own
+// span ID. It's distinct from the ~trace decorator of Aries RFC 0034, which reports message tracing events.
+// The trace parent has the format of OpenTelemetry span contexts, which the application converts from and to.
+type Trace struct {
+	// TraceParent is "00-<trace ID>-<span ID>-<trace flags>" with hex encoded IDs.
+	TraceParent string `json:"traceparent"`
+	// TraceState is vendor specific trace information, propagated as is.
+	TraceState string `json:"tracestate,omitempty"`
+}
+
+// NewTrace starts a new trace.
+func NewTrace() (*Trace, error) {
+	traceID, err := randomHex(traceIDSize)
+	if err != nil {
+		return nil, err
+	}
+
+	spanID, err := randomHex(spanIDSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Trace{TraceParent: strings.Join([]string{traceVersion, traceID, spanID, traceSampled}, "-")}, nil
+}
+
+// Validate checks the trace parent.
+func (t *Trace) Validate() error {
+	parts := traceParentRegex.FindStringSubmatch(t.TraceParent)
+	if parts == nil {
+		return fmt.Errorf("invalid traceparent %q", t.TraceParent)
+	}
+
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return fmt.Errorf("invalid traceparent %q: all zero ID", t.TraceParent)
+	}
+
+	return nil
+}
+
+// TraceID returns the trace ID or an empty string if the trace parent is invalid.
+func (t *Trace) TraceID() string {
+	return t.part(1) // nolint:gomnd
+}
+
+// SpanID returns the span ID or an empty string if the trace parent is invalid.
+func (t *Trace) SpanID() string {
+	return t.part(2) // nolint:gomnd
+}
+
+// Child returns the trace of a message caused by the message of this trace (e.g. a reply):
+// the trace ID, flags and state are kept and a new span ID is generated.
+// A new trace is started if the trace parent is invalid.
+func (t *Trace) Child() (*Trace, error) {
+	if t.Validate() != nil {
+		return NewTrace()
+	}
+
+	spanID, err := randomHex(spanIDSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Trace{
+		TraceParent: strings.Join([]string{traceVersion, t.TraceID(), spanID, t.part(3)}, "-"), // nolint:gomnd
+		TraceState:  t.TraceState,
+	}, nil
+}
+
+func (t *Trace) part(i int) string {
+	if t.Validate() != nil {
+		return ""
+	}
+
+	return traceParentRegex.FindStringSubmatch(t.TraceParent)[i]
+}
+
+func randomHex(size int) (string, error) {
+	id := make([]byte, size)
+
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", fmt.Errorf("generate trace id: %w", err)
+	}
+
+	return hex.EncodeToString(id), nil
+}
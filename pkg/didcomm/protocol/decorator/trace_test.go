@@ -0,0 +1,61 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decorator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace(t *testing.T) {
+	t.Run("new trace", func(t *testing.T) {
+		trace, err := NewTrace()
+		require.NoError(t, err)
+		require.NoError(t, trace.Validate())
+		require.Len(t, trace.TraceID(), 32)
+		require.Len(t, trace.SpanID(), 16)
+
+		other, err := NewTrace()
+		require.NoError(t, err)
+		require.NotEqual(t, trace.TraceID(), other.TraceID())
+	})
+
+	t.Run("child", func(t *testing.T) {
+		trace := &Trace{
+			TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			TraceState:  "vendor=1",
+		}
+
+		child, err := trace.Child()
+		require.NoError(t, err)
+		require.NoError(t, child.Validate())
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", child.TraceID())
+		require.NotEqual(t, trace.SpanID(), child.SpanID())
+		require.Equal(t, "vendor=1", child.TraceState)
+		require.True(t, len(child.TraceParent) > 3 && child.TraceParent[len(child.TraceParent)-3:] == "-00")
+
+		child, err = (&Trace{TraceParent: "invalid"}).Child()
+		require.NoError(t, err)
+		require.NoError(t, child.Validate())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, traceParent := range []string{
+			"",
+			"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		} {
+			trace := &Trace{TraceParent: traceParent}
+			require.Error(t, trace.Validate(), traceParent)
+			require.Empty(t, trace.TraceID())
+			require.Empty(t, trace.SpanID())
+		}
+	})
+}
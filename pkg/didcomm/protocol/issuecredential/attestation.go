@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package issuecredential
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/attestation"
+)
+
+// WalletAttestationFormat is the format of the wallet attestation attached to request-credential messages.
+const WalletAttestationFormat = attestation.AttestationType
+
+// ErrNoWalletAttestation is returned when no wallet attestation is attached to a request-credential message.
+var ErrNoWalletAttestation = errors.New("no wallet attestation attached")
+
+// AttachWalletAttestation attaches the wallet attestation and the proof of possession of the attested key to the
+// request, so that the issuer can authenticate the wallet. The proof of possession is expected to be addressed to
+// the issuer DID, to contain the thread ID of the exchange as nonce and to be created for this request: the issuer
+// accepts it once and only shortly after its issuance (see attestation.WithReplayCache).
+func (r *RequestCredential) AttachWalletAttestation(a *attestation.ClientAttestation) {
+	id := uuid.New().String()
+
+	r.Formats = append(r.Formats, Format{AttachID: id, Format: WalletAttestationFormat})
+	r.RequestsAttach = append(r.RequestsAttach, decorator.Attachment{
+		ID:       id,
+		MimeType: "application/json",
+		Data:     decorator.AttachmentData{JSON: a},
+	})
+}
+
+// WalletAttestation returns the wallet attestation attached to the request, to be verified by the issuer.
+func (r *RequestCredential) WalletAttestation() (*attestation.ClientAttestation, error) {
+	for _, format := range r.Formats {
+		if format.Format != WalletAttestationFormat {
+			continue
+		}
+
+		for i := range r.RequestsAttach {
+			if r.RequestsAttach[i].ID != format.AttachID {
+				continue
+			}
+
+			data, err := r.RequestsAttach[i].Data.Fetch()
+			if err != nil {
+				return nil, fmt.Errorf("wallet attestation: %w", err)
+			}
+
+			var a attestation.ClientAttestation
+
+			if err = json.Unmarshal(data, &a); err != nil {
+				return nil, fmt.Errorf("wallet attestation: %w", err)
+			}
+
+			return &a, nil
+		}
+	}
+
+	return nil, ErrNoWalletAttestation
+}
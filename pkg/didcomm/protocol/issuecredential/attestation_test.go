@@ -0,0 +1,53 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package issuecredential
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/attestation"
+)
+
+func TestRequestCredential_WalletAttestation(t *testing.T) {
+	a := &attestation.ClientAttestation{Attestation: "eyJ.attestation.sig", PoP: "eyJ.pop.sig"}
+
+	request := &RequestCredential{
+		Type:           RequestCredentialMsgType,
+		RequestsAttach: []decorator.Attachment{{ID: "credential-request"}},
+	}
+	request.AttachWalletAttestation(a)
+
+	msg := service.NewDIDCommMsgMap(request)
+
+	received := &RequestCredential{}
+	require.NoError(t, msg.Decode(received))
+	require.Len(t, received.RequestsAttach, 2)
+
+	walletAttestation, err := received.WalletAttestation()
+	require.NoError(t, err)
+	require.Equal(t, a, walletAttestation)
+
+	_, err = (&RequestCredential{}).WalletAttestation()
+	require.True(t, errors.Is(err, ErrNoWalletAttestation))
+
+	_, err = (&RequestCredential{
+		Formats:        []Format{{AttachID: "1", Format: WalletAttestationFormat}},
+		RequestsAttach: []decorator.Attachment{{ID: "1"}},
+	}).WalletAttestation()
+	require.EqualError(t, err, "wallet attestation: no contents in this attachment")
+
+	_, err = (&RequestCredential{
+		Formats:        []Format{{AttachID: "1", Format: WalletAttestationFormat}},
+		RequestsAttach: []decorator.Attachment{{ID: "1", Data: decorator.AttachmentData{Base64: "ew=="}}},
+	}).WalletAttestation()
+	require.Error(t, err)
+}
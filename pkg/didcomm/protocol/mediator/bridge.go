@@ -0,0 +1,210 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mediator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// Envelope formats, as found in the `typ` protected header of the envelopes.
+const (
+	// EnvelopeV1 is the format of DIDComm v1 envelopes (Aries RFC 0019), used by legacy agents.
+	EnvelopeV1 = "JWM/1.0"
+
+	// EnvelopeV2 is the format of DIDComm v2 envelopes (JWE).
+	EnvelopeV2 = "didcomm-envelope-enc"
+
+	// data key to store the envelope formats accepted by a routed agent.
+	routeAcceptDataKey = "route_accept_%s"
+)
+
+// routeAccept is the routing relationship with an agent that accepts only some envelope formats.
+type routeAccept struct {
+	Accept []string `json:"accept"`
+}
+
+// bridgeEnvelope returns the envelope to forward to the routed agent identified by theirDID, in a format it
+// accepts. Agents which didn't ask for any format accept all of them.
+//
+// Forwarded envelopes are end-to-end: the router can't decrypt them, so it can't re-pack them in another format.
+// An envelope in a format not accepted by the agent is bridged instead: it is wrapped in a forward message to the
+// recipient key, packed for the recipient keys of the agent in the first format it accepts. The agent unpacks the
+// outer layer with the packer of its format, and the inner envelope with the packer of the sender's format (see
+// unpackBridgedForward).
+func (s *Service) bridgeEnvelope(forward *model.Forward, dest *service.Destination,
+	theirDID string) (*model.Envelope, error) {
+	accept, err := s.getRouteAccept(theirDID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return forward.Msg, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := envelopeFormat(forward.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range accept.Accept {
+		if f == format {
+			return forward.Msg, nil
+		}
+	}
+
+	if s.packager == nil {
+		return nil, fmt.Errorf("envelope format %s is not accepted by %s (accepts %v)", format, theirDID, accept.Accept)
+	}
+
+	req, err := json.Marshal(&model.Forward{
+		Type: service.ForwardMsgType,
+		ID:   uuid.New().String(),
+		To:   forward.To,
+		Msg:  forward.Msg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal bridged forward : %w", err)
+	}
+
+	_, senderKey, err := s.kms.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	if err != nil {
+		return nil, fmt.Errorf("create sender key : %w", err)
+	}
+
+	packed, err := s.packager.PackMessage(&commontransport.Envelope{
+		Message:      req,
+		FromKey:      senderKey,
+		ToKeys:       dest.RecipientKeys,
+		EncodingType: accept.Accept[0],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pack bridged forward in format %s : %w", accept.Accept[0], err)
+	}
+
+	return packedEnvelope(packed)
+}
+
+// packedEnvelope returns the envelope of a packed message, converting the JWE compact serialization of single
+// recipient envelopes to the flattened JSON one.
+func packedEnvelope(packed []byte) (*model.Envelope, error) {
+	const compactParts = 5
+
+	if !strings.HasPrefix(string(packed), "{") {
+		parts := strings.Split(string(packed), ".")
+		if len(parts) != compactParts {
+			return nil, errors.New("invalid compact envelope")
+		}
+
+		return &model.Envelope{
+			Protected:    parts[0],
+			EncryptedKey: parts[1],
+			IV:           parts[2],
+			CipherText:   parts[3],
+			Tag:          parts[4],
+		}, nil
+	}
+
+	env := &model.Envelope{}
+
+	err := json.Unmarshal(packed, env)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal bridged envelope : %w", err)
+	}
+
+	return env, nil
+}
+
+// unpackBridgedForward unpacks the envelope of a forward message bridged by the router of this agent (see
+// bridgeEnvelope) with the packer of its format. It fails if the envelope isn't packed for this agent.
+func (s *Service) unpackBridgedForward(forward *model.Forward) (*commontransport.Envelope, error) {
+	if s.packager == nil || s.msgHandler == nil || forward.Msg == nil {
+		return nil, errors.New("bridged envelopes are not supported")
+	}
+
+	msg, err := json.Marshal(forward.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal bridged envelope : %w", err)
+	}
+
+	unpacked, err := s.packager.UnpackMessage(msg)
+	if err != nil {
+		return nil, fmt.Errorf("unpack bridged envelope : %w", err)
+	}
+
+	if base58.Encode(unpacked.ToKey) != forward.To {
+		return nil, fmt.Errorf("bridged envelope is not packed for %s", forward.To)
+	}
+
+	return unpacked, nil
+}
+
+func (s *Service) getRouteAccept(theirDID string) (*routeAccept, error) {
+	src, err := s.routeStore.Get(fmt.Sprintf(routeAcceptDataKey, theirDID))
+	if err != nil {
+		return nil, fmt.Errorf("get route accept : %w", err)
+	}
+
+	accept := &routeAccept{}
+
+	err = json.Unmarshal(src, accept)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal route accept : %w", err)
+	}
+
+	return accept, nil
+}
+
+func (s *Service) saveRouteAccept(theirDID string, accept *routeAccept) error {
+	src, err := json.Marshal(accept)
+	if err != nil {
+		return fmt.Errorf("marshal route accept : %w", err)
+	}
+
+	err = s.routeStore.Put(fmt.Sprintf(routeAcceptDataKey, theirDID), src)
+	if err != nil {
+		return fmt.Errorf("save route accept : %w", err)
+	}
+
+	return nil
+}
+
+// envelopeFormat returns the format of the envelope from its protected header.
+func envelopeFormat(env *model.Envelope) (string, error) {
+	if env == nil {
+		return "", errors.New("missing envelope")
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(env.Protected, "="))
+	if err != nil {
+		return "", fmt.Errorf("decode envelope header : %w", err)
+	}
+
+	header := &struct {
+		Typ string `json:"typ,omitempty"`
+	}{}
+
+	err = json.Unmarshal(protected, header)
+	if err != nil {
+		return "", fmt.Errorf("parse envelope header : %w", err)
+	}
+
+	return header.Typ, nil
+}
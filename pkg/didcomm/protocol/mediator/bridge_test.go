@@ -0,0 +1,320 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mediator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/anoncrypt"
+	legacy "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/legacy/authcrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/messagepickup"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockdispatcher "github.com/hyperledger/aries-framework-go/pkg/mock/didcomm/dispatcher"
+	mockpackager "github.com/hyperledger/aries-framework-go/pkg/mock/didcomm/packager"
+	mockmessagep "github.com/hyperledger/aries-framework-go/pkg/mock/didcomm/protocol/messagepickup"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	mockvdr "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+const recipientKey = "recipientKey"
+
+// testEnvelope returns an envelope of the given format, with an opaque ciphertext.
+func testEnvelope(t *testing.T, format string) *model.Envelope {
+	t.Helper()
+
+	header, err := json.Marshal(&struct {
+		Typ string `json:"typ"`
+	}{Typ: format})
+	require.NoError(t, err)
+
+	return &model.Envelope{
+		Protected:  base64.RawURLEncoding.EncodeToString(header),
+		Recipients: json.RawMessage(`[{"encrypted_key":"a2V5"}]`),
+		IV:         "aXY",
+		CipherText: "Y2lwaGVydGV4dA",
+		Tag:        "dGFn",
+	}
+}
+
+func newBridgeService(t *testing.T, outbound *mockdispatcher.MockOutbound) *Service {
+	t.Helper()
+
+	return newBridgeServiceForKeys(t, outbound, nil, recipientKey, base58.Encode([]byte(recipientKey)))
+}
+
+// newBridgeServiceForKeys returns a router routing the forward messages to routeKey to theirDID, whose DIDComm
+// service has the recipientKey.
+func newBridgeServiceForKeys(t *testing.T, outbound *mockdispatcher.MockOutbound,
+	packager commontransport.Packager, routeKey, recipientKey string) *Service {
+	t.Helper()
+
+	doc := &did.Doc{ID: THEIRDID, Service: []did.Service{{Type: "did-communication", ServiceEndpoint: ENDPOINT,
+		RecipientKeys: []string{recipientKey}}}}
+
+	svc, err := New(&mockprovider.Provider{
+		ServiceMap: map[string]interface{}{
+			messagepickup.MessagePickup: &mockmessagep.MockMessagePickupSvc{},
+		},
+		StorageProviderValue:              mockstore.NewMockStoreProvider(),
+		ProtocolStateStorageProviderValue: mockstore.NewMockStoreProvider(),
+		KMSValue:                          &mockkms.KeyManager{},
+		OutboundDispatcherValue:           outbound,
+		PackagerValue:                     packager,
+		VDRegistryValue: &mockvdr.MockVDRegistry{
+			ResolveFunc: func(didID string, opts ...vdr.ResolveOpts) (*did.Doc, error) {
+				if didID != THEIRDID {
+					return nil, errors.New("not found")
+				}
+
+				return doc, nil
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.routeStore.Put(dataKey(routeKey), []byte(THEIRDID)))
+
+	return svc
+}
+
+// newBridgePackager returns a KMS and a packager packing DIDComm v1 (legacy) envelopes, which also packs and
+// unpacks DIDComm v2 (anoncrypt) ones.
+func newBridgePackager(t *testing.T) (kms.KeyManager, commontransport.Packager) {
+	t.Helper()
+
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(mockstore.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	prov := &mockprovider.Provider{
+		KMSValue:             km,
+		CryptoValue:          cr,
+		StorageProviderValue: mockstore.NewMockStoreProvider(),
+		VDRegistryValue:      &mockvdr.MockVDRegistry{},
+	}
+
+	anonPacker, err := anoncrypt.New(prov, jose.A256GCM)
+	require.NoError(t, err)
+
+	prov.PackerValue = legacy.New(prov)
+	prov.PackerList = []packer.Packer{anonPacker}
+
+	p, err := packager.New(prov)
+	require.NoError(t, err)
+
+	return km, p
+}
+
+func TestServiceBridgeForwardMsg(t *testing.T) {
+	t.Run("test request saves accepted envelope formats", func(t *testing.T) {
+		svc := newBridgeService(t, &mockdispatcher.MockOutbound{})
+
+		err := svc.handleInboundRequest(&callback{
+			msg: service.NewDIDCommMsgMap(&Request{
+				ID: randomID(), Type: RequestMsgType, Accept: []string{EnvelopeV2},
+			}),
+			myDID:    MYDID,
+			theirDID: THEIRDID,
+			options:  &Options{},
+		})
+		require.NoError(t, err)
+
+		accept, err := svc.getRouteAccept(THEIRDID)
+		require.NoError(t, err)
+		require.Equal(t, &routeAccept{Accept: []string{EnvelopeV2}}, accept)
+	})
+
+	t.Run("test envelopes forwarded unchanged", func(t *testing.T) {
+		env := testEnvelope(t, EnvelopeV2)
+		forwarded := 0
+
+		svc := newBridgeService(t, &mockdispatcher.MockOutbound{
+			ValidateForward: func(msg interface{}, _ *service.Destination) error {
+				require.Equal(t, env, msg)
+
+				forwarded++
+
+				return nil
+			},
+		})
+
+		// no accepted formats
+		require.NoError(t, svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey, env)))
+
+		// accepted format
+		require.NoError(t, svc.saveRouteAccept(THEIRDID, &routeAccept{Accept: []string{EnvelopeV1, EnvelopeV2}}))
+		require.NoError(t, svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey, env)))
+
+		require.Equal(t, 2, forwarded)
+	})
+
+	t.Run("test envelope in a format not accepted is bridged", func(t *testing.T) {
+		km, p := newBridgePackager(t)
+
+		// the DIDComm v2 key of the recipient, to which the router packs
+		_, v2Key, err := km.CreateAndExportPubKeyBytes(kms.ECDH256KWAES256GCMType)
+		require.NoError(t, err)
+
+		// the DIDComm v1 keys of the legacy sender and of the recipient
+		_, senderKey, err := km.CreateAndExportPubKeyBytes(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, v1Key, err := km.CreateAndExportPubKeyBytes(kms.ED25519Type)
+		require.NoError(t, err)
+
+		payload := []byte(`{"@id":"message-1","@type":"https://didcomm.org/basicmessage/1.0/message"}`)
+
+		packed, err := p.PackMessage(&commontransport.Envelope{
+			Message: payload, FromKey: senderKey, ToKeys: []string{base58.Encode(v1Key)},
+		})
+		require.NoError(t, err)
+
+		env := &model.Envelope{}
+		require.NoError(t, json.Unmarshal(packed, env))
+
+		var bridged *model.Envelope
+
+		router := newBridgeServiceForKeys(t, &mockdispatcher.MockOutbound{
+			ValidateForward: func(msg interface{}, _ *service.Destination) error {
+				var ok bool
+
+				bridged, ok = msg.(*model.Envelope)
+				require.True(t, ok)
+
+				return nil
+			},
+		}, p, base58.Encode(v1Key), base58.Encode(v2Key))
+
+		require.NoError(t, router.saveRouteAccept(THEIRDID, &routeAccept{Accept: []string{EnvelopeV2}}))
+		require.NoError(t, router.handleForward(generateForwardMsgPayload(t, randomID(), base58.Encode(v1Key), env)))
+
+		format, err := envelopeFormat(bridged)
+		require.NoError(t, err)
+		require.Equal(t, EnvelopeV2, format)
+
+		// the recipient unpacks the outer layer with its packer, then the inner envelope of the forward message
+		bridgedBytes, err := json.Marshal(bridged)
+		require.NoError(t, err)
+
+		unpacked, err := p.UnpackMessage(bridgedBytes)
+		require.NoError(t, err)
+
+		forwardMsg, err := service.ParseDIDCommMsgMap(unpacked.Message)
+		require.NoError(t, err)
+		require.Equal(t, service.ForwardMsgType, forwardMsg.Type())
+
+		var received []byte
+
+		recipient, err := New(&mockprovider.Provider{
+			ServiceMap: map[string]interface{}{
+				messagepickup.MessagePickup: &mockmessagep.MockMessagePickupSvc{},
+			},
+			StorageProviderValue:              mockstore.NewMockStoreProvider(),
+			ProtocolStateStorageProviderValue: mockstore.NewMockStoreProvider(),
+			PackagerValue:                     p,
+			InboundMessageHandlerValue: func(message []byte, _, _ string) error {
+				received = message
+
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, recipient.handleForward(forwardMsg))
+		require.Equal(t, payload, received)
+
+		// a forward message which isn't packed for the recipient key isn't handled
+		err = recipient.handleForward(generateForwardMsgPayload(t, randomID(), base58.Encode(senderKey), env))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "route key fetch")
+	})
+
+	t.Run("test envelope in a format not accepted is refused without packager", func(t *testing.T) {
+		svc := newBridgeService(t, &mockdispatcher.MockOutbound{
+			ValidateForward: func(msg interface{}, _ *service.Destination) error {
+				require.FailNow(t, "the envelope must not be forwarded")
+
+				return nil
+			},
+		})
+
+		require.NoError(t, svc.saveRouteAccept(THEIRDID, &routeAccept{Accept: []string{EnvelopeV2}}))
+
+		err := svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey, testEnvelope(t, EnvelopeV1)))
+		require.EqualError(t, err, "bridge envelope : envelope format JWM/1.0 is not accepted by theirDID "+
+			"(accepts [didcomm-envelope-enc])")
+	})
+
+	t.Run("test bridging errors", func(t *testing.T) {
+		svc := newBridgeServiceForKeys(t, &mockdispatcher.MockOutbound{}, &mockpackager.Packager{
+			PackErr: errors.New("pack error"),
+		}, recipientKey, base58.Encode([]byte(recipientKey)))
+
+		require.NoError(t, svc.saveRouteAccept(THEIRDID, &routeAccept{Accept: []string{EnvelopeV2}}))
+
+		err := svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey, testEnvelope(t, EnvelopeV1)))
+		require.EqualError(t, err, "bridge envelope : pack bridged forward in format didcomm-envelope-enc : "+
+			"pack error")
+
+		svc.packager = &mockpackager.Packager{PackValue: []byte("{")}
+
+		err = svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey, testEnvelope(t, EnvelopeV1)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal bridged envelope")
+
+		svc.packager = &mockpackager.Packager{PackValue: []byte("protected.iv")}
+
+		err = svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey, testEnvelope(t, EnvelopeV1)))
+		require.EqualError(t, err, "bridge envelope : invalid compact envelope")
+	})
+
+	t.Run("test envelope format errors", func(t *testing.T) {
+		svc := newBridgeService(t, &mockdispatcher.MockOutbound{})
+		require.NoError(t, svc.saveRouteAccept(THEIRDID, &routeAccept{Accept: []string{EnvelopeV2}}))
+
+		err := svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey,
+			&model.Envelope{Protected: "!"}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode envelope header")
+
+		err = svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey,
+			&model.Envelope{Protected: base64.RawURLEncoding.EncodeToString([]byte("{"))}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse envelope header")
+
+		_, err = envelopeFormat(nil)
+		require.EqualError(t, err, "missing envelope")
+
+		require.NoError(t, svc.routeStore.Put("route_accept_"+THEIRDID, []byte("{")))
+
+		err = svc.handleForward(generateForwardMsgPayload(t, randomID(), recipientKey, testEnvelope(t, EnvelopeV1)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal route accept")
+	})
+}
@@ -16,6 +16,9 @@ type Request struct {
 	Type             string `json:"@type,omitempty"`
 	ID               string `json:"@id,omitempty"`
 	decorator.Timing `json:"~timing,omitempty"`
+	// Accept lists the envelope formats (EnvelopeV1, EnvelopeV2) accepted by the requester in order of
+	// preference; the router wraps envelopes of other formats in a forward message packed in the preferred format.
+	Accept []string `json:"accept,omitempty"`
 }
 
 // Grant route grant message.
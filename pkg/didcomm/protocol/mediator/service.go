@@ -20,9 +20,11 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/model"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/messagepickup"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/logutil"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
@@ -98,6 +100,8 @@ type provider interface {
 	KMS() kms.KeyManager
 	VDRegistry() vdr.Registry
 	Service(id string) (interface{}, error)
+	Packager() commontransport.Packager
+	InboundMessageHandler() transport.InboundMessageHandler
 }
 
 // ClientOption configures the route client.
@@ -106,6 +110,7 @@ type ClientOption func(opts *ClientOptions)
 // ClientOptions holds options for the router client.
 type ClientOptions struct {
 	Timeout time.Duration
+	Accept  []string
 }
 
 // Options is a container for route protocol options.
@@ -142,6 +147,8 @@ type Service struct {
 	keylistUpdateMapLock sync.RWMutex
 	callbacks            chan *callback
 	messagePickupSvc     messagepickup.ProtocolService
+	packager             commontransport.Packager
+	msgHandler           transport.InboundMessageHandler
 }
 
 // New return route coordination service.
@@ -176,6 +183,8 @@ func New(prov provider) (*Service, error) {
 		keylistUpdateMap: make(map[string]chan *KeylistUpdateResponse),
 		callbacks:        make(chan *callback),
 		messagePickupSvc: messagePickupSvc,
+		packager:         prov.Packager(),
+		msgHandler:       prov.InboundMessageHandler(),
 	}
 
 	go s.listenForCallbacks()
@@ -363,6 +372,13 @@ func (s *Service) handleInboundRequest(c *callback) error {
 		return fmt.Errorf("handleInboundRequest: failed to handle inbound request : %w", err)
 	}
 
+	if len(request.Accept) > 0 {
+		err = s.saveRouteAccept(c.theirDID, &routeAccept{Accept: request.Accept})
+		if err != nil {
+			return fmt.Errorf("handleInboundRequest: %w", err)
+		}
+	}
+
 	return s.outbound.SendToDID(grant, c.myDID, c.theirDID)
 }
 
@@ -476,6 +492,13 @@ func (s *Service) handleForward(msg service.DIDCommMsg) error {
 	// TODO Open question - https://github.com/hyperledger/aries-framework-go/issues/965 Mismatch between Route
 	//  Coordination and Forward RFC. For now assume, the TO field contains the recipient key.
 	theirDID, err := s.routeStore.Get(dataKey(forward.To))
+	if errors.Is(err, storage.ErrDataNotFound) {
+		// not routed by this agent, the forward might be the bridged envelope of a message sent to this agent
+		if unpacked, bridgeErr := s.unpackBridgedForward(forward); bridgeErr == nil {
+			return s.msgHandler(unpacked.Message, unpacked.ToDID, unpacked.FromDID)
+		}
+	}
+
 	if err != nil {
 		return fmt.Errorf("route key fetch : %w", err)
 	}
@@ -485,9 +508,14 @@ func (s *Service) handleForward(msg service.DIDCommMsg) error {
 		return fmt.Errorf("get destination : %w", err)
 	}
 
-	err = s.outbound.Forward(forward.Msg, dest)
+	env, err := s.bridgeEnvelope(forward, dest, string(theirDID))
+	if err != nil {
+		return fmt.Errorf("bridge envelope : %w", err)
+	}
+
+	err = s.outbound.Forward(env, dest)
 	if err != nil && s.messagePickupSvc != nil {
-		return s.messagePickupSvc.AddMessage(forward.Msg, string(theirDID))
+		return s.messagePickupSvc.AddMessage(env, string(theirDID))
 	}
 
 	return err
@@ -511,6 +539,7 @@ func (s *Service) Register(connectionID string, options ...ClientOption) error {
 			Type:   RequestMsgType,
 			ID:     uuid.New().String(),
 			Timing: decorator.Timing{},
+			Accept: opts.Accept,
 		},
 		opts.Timeout,
 	)
@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package issuecredential
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/attestation"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+)
+
+const (
+	stateNameRequestReceived = "request-received"
+	walletAttestationKey     = "walletAttestation"
+)
+
+// VerifyWalletAttestation the helper function for the issue credential protocol which verifies the wallet
+// attestation attached to received requests (see issuecredential.RequestCredential.AttachWalletAttestation)
+// with the keys of the trusted wallet providers. The proof of possession must be addressed to the audience of
+// the issuer and contain the thread ID as nonce. As the thread ID is chosen by the wallet, the proof of possession
+// must also be fresh and is accepted once: its jti is recorded by an in-memory replay cache, unless another cache
+// is set with attestation.WithReplayCache (e.g. shared by the issuer instances). Requests without a valid wallet
+// attestation are rejected, and the claims of the attestation are set to the "walletAttestation" property.
+func VerifyWalletAttestation(providers jwt.KeyResolver, audience string,
+	opts ...attestation.VerifyOpt) issuecredential.Middleware {
+	opts = append([]attestation.VerifyOpt{attestation.WithReplayCache(attestation.NewMemReplayCache())}, opts...)
+
+	return func(next issuecredential.Handler) issuecredential.Handler {
+		return issuecredential.HandlerFunc(func(metadata issuecredential.Metadata) error {
+			if metadata.StateName() != stateNameRequestReceived {
+				return next.Handle(metadata)
+			}
+
+			request := issuecredential.RequestCredential{}
+
+			err := metadata.Message().Decode(&request)
+			if err != nil {
+				return fmt.Errorf("decode: %w", err)
+			}
+
+			thID, err := metadata.Message().ThreadID()
+			if err != nil {
+				return fmt.Errorf("threadID: %w", err)
+			}
+
+			a, err := request.WalletAttestation()
+			if err != nil {
+				return err
+			}
+
+			claims, err := a.Verify(providers, audience, append(opts, attestation.WithNonce(thID))...)
+			if err != nil {
+				return err
+			}
+
+			metadata.Properties()[walletAttestationKey] = claims
+
+			return next.Handle(metadata)
+		})
+	}
+}
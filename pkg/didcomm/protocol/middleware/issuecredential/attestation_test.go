@@ -0,0 +1,183 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package issuecredential
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/attestation"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	mocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/protocol/middleware/issuecredential"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+func TestVerifyWalletAttestation(t *testing.T) {
+	const (
+		walletProvider = "https://wallet-provider.example.com"
+		clientID       = "did:example:wallet-instance"
+		issuerDID      = "did:example:issuer"
+		thID           = "b3e4b5f2-7b1c-4e4a-9c3d-2f6a1d9e8c7b"
+	)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(storage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	crypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	newSigner := func() (*attestation.Signer, []byte) {
+		keyID, pubKey, e := km.CreateAndExportPubKeyBytes(kmsapi.ED25519Type)
+		require.NoError(t, e)
+
+		signer, e := attestation.NewSigner(km, crypto, keyID, kmsapi.ED25519Type)
+		require.NoError(t, e)
+
+		return signer, pubKey
+	}
+
+	providerSigner, providerKey := newSigner()
+	instanceSigner, _ := newSigner()
+
+	instanceKey, err := instanceSigner.PublicJWK()
+	require.NoError(t, err)
+
+	walletAttestation, err := providerSigner.Attest(walletProvider, clientID, instanceKey, time.Hour)
+	require.NoError(t, err)
+
+	providers := jwt.KeyResolverFunc(func(issuer, _ string) (*verifier.PublicKey, error) {
+		if issuer != walletProvider {
+			return nil, errors.New("untrusted wallet provider")
+		}
+
+		return &verifier.PublicKey{Value: providerKey}, nil
+	})
+
+	next := issuecredential.HandlerFunc(func(metadata issuecredential.Metadata) error {
+		return nil
+	})
+
+	requestMsg := func(nonce string) service.DIDCommMsgMap {
+		a, e := instanceSigner.ProvePossession(walletAttestation, clientID, issuerDID, nonce, time.Minute)
+		require.NoError(t, e)
+
+		request := &issuecredential.RequestCredential{Type: issuecredential.RequestCredentialMsgType}
+		request.AttachWalletAttestation(a)
+
+		msg := service.NewDIDCommMsgMap(request)
+		msg["@id"] = thID
+
+		return msg
+	}
+
+	t.Run("Ignores processing", func(t *testing.T) {
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return("state-name")
+		require.NoError(t, VerifyWalletAttestation(providers, issuerDID)(next).Handle(metadata))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		properties := map[string]interface{}{}
+
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameRequestReceived)
+		metadata.EXPECT().Message().Return(requestMsg(thID)).AnyTimes()
+		metadata.EXPECT().Properties().Return(properties)
+
+		require.NoError(t, VerifyWalletAttestation(providers, issuerDID)(next).Handle(metadata))
+
+		claims, ok := properties[walletAttestationKey].(*attestation.Claims)
+		require.True(t, ok)
+		require.Equal(t, clientID, claims.Subject)
+	})
+
+	t.Run("Replayed proof of possession", func(t *testing.T) {
+		msg := requestMsg(thID)
+		handler := VerifyWalletAttestation(providers, issuerDID)(next)
+
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameRequestReceived).Times(2)
+		metadata.EXPECT().Message().Return(msg).AnyTimes()
+		metadata.EXPECT().Properties().Return(map[string]interface{}{})
+
+		require.NoError(t, handler.Handle(metadata))
+
+		err := handler.Handle(metadata)
+		require.EqualError(t, err, "wallet attestation proof of possession: replayed")
+
+		// the replay cache is shared by the issuer instances
+		cache := attestation.NewMemReplayCache()
+		msg = requestMsg(thID)
+
+		metadata = mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameRequestReceived).Times(2)
+		metadata.EXPECT().Message().Return(msg).AnyTimes()
+		metadata.EXPECT().Properties().Return(map[string]interface{}{})
+
+		require.NoError(t, VerifyWalletAttestation(providers, issuerDID,
+			attestation.WithReplayCache(cache))(next).Handle(metadata))
+
+		err = VerifyWalletAttestation(providers, issuerDID, attestation.WithReplayCache(cache))(next).Handle(metadata)
+		require.EqualError(t, err, "wallet attestation proof of possession: replayed")
+	})
+
+	t.Run("Proof of possession of another exchange", func(t *testing.T) {
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameRequestReceived)
+		metadata.EXPECT().Message().Return(requestMsg("other thread")).AnyTimes()
+
+		err := VerifyWalletAttestation(providers, issuerDID)(next).Handle(metadata)
+		require.EqualError(t, err, "wallet attestation proof of possession: nonce mismatch")
+	})
+
+	t.Run("Proof of possession for another issuer", func(t *testing.T) {
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameRequestReceived)
+		metadata.EXPECT().Message().Return(requestMsg(thID)).AnyTimes()
+
+		err := VerifyWalletAttestation(providers, "did:example:other")(next).Handle(metadata)
+		require.EqualError(t, err, "wallet attestation proof of possession: audience is not did:example:other")
+	})
+
+	t.Run("No wallet attestation", func(t *testing.T) {
+		msg := service.NewDIDCommMsgMap(issuecredential.RequestCredential{Type: issuecredential.RequestCredentialMsgType})
+		msg["@id"] = thID
+
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameRequestReceived)
+		metadata.EXPECT().Message().Return(msg).AnyTimes()
+
+		err := VerifyWalletAttestation(providers, issuerDID)(next).Handle(metadata)
+		require.True(t, errors.Is(err, issuecredential.ErrNoWalletAttestation))
+	})
+
+	t.Run("Decode error", func(t *testing.T) {
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameRequestReceived)
+		metadata.EXPECT().Message().Return(service.DIDCommMsgMap{"@type": map[int]int{}})
+
+		err := VerifyWalletAttestation(providers, issuerDID)(next).Handle(metadata)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decode")
+	})
+}
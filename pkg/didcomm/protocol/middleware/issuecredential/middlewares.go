@@ -35,11 +35,40 @@ type Provider interface {
 	VDRegistry() vdrapi.Registry
 }
 
+// Option configures the SaveCredentials middleware.
+type Option func(opts *options)
+
+type options struct {
+	credentialOpts []verifiable.CredentialOpt
+}
+
+// WithContextAllowList rejects received credentials referencing JSON-LD contexts which don't match
+// the given URL patterns, before any of them is fetched (see verifiable.WithContextAllowList).
+func WithContextAllowList(patterns ...string) Option {
+	return func(opts *options) {
+		opts.credentialOpts = append(opts.credentialOpts, verifiable.WithContextAllowList(patterns...))
+	}
+}
+
+// WithPostVerificationHooks rejects received credentials which don't pass the post-verification hooks
+// (see verifiable.WithPostVerificationHooks), e.g. risk scoring or device binding checks.
+func WithPostVerificationHooks(hooks ...verifiable.PostVerificationHook) Option {
+	return func(opts *options) {
+		opts.credentialOpts = append(opts.credentialOpts, verifiable.WithPostVerificationHooks(hooks...))
+	}
+}
+
 // SaveCredentials the helper function for the issue credential protocol which saves credentials.
-func SaveCredentials(p Provider) issuecredential.Middleware {
+func SaveCredentials(p Provider, opts ...Option) issuecredential.Middleware {
 	vdr := p.VDRegistry()
 	store := p.VerifiableStore()
 
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	return func(next issuecredential.Handler) issuecredential.Handler {
 		return issuecredential.HandlerFunc(func(metadata issuecredential.Metadata) error {
 			if metadata.StateName() != stateNameCredentialReceived {
@@ -53,7 +82,7 @@ func SaveCredentials(p Provider) issuecredential.Middleware {
 				return fmt.Errorf("decode: %w", err)
 			}
 
-			credentials, err := toVerifiableCredentials(vdr, credential.CredentialsAttach)
+			credentials, err := toVerifiableCredentials(vdr, credential.CredentialsAttach, o.credentialOpts...)
 			if err != nil {
 				return fmt.Errorf("to verifiable credentials: %w", err)
 			}
@@ -105,7 +134,8 @@ func getName(idx int, id string, metadata issuecredential.Metadata) string {
 	return uuid.New().String()
 }
 
-func toVerifiableCredentials(v vdrapi.Registry, attachments []decorator.Attachment) ([]*verifiable.Credential, error) {
+func toVerifiableCredentials(v vdrapi.Registry, attachments []decorator.Attachment,
+	opts ...verifiable.CredentialOpt) ([]*verifiable.Credential, error) {
 	var credentials []*verifiable.Credential
 
 	for i := range attachments {
@@ -114,9 +144,9 @@ func toVerifiableCredentials(v vdrapi.Registry, attachments []decorator.Attachme
 			return nil, fmt.Errorf("fetch: %w", err)
 		}
 
-		vc, err := verifiable.ParseCredential(rawVC, verifiable.WithPublicKeyFetcher(
+		vc, err := verifiable.ParseCredential(rawVC, append([]verifiable.CredentialOpt{verifiable.WithPublicKeyFetcher(
 			verifiable.NewDIDKeyResolver(v).PublicKeyFetcher(),
-		))
+		)}, opts...)...)
 		if err != nil {
 			return nil, fmt.Errorf("new credential: %w", err)
 		}
@@ -121,6 +121,39 @@ func TestSaveCredentials(t *testing.T) {
 		require.Contains(t, fmt.Sprintf("%v", err), "to verifiable credentials")
 	})
 
+	t.Run("Context not allowed", func(t *testing.T) {
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameCredentialReceived)
+		metadata.EXPECT().Message().Return(service.NewDIDCommMsgMap(issuecredential.IssueCredential{
+			Type: issuecredential.IssueCredentialMsgType,
+			CredentialsAttach: []decorator.Attachment{
+				{Data: decorator.AttachmentData{JSON: getCredential()}},
+			},
+		}))
+
+		err := SaveCredentials(provider, WithContextAllowList("https://w3id.org/citizenship/*"))(next).Handle(metadata)
+		require.True(t, errors.Is(err, verifiable.ErrContextNotAllowed))
+	})
+
+	t.Run("Credential rejected by hooks", func(t *testing.T) {
+		metadata := mocks.NewMockMetadata(ctrl)
+		metadata.EXPECT().StateName().Return(stateNameCredentialReceived)
+		metadata.EXPECT().Message().Return(service.NewDIDCommMsgMap(issuecredential.IssueCredential{
+			Type: issuecredential.IssueCredentialMsgType,
+			CredentialsAttach: []decorator.Attachment{
+				{Data: decorator.AttachmentData{JSON: getCredential()}},
+			},
+		}))
+
+		hook := verifiable.PostVerificationHookFunc(
+			func(*verifiable.Credential, *verifiable.VerificationResult) (*verifiable.HookResult, error) {
+				return &verifiable.HookResult{Verdicts: []*verifiable.PolicyVerdict{{Policy: "velocity"}}}, nil
+			})
+
+		err := SaveCredentials(provider, WithPostVerificationHooks(hook))(next).Handle(metadata)
+		require.True(t, errors.Is(err, verifiable.ErrCredentialRejected))
+	})
+
 	t.Run("DB error", func(t *testing.T) {
 		const (
 			vcName = "vc-name"
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spi
+
+import (
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+)
+
+// Context of the message passed to handlers of custom protocols.
+type Context struct {
+	// MyDID is the DID of the agent.
+	MyDID string
+	// TheirDID is the DID of the other agent.
+	TheirDID string
+	// ThreadID is the ID of the protocol thread the message belongs to.
+	ThreadID string
+
+	msg      service.DIDCommMsg
+	svc      *Service
+	outbound bool
+	events   []service.StateMsg
+	replies  []service.DIDCommMsgMap
+}
+
+// Reply sends the message to the other agent on the thread of the handled message. Only received messages
+// can be replied to: outbound handlers get ErrOutboundReply. Like events, replies are sent once the handler
+// returns, so that the service isn't locked while sending; they aren't sent if the handler fails, and an error
+// sending them is returned by Service.HandleInbound.
+func (c *Context) Reply(msg service.DIDCommMsgMap) error {
+	if c.outbound {
+		return ErrOutboundReply
+	}
+
+	c.replies = append(c.replies, msg)
+
+	return nil
+}
+
+// SaveState saves the state of the thread.
+func (c *Context) SaveState(state interface{}) error {
+	return c.svc.SaveState(c.ThreadID, state)
+}
+
+// State reads the state of the thread into state, ErrStateNotFound is returned when the thread has no state.
+func (c *Context) State(state interface{}) error {
+	return c.svc.State(c.ThreadID, state)
+}
+
+// DeleteState deletes the state of the thread, e.g. when the protocol is done.
+func (c *Context) DeleteState() error {
+	return c.svc.DeleteState(c.ThreadID)
+}
+
+// Emit triggers the message events of the service with the state of the thread. Properties are passed
+// to the consumers along with the thread ID and the DIDs. The events are triggered once the handler returns,
+// so that slow consumers don't block the service.
+func (c *Context) Emit(stateID string, properties map[string]interface{}) {
+	c.events = append(c.events, service.StateMsg{
+		ProtocolName: c.svc.name,
+		Type:         service.PostState,
+		Msg:          c.msg.Clone(),
+		StateID:      stateID,
+		Properties:   newEventProps(c, properties),
+	})
+}
@@ -0,0 +1,59 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spi
+
+const (
+	threadIDPropKey = "threadID"
+	myDIDPropKey    = "myDID"
+	theirDIDPropKey = "theirDID"
+)
+
+type eventProps struct {
+	threadID   string
+	myDID      string
+	theirDID   string
+	properties map[string]interface{}
+}
+
+func newEventProps(ctx *Context, properties map[string]interface{}) *eventProps {
+	return &eventProps{
+		threadID:   ctx.ThreadID,
+		myDID:      ctx.MyDID,
+		theirDID:   ctx.TheirDID,
+		properties: properties,
+	}
+}
+
+// ThreadID returns the ID of the protocol thread.
+func (e *eventProps) ThreadID() string {
+	return e.threadID
+}
+
+// MyDID returns the DID of the agent.
+func (e *eventProps) MyDID() string {
+	return e.myDID
+}
+
+// TheirDID returns the DID of the other agent.
+func (e *eventProps) TheirDID() string {
+	return e.theirDID
+}
+
+// All implements EventProperties interface.
+func (e *eventProps) All() map[string]interface{} {
+	all := make(map[string]interface{}, len(e.properties)+3) // nolint:gomnd
+
+	for k, v := range e.properties {
+		all[k] = v
+	}
+
+	all[threadIDPropKey] = e.threadID
+	all[myDIDPropKey] = e.myDID
+	all[theirDIDPropKey] = e.theirDID
+
+	return all
+}
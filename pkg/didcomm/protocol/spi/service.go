@@ -0,0 +1,270 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package spi is the service provider interface for custom DIDComm protocols. It implements the plumbing of
+// a dispatcher.ProtocolService (message type routing, state persistence, event emission and outbound
+// sending), so that a custom protocol only provides handlers of its message types:
+//
+//	svc, err := spi.New(ctx, "data-agreement",
+//		spi.WithHandler("https://example.org/data-agreement/1.0/offer", handleOffer),
+//		spi.WithHandler("https://example.org/data-agreement/1.0/accept", handleAccept),
+//	)
+//
+// Custom protocols are registered with the framework using aries.WithProtocols(spi.NewCreator(...)).
+package spi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	storeNamePrefix = "spi_"
+	stateKeyPrefix  = "state_"
+)
+
+var (
+	// ErrStateNotFound is returned when there is no state of the thread.
+	ErrStateNotFound = errors.New("state not found")
+	// ErrOutboundReply is returned when an outbound handler replies to the message being sent.
+	ErrOutboundReply = errors.New("outbound messages can't be replied to")
+)
+
+// Provider contains dependencies for custom protocols and is typically created by using aries.Context().
+type Provider interface {
+	Messenger() service.Messenger
+	StorageProvider() storage.Provider
+}
+
+// Handler handles a message of a custom protocol. Inbound handlers are called with the received message
+// and outbound handlers with the message about to be sent by the agent, which is not sent if the handler
+// returns an error.
+type Handler func(ctx *Context, msg service.DIDCommMsg) error
+
+// Opt configures the custom protocol service.
+type Opt func(s *Service)
+
+// WithHandler routes inbound messages of the given type to the handler.
+func WithHandler(msgType string, handler Handler) Opt {
+	return func(s *Service) {
+		s.inbound[msgType] = handler
+	}
+}
+
+// WithOutboundHandler allows sending messages of the given type by the agent with HandleOutbound (for example
+// using the messaging client). The handler is called before the message is sent, e.g. to save the state of a
+// new thread.
+func WithOutboundHandler(msgType string, handler Handler) Opt {
+	return func(s *Service) {
+		s.outbound[msgType] = handler
+	}
+}
+
+// NewCreator returns the creator of the custom protocol service to be registered with the framework
+// using aries.WithProtocols().
+func NewCreator(name string, opts ...Opt) api.ProtocolSvcCreator {
+	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
+		return New(prv, name, opts...)
+	}
+}
+
+// Service implements dispatcher.ProtocolService for a custom protocol. Messages are routed to handlers
+// by their exact type and handlers of the service are never called concurrently.
+type Service struct {
+	service.Message
+	name      string
+	messenger service.Messenger
+	store     storage.Store
+	inbound   map[string]Handler
+	outbound  map[string]Handler
+	lock      sync.Mutex
+}
+
+// New returns the service of the custom protocol with the given name. The name is used as the name of
+// the service, the protocol name of events and the namespace of the state store (prefixed with "spi_", so that
+// it doesn't collide with the stores of the framework).
+func New(prov Provider, name string, opts ...Opt) (*Service, error) {
+	if name == "" {
+		return nil, errors.New("protocol name is required")
+	}
+
+	store, err := prov.StorageProvider().OpenStore(storeNamePrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("open %s store : %w", name, err)
+	}
+
+	svc := &Service{
+		name:      name,
+		messenger: prov.Messenger(),
+		store:     store,
+		inbound:   make(map[string]Handler),
+		outbound:  make(map[string]Handler),
+	}
+
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	return svc, nil
+}
+
+// HandleInbound handles inbound messages of the protocol. Handlers are called one at a time, the events they
+// emit and the replies they send are triggered once they return.
+func (s *Service) HandleInbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) {
+	handler, ok := s.inbound[msg.Type()]
+	if !ok {
+		return "", fmt.Errorf("%s: unsupported message type %s", s.name, msg.Type())
+	}
+
+	ctx, err := s.newContext(msg, myDID, theirDID)
+	if err != nil {
+		return "", err
+	}
+
+	s.lock.Lock()
+	err = handler(ctx, msg)
+	s.lock.Unlock()
+
+	s.emit(ctx.events)
+
+	if err != nil {
+		return "", fmt.Errorf("%s: handle %s: %w", s.name, msg.Type(), err)
+	}
+
+	for _, reply := range ctx.replies {
+		if err = s.messenger.ReplyToMsg(msg.Clone(), reply, myDID, theirDID); err != nil {
+			return "", fmt.Errorf("%s: reply to %s: %w", s.name, msg.Type(), err)
+		}
+	}
+
+	return msg.ID(), nil
+}
+
+// HandleOutbound sends a message of the protocol starting a new thread, the ID of which is the message ID.
+// Replies on existing threads are sent by handlers with Context.Reply.
+func (s *Service) HandleOutbound(msg service.DIDCommMsg, myDID, theirDID string) (string, error) {
+	handler, ok := s.outbound[msg.Type()]
+	if !ok {
+		return "", fmt.Errorf("%s: unsupported outbound message type %s", s.name, msg.Type())
+	}
+
+	if msg.ID() == "" {
+		if err := msg.SetID(uuid.New().String()); err != nil {
+			return "", fmt.Errorf("%s: set message ID: %w", s.name, err)
+		}
+	}
+
+	ctx := &Context{MyDID: myDID, TheirDID: theirDID, ThreadID: msg.ID(), msg: msg, svc: s, outbound: true}
+
+	s.lock.Lock()
+	err := handler(ctx, msg)
+	s.lock.Unlock()
+
+	s.emit(ctx.events)
+
+	if err != nil {
+		return "", fmt.Errorf("%s: handle outbound %s: %w", s.name, msg.Type(), err)
+	}
+
+	if err = s.messenger.Send(msg.Clone(), myDID, theirDID); err != nil {
+		return "", fmt.Errorf("%s: send %s: %w", s.name, msg.Type(), err)
+	}
+
+	return msg.ID(), nil
+}
+
+// Accept checks whether the service handles inbound messages of the type.
+func (s *Service) Accept(msgType string) bool {
+	_, ok := s.inbound[msgType]
+
+	return ok
+}
+
+// Name returns the name of the protocol.
+func (s *Service) Name() string {
+	return s.name
+}
+
+// SaveState saves the state of the protocol thread.
+func (s *Service) SaveState(threadID string, state interface{}) error {
+	src, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+
+	if err = s.store.Put(stateKeyPrefix+threadID, src); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+
+	return nil
+}
+
+// State reads the state of the protocol thread into state, ErrStateNotFound is returned when the thread
+// has no state.
+func (s *Service) State(threadID string, state interface{}) error {
+	src, err := s.store.Get(stateKeyPrefix + threadID)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return fmt.Errorf("thread %s: %w", threadID, ErrStateNotFound)
+	}
+
+	if err != nil {
+		return fmt.Errorf("get state: %w", err)
+	}
+
+	if err = json.Unmarshal(src, state); err != nil {
+		return fmt.Errorf("unmarshal state: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteState deletes the state of the protocol thread.
+func (s *Service) DeleteState(threadID string) error {
+	if err := s.store.Delete(stateKeyPrefix + threadID); err != nil {
+		return fmt.Errorf("delete state: %w", err)
+	}
+
+	return nil
+}
+
+// emit triggers the message events emitted by a handler, once the service is unlocked.
+func (s *Service) emit(events []service.StateMsg) {
+	if len(events) == 0 {
+		return
+	}
+
+	handlers := s.MsgEvents()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler <- event
+		}
+	}
+}
+
+func (s *Service) newContext(msg service.DIDCommMsg, myDID, theirDID string) (*Context, error) {
+	threadID, err := msg.ThreadID()
+	if err != nil {
+		return nil, fmt.Errorf("%s: threadID: %w", s.name, err)
+	}
+
+	return &Context{
+		MyDID:    myDID,
+		TheirDID: theirDID,
+		ThreadID: threadID,
+		msg:      msg,
+		svc:      s,
+	}, nil
+}
@@ -0,0 +1,305 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package spi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
+	serviceMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+const (
+	name     = "data-agreement"
+	spec     = "https://example.org/data-agreement/1.0/"
+	offer    = spec + "offer"
+	accept   = spec + "accept"
+	myDID    = "did:example:alice"
+	theirDID = "did:example:bob"
+)
+
+type provider struct {
+	messenger       service.Messenger
+	storageProvider storage.Provider
+}
+
+func (p *provider) Messenger() service.Messenger {
+	return p.messenger
+}
+
+func (p *provider) StorageProvider() storage.Provider {
+	return p.storageProvider
+}
+
+type agreement struct {
+	State string `json:"state"`
+	Terms string `json:"terms"`
+}
+
+func dataAgreement(t *testing.T, messenger service.Messenger) *Service {
+	t.Helper()
+
+	svc, err := New(&provider{messenger: messenger, storageProvider: mem.NewProvider()}, name,
+		WithOutboundHandler(offer, func(ctx *Context, msg service.DIDCommMsg) error {
+			terms, ok := msg.Clone()["terms"].(string)
+			if !ok {
+				return errors.New("terms are required")
+			}
+
+			return ctx.SaveState(&agreement{State: "offered", Terms: terms})
+		}),
+		WithHandler(offer, func(ctx *Context, msg service.DIDCommMsg) error {
+			if err := ctx.SaveState(&agreement{State: "offer-received"}); err != nil {
+				return err
+			}
+
+			return ctx.Reply(service.DIDCommMsgMap{"@type": accept})
+		}),
+		WithHandler(accept, func(ctx *Context, msg service.DIDCommMsg) error {
+			a := &agreement{}
+			if err := ctx.State(a); err != nil {
+				return err
+			}
+
+			ctx.Emit("accepted", map[string]interface{}{"terms": a.Terms})
+
+			return ctx.DeleteState()
+		}),
+	)
+	require.NoError(t, err)
+
+	return svc
+}
+
+func TestService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("test protocol flow", func(t *testing.T) {
+		messenger := serviceMocks.NewMockMessenger(ctrl)
+		svc := dataAgreement(t, messenger)
+
+		require.Equal(t, name, svc.Name())
+		require.True(t, svc.Accept(offer))
+		require.True(t, svc.Accept(accept))
+		require.False(t, svc.Accept(spec+"reject"))
+
+		// agent sends the offer
+		messenger.EXPECT().Send(gomock.Any(), myDID, theirDID).
+			Do(func(msg service.DIDCommMsgMap, _, _ string) {
+				require.Equal(t, offer, msg.Type())
+				require.NotEmpty(t, msg.ID())
+			})
+
+		threadID, err := svc.HandleOutbound(service.DIDCommMsgMap{"@type": offer, "terms": "1y"}, myDID, theirDID)
+		require.NoError(t, err)
+
+		a := &agreement{}
+		require.NoError(t, svc.State(threadID, a))
+		require.Equal(t, &agreement{State: "offered", Terms: "1y"}, a)
+
+		// other agent accepts
+		events := make(chan service.StateMsg, 1)
+		require.NoError(t, svc.RegisterMsgEvent(events))
+
+		msgID, err := svc.HandleInbound(service.DIDCommMsgMap{
+			"@id": "accept-1", "@type": accept, "~thread": map[string]interface{}{"thid": threadID},
+		}, myDID, theirDID)
+		require.NoError(t, err)
+		require.Equal(t, "accept-1", msgID)
+
+		event := <-events
+		require.Equal(t, name, event.ProtocolName)
+		require.Equal(t, service.PostState, event.Type)
+		require.Equal(t, "accepted", event.StateID)
+		require.Equal(t, accept, event.Msg.Type())
+		require.Equal(t, map[string]interface{}{
+			"terms": "1y", "threadID": threadID, "myDID": myDID, "theirDID": theirDID,
+		}, event.Properties.All())
+
+		props, ok := event.Properties.(*eventProps)
+		require.True(t, ok)
+		require.Equal(t, threadID, props.ThreadID())
+		require.Equal(t, myDID, props.MyDID())
+		require.Equal(t, theirDID, props.TheirDID())
+
+		err = svc.State(threadID, a)
+		require.True(t, errors.Is(err, ErrStateNotFound))
+	})
+
+	t.Run("test received offer is replied on its thread", func(t *testing.T) {
+		messenger := serviceMocks.NewMockMessenger(ctrl)
+		svc := dataAgreement(t, messenger)
+
+		in := service.DIDCommMsgMap{"@id": "offer-1", "@type": offer}
+
+		messenger.EXPECT().ReplyToMsg(in, service.DIDCommMsgMap{"@type": accept}, myDID, theirDID)
+
+		_, err := svc.HandleInbound(in, myDID, theirDID)
+		require.NoError(t, err)
+
+		a := &agreement{}
+		require.NoError(t, svc.State("offer-1", a))
+		require.Equal(t, "offer-received", a.State)
+	})
+
+	t.Run("test replies are sent after the handler", func(t *testing.T) {
+		messenger := serviceMocks.NewMockMessenger(ctrl)
+		svc := dataAgreement(t, messenger)
+
+		in := service.DIDCommMsgMap{"@id": "offer-1", "@type": offer}
+
+		// the messenger can call back into the service while the reply is sent
+		messenger.EXPECT().ReplyToMsg(in, service.DIDCommMsgMap{"@type": accept}, myDID, theirDID).
+			DoAndReturn(func(service.DIDCommMsgMap, service.DIDCommMsgMap, string, string) error {
+				_, err := svc.HandleInbound(service.DIDCommMsgMap{"@id": "offer-2", "@type": offer}, myDID, theirDID)
+
+				return err
+			})
+		messenger.EXPECT().ReplyToMsg(gomock.Any(), service.DIDCommMsgMap{"@type": accept}, myDID, theirDID).
+			Return(errors.New("reply error"))
+
+		_, err := svc.HandleInbound(in, myDID, theirDID)
+		require.EqualError(t, err, "data-agreement: reply to "+offer+": data-agreement: reply to "+offer+": reply error")
+	})
+
+	t.Run("test replies aren't sent if the handler fails", func(t *testing.T) {
+		messenger := serviceMocks.NewMockMessenger(ctrl)
+
+		svc, err := New(&provider{messenger: messenger, storageProvider: mem.NewProvider()}, name,
+			WithHandler(offer, func(ctx *Context, msg service.DIDCommMsg) error {
+				require.NoError(t, ctx.Reply(service.DIDCommMsgMap{"@type": accept}))
+
+				return errors.New("handler error")
+			}))
+		require.NoError(t, err)
+
+		_, err = svc.HandleInbound(service.DIDCommMsgMap{"@id": "1", "@type": offer}, myDID, theirDID)
+		require.EqualError(t, err, "data-agreement: handle "+offer+": handler error")
+	})
+
+	t.Run("test errors", func(t *testing.T) {
+		messenger := serviceMocks.NewMockMessenger(ctrl)
+		svc := dataAgreement(t, messenger)
+
+		_, err := svc.HandleInbound(service.DIDCommMsgMap{"@id": "1", "@type": spec + "reject"}, myDID, theirDID)
+		require.EqualError(t, err, "data-agreement: unsupported message type "+spec+"reject")
+
+		_, err = svc.HandleOutbound(service.DIDCommMsgMap{"@type": accept}, myDID, theirDID)
+		require.EqualError(t, err, "data-agreement: unsupported outbound message type "+accept)
+
+		_, err = svc.HandleInbound(service.DIDCommMsgMap{"@type": accept}, myDID, theirDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "data-agreement: threadID")
+
+		_, err = svc.HandleInbound(service.DIDCommMsgMap{"@id": "2", "@type": accept}, myDID, theirDID)
+		require.True(t, errors.Is(err, ErrStateNotFound))
+		require.Contains(t, err.Error(), "data-agreement: handle "+accept)
+
+		_, err = svc.HandleOutbound(service.DIDCommMsgMap{"@type": offer}, myDID, theirDID)
+		require.EqualError(t, err, "data-agreement: handle outbound "+offer+": terms are required")
+
+		messenger.EXPECT().Send(gomock.Any(), myDID, theirDID).Return(errors.New("send error"))
+
+		_, err = svc.HandleOutbound(service.DIDCommMsgMap{"@type": offer, "terms": "1y"}, myDID, theirDID)
+		require.EqualError(t, err, "data-agreement: send "+offer+": send error")
+
+		require.Error(t, svc.SaveState("1", make(chan int)))
+
+		require.NoError(t, svc.store.Put(stateKeyPrefix+"1", []byte("{")))
+		require.Contains(t, svc.State("1", &agreement{}).Error(), "unmarshal state")
+	})
+
+	t.Run("test outbound handler can't reply", func(t *testing.T) {
+		messenger := serviceMocks.NewMockMessenger(ctrl)
+
+		svc, err := New(&provider{messenger: messenger, storageProvider: mem.NewProvider()}, name,
+			WithOutboundHandler(offer, func(ctx *Context, msg service.DIDCommMsg) error {
+				return ctx.Reply(service.DIDCommMsgMap{"@type": accept})
+			}))
+		require.NoError(t, err)
+
+		_, err = svc.HandleOutbound(service.DIDCommMsgMap{"@type": offer}, myDID, theirDID)
+		require.True(t, errors.Is(err, ErrOutboundReply))
+		require.EqualError(t, err, "data-agreement: handle outbound "+offer+": outbound messages can't be replied to")
+	})
+
+	t.Run("test events are emitted after the handler", func(t *testing.T) {
+		svc, err := New(&provider{storageProvider: mem.NewProvider()}, name,
+			WithHandler(accept, func(ctx *Context, msg service.DIDCommMsg) error {
+				ctx.Emit("accepted", nil)
+				ctx.Emit("done", nil)
+
+				return nil
+			}),
+			WithHandler(offer, func(*Context, service.DIDCommMsg) error {
+				return nil
+			}))
+		require.NoError(t, err)
+
+		events := make(chan service.StateMsg)
+		require.NoError(t, svc.RegisterMsgEvent(events))
+
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			_, e := svc.HandleInbound(service.DIDCommMsgMap{"@id": "1", "@type": accept}, myDID, theirDID)
+			require.NoError(t, e)
+		}()
+
+		require.Equal(t, "accepted", (<-events).StateID)
+
+		// the service handles messages while the consumer is busy
+		_, err = svc.HandleInbound(service.DIDCommMsgMap{"@id": "2", "@type": offer}, myDID, theirDID)
+		require.NoError(t, err)
+
+		require.Equal(t, "done", (<-events).StateID)
+		<-done
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		_, err := New(&provider{storageProvider: mem.NewProvider()}, "")
+		require.EqualError(t, err, "protocol name is required")
+
+		_, err = New(&provider{storageProvider: &mockstore.MockStoreProvider{FailNamespace: "spi_" + name}}, name)
+		require.EqualError(t, err, "open data-agreement store : failed to open store for name space spi_"+name)
+
+		storeProvider := mockstore.NewMockStoreProvider()
+
+		svc, err := New(&provider{storageProvider: storeProvider}, name)
+		require.NoError(t, err)
+
+		storeProvider.Store.ErrPut = errors.New("put error")
+		require.EqualError(t, svc.SaveState("1", &agreement{}), "save state: put error")
+
+		storeProvider.Store.ErrGet = errors.New("get error")
+		require.EqualError(t, svc.State("1", &agreement{}), "get state: get error")
+
+		storeProvider.Store.ErrDelete = errors.New("delete error")
+		require.EqualError(t, svc.DeleteState("1"), "delete state: delete error")
+	})
+}
+
+func TestNewCreator(t *testing.T) {
+	ctx, err := context.New(context.WithStorageProvider(mem.NewProvider()))
+	require.NoError(t, err)
+
+	svc, err := NewCreator(name, WithHandler(offer, nil))(ctx)
+	require.NoError(t, err)
+	require.Equal(t, name, svc.Name())
+	require.True(t, svc.Accept(offer))
+}
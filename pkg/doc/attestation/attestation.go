@@ -0,0 +1,193 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package attestation creates and verifies wallet attestations: JWTs of a wallet provider attesting a key of a
+// wallet instance, presented together with a proof of possession of that key to authenticate the wallet to an
+// issuer, following OAuth 2.0 Attestation-Based Client Authentication
+// (https://datatracker.ietf.org/doc/draft-ietf-oauth-attestation-based-client-auth/).
+//
+// In DIDComm issuance, the attestation is attached to request-credential messages (see
+// issuecredential.RequestCredential.AttachWalletAttestation) and verified by the issuer with the
+// VerifyWalletAttestation middleware. For OAuth token requests, ClientAttestation.AddToValues and SetHeaders
+// (client side) and FromRequest and Verify (server side) only encode and check the attestation: the framework
+// has no OpenID for Verifiable Credential Issuance client or token endpoint to wire them into.
+package attestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	josejwt "github.com/square/go-jose/v3/jwt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+)
+
+const (
+	// AttestationType is the typ header of wallet attestations.
+	AttestationType = "oauth-client-attestation+jwt"
+	// PoPType is the typ header of proofs of possession of wallet attestations.
+	PoPType = "oauth-client-attestation-pop+jwt"
+	// ClientAssertionType is the client_assertion_type of token requests authenticated with a wallet attestation.
+	ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-client-attestation"
+	// HeaderAttestation is the HTTP header of the wallet attestation.
+	HeaderAttestation = "OAuth-Client-Attestation"
+	// HeaderPoP is the HTTP header of the proof of possession of the wallet attestation.
+	HeaderPoP = "OAuth-Client-Attestation-PoP"
+
+	paramClientAssertionType = "client_assertion_type"
+	paramClientAssertion     = "client_assertion"
+	assertionSeparator       = "~"
+)
+
+// Claims are the claims of a wallet attestation.
+type Claims struct {
+	*jwt.Claims
+
+	// Confirmation holds the attested key of the wallet instance.
+	Confirmation *Confirmation `json:"cnf,omitempty"`
+	WalletName   string        `json:"wallet_name,omitempty"`
+	WalletLink   string        `json:"wallet_link,omitempty"`
+}
+
+// Confirmation holds the attested key of the wallet instance.
+type Confirmation struct {
+	JWK *jose.JWK `json:"jwk"`
+}
+
+type popClaims struct {
+	*jwt.Claims
+
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// AttestOpt sets optional claims of a wallet attestation.
+type AttestOpt func(c *Claims)
+
+// WithWalletName sets the name of the wallet solution.
+func WithWalletName(name string) AttestOpt {
+	return func(c *Claims) {
+		c.WalletName = name
+	}
+}
+
+// WithWalletLink sets the URL with further information about the wallet solution.
+func WithWalletLink(link string) AttestOpt {
+	return func(c *Claims) {
+		c.WalletLink = link
+	}
+}
+
+// Attest creates a wallet attestation of the wallet instance key for the client ID of the wallet instance.
+// It's used by the wallet provider, the issuer of the attestation.
+func (s *Signer) Attest(issuer, clientID string, instanceKey *jose.JWK, validity time.Duration,
+	opts ...AttestOpt) (string, error) {
+	if issuer == "" || clientID == "" || instanceKey == nil {
+		return "", errors.New("issuer, client ID and wallet instance key are mandatory")
+	}
+
+	now := time.Now()
+
+	claims := &Claims{
+		Claims: &jwt.Claims{
+			Issuer:   issuer,
+			Subject:  clientID,
+			IssuedAt: josejwt.NewNumericDate(now),
+			Expiry:   josejwt.NewNumericDate(now.Add(validity)),
+		},
+		Confirmation: &Confirmation{JWK: instanceKey},
+	}
+
+	for _, opt := range opts {
+		opt(claims)
+	}
+
+	return s.sign(claims, AttestationType)
+}
+
+// ProvePossession creates the proof of possession of the wallet instance key of the attestation for the audience
+// (the issuer the wallet authenticates to) and the nonce provided by the audience, if any.
+// It's used by the wallet instance, with its attested key.
+func (s *Signer) ProvePossession(attestation, clientID, audience, nonce string,
+	validity time.Duration) (*ClientAttestation, error) {
+	if attestation == "" || clientID == "" || audience == "" {
+		return nil, errors.New("attestation, client ID and audience are mandatory")
+	}
+
+	now := time.Now()
+
+	pop, err := s.sign(&popClaims{
+		Claims: &jwt.Claims{
+			Issuer:   clientID,
+			Audience: josejwt.Audience{audience},
+			ID:       uuid.New().String(),
+			IssuedAt: josejwt.NewNumericDate(now),
+			Expiry:   josejwt.NewNumericDate(now.Add(validity)),
+		},
+		Nonce: nonce,
+	}, PoPType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientAttestation{Attestation: attestation, PoP: pop}, nil
+}
+
+func (s *Signer) sign(claims interface{}, typ string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshal %s claims: %w", typ, err)
+	}
+
+	jws, err := jose.NewJWS(jose.Headers{jose.HeaderType: typ}, nil, payload, s)
+	if err != nil {
+		return "", fmt.Errorf("sign %s: %w", typ, err)
+	}
+
+	return jws.SerializeCompact(false)
+}
+
+// ClientAttestation is a wallet attestation together with the proof of possession of the attested key.
+type ClientAttestation struct {
+	Attestation string `json:"attestation"`
+	PoP         string `json:"pop"`
+}
+
+// AddToValues adds the client attestation as client assertion to the parameters of a token request.
+func (a *ClientAttestation) AddToValues(values url.Values) {
+	values.Set(paramClientAssertionType, ClientAssertionType)
+	values.Set(paramClientAssertion, a.Attestation+assertionSeparator+a.PoP)
+}
+
+// SetHeaders sets the client attestation headers of a request.
+func (a *ClientAttestation) SetHeaders(h http.Header) {
+	h.Set(HeaderAttestation, a.Attestation)
+	h.Set(HeaderPoP, a.PoP)
+}
+
+// FromRequest reads the client attestation of a token request from its headers or its client assertion.
+func FromRequest(req *http.Request) (*ClientAttestation, error) {
+	if attestation := req.Header.Get(HeaderAttestation); attestation != "" {
+		return &ClientAttestation{Attestation: attestation, PoP: req.Header.Get(HeaderPoP)}, nil
+	}
+
+	if req.PostFormValue(paramClientAssertionType) != ClientAssertionType {
+		return nil, errors.New("no client attestation in request")
+	}
+
+	parts := strings.Split(req.PostFormValue(paramClientAssertion), assertionSeparator)
+	if len(parts) != 2 { // nolint:gomnd
+		return nil, errors.New("invalid client attestation assertion")
+	}
+
+	return &ClientAttestation{Attestation: parts[0], PoP: parts[1]}, nil
+}
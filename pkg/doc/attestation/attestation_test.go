@@ -0,0 +1,340 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package attestation
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	josejwt "github.com/square/go-jose/v3/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+)
+
+const (
+	walletProvider = "https://wallet-provider.example.com"
+	clientID       = "did:example:wallet-instance"
+	issuer         = "https://issuer.example.com"
+	nonce          = "c8ca0e4f"
+)
+
+type testKeys struct {
+	km     *localkms.LocalKMS
+	crypto *tinkcrypto.Crypto
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	km, err := localkms.New("local-lock://custom/master/key/",
+		mockkms.NewProviderForKMS(storage.NewMockStoreProvider(), &noop.NoLock{}))
+	require.NoError(t, err)
+
+	crypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	return &testKeys{km: km, crypto: crypto}
+}
+
+func (k *testKeys) signer(t *testing.T, keyType kmsapi.KeyType) (*Signer, []byte) {
+	t.Helper()
+
+	keyID, _, err := k.km.Create(keyType)
+	require.NoError(t, err)
+
+	signer, err := NewSigner(k.km, k.crypto, keyID, keyType)
+	require.NoError(t, err)
+
+	pubKey, err := k.km.ExportPubKeyBytes(keyID)
+	require.NoError(t, err)
+
+	return signer, pubKey
+}
+
+func providerResolver(pubKey []byte) jwt.KeyResolver {
+	return jwt.KeyResolverFunc(func(what, kid string) (*verifier.PublicKey, error) {
+		if what != walletProvider {
+			return nil, errors.New("untrusted wallet provider")
+		}
+
+		return &verifier.PublicKey{Value: pubKey}, nil
+	})
+}
+
+func TestClientAttestation(t *testing.T) {
+	keys := newTestKeys(t)
+
+	for _, keyType := range []kmsapi.KeyType{kmsapi.ED25519Type, kmsapi.ECDSAP256TypeIEEEP1363} {
+		providerSigner, providerKey := keys.signer(t, keyType)
+		instanceSigner, _ := keys.signer(t, keyType)
+
+		instanceKey, err := instanceSigner.PublicJWK()
+		require.NoError(t, err)
+
+		attestation, err := providerSigner.Attest(walletProvider, clientID, instanceKey, time.Hour,
+			WithWalletName("Example Wallet"), WithWalletLink("https://wallet.example.com"))
+		require.NoError(t, err)
+
+		a, err := instanceSigner.ProvePossession(attestation, clientID, issuer, nonce, time.Minute)
+		require.NoError(t, err)
+
+		claims, err := a.Verify(providerResolver(providerKey), issuer, WithNonce(nonce))
+		require.NoError(t, err, keyType)
+		require.Equal(t, clientID, claims.Subject)
+		require.Equal(t, walletProvider, claims.Issuer)
+		require.Equal(t, "Example Wallet", claims.WalletName)
+		require.Equal(t, "https://wallet.example.com", claims.WalletLink)
+	}
+}
+
+func TestClientAttestation_Verify(t *testing.T) {
+	keys := newTestKeys(t)
+
+	providerSigner, providerKey := keys.signer(t, kmsapi.ED25519Type)
+	resolver := providerResolver(providerKey)
+	instanceSigner, _ := keys.signer(t, kmsapi.ED25519Type)
+	otherSigner, _ := keys.signer(t, kmsapi.ED25519Type)
+
+	instanceKey, err := instanceSigner.PublicJWK()
+	require.NoError(t, err)
+
+	attestation, err := providerSigner.Attest(walletProvider, clientID, instanceKey, time.Hour)
+	require.NoError(t, err)
+
+	valid, err := instanceSigner.ProvePossession(attestation, clientID, issuer, nonce, time.Minute)
+	require.NoError(t, err)
+
+	t.Run("untrusted wallet provider", func(t *testing.T) {
+		selfAttested, err := otherSigner.Attest("https://other.example.com", clientID, instanceKey, time.Hour)
+		require.NoError(t, err)
+
+		a := &ClientAttestation{Attestation: selfAttested, PoP: valid.PoP}
+
+		_, err = a.Verify(resolver, issuer, WithNonce(nonce))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "untrusted wallet provider")
+	})
+
+	t.Run("attestation not signed by wallet provider", func(t *testing.T) {
+		forged, err := otherSigner.Attest(walletProvider, clientID, instanceKey, time.Hour)
+		require.NoError(t, err)
+
+		_, err = (&ClientAttestation{Attestation: forged, PoP: valid.PoP}).Verify(resolver, issuer, WithNonce(nonce))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature doesn't match")
+	})
+
+	t.Run("proof not signed by attested key", func(t *testing.T) {
+		stolen, err := otherSigner.ProvePossession(attestation, clientID, issuer, nonce, time.Minute)
+		require.NoError(t, err)
+
+		_, err = stolen.Verify(resolver, issuer, WithNonce(nonce))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "wallet attestation proof of possession")
+	})
+
+	t.Run("proof checks", func(t *testing.T) {
+		_, err := valid.Verify(resolver, "https://other.example.com", WithNonce(nonce))
+		require.EqualError(t, err,
+			"wallet attestation proof of possession: audience is not https://other.example.com")
+
+		_, err = valid.Verify(resolver, issuer, WithNonce("other"))
+		require.EqualError(t, err, "wallet attestation proof of possession: nonce mismatch")
+
+		_, err = valid.Verify(resolver, "", WithNonce(nonce))
+		require.EqualError(t, err, "wallet attestation: audience is mandatory")
+
+		_, err = valid.Verify(resolver, issuer)
+		require.EqualError(t, err, "wallet attestation: nonce or replay cache is mandatory")
+
+		other, err := instanceSigner.ProvePossession(attestation, "did:example:other", issuer, nonce, time.Minute)
+		require.NoError(t, err)
+
+		_, err = other.Verify(resolver, issuer, WithNonce(nonce))
+		require.EqualError(t, err, "wallet attestation proof of possession: issuer is not the attested client ID")
+
+		expired, err := instanceSigner.ProvePossession(attestation, clientID, issuer, nonce, -time.Hour)
+		require.NoError(t, err)
+
+		_, err = expired.Verify(resolver, issuer, WithNonce(nonce))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expired")
+	})
+
+	t.Run("replay without nonce", func(t *testing.T) {
+		cache := NewMemReplayCache()
+
+		fresh, err := instanceSigner.ProvePossession(attestation, clientID, issuer, "", time.Minute)
+		require.NoError(t, err)
+
+		_, err = fresh.Verify(resolver, issuer, WithReplayCache(cache))
+		require.NoError(t, err)
+
+		// the same proof presented again
+		_, err = fresh.Verify(resolver, issuer, WithReplayCache(cache))
+		require.EqualError(t, err, "wallet attestation proof of possession: replayed")
+
+		// the proof presented to another issuer
+		_, err = fresh.Verify(resolver, "https://other.example.com",
+			WithReplayCache(NewMemReplayCache()))
+		require.EqualError(t, err,
+			"wallet attestation proof of possession: audience is not https://other.example.com")
+
+		// a proof valid for long, presented after the maximum age
+		old, err := instanceSigner.sign(&popClaims{Claims: &jwt.Claims{
+			Issuer:   clientID,
+			Audience: josejwt.Audience{issuer},
+			ID:       "old",
+			IssuedAt: josejwt.NewNumericDate(time.Now().Add(-time.Hour)),
+			Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}}, PoPType)
+		require.NoError(t, err)
+
+		_, err = (&ClientAttestation{Attestation: attestation, PoP: old}).Verify(resolver, issuer,
+			WithReplayCache(cache), WithMaxAge(10*time.Minute))
+		require.EqualError(t, err, "wallet attestation proof of possession: issued more than 10m0s ago")
+
+		noIAT, err := instanceSigner.sign(&popClaims{Claims: &jwt.Claims{
+			Issuer:   clientID,
+			Audience: josejwt.Audience{issuer},
+			ID:       "no-iat",
+			Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}}, PoPType)
+		require.NoError(t, err)
+
+		_, err = (&ClientAttestation{Attestation: attestation, PoP: noIAT}).Verify(resolver,
+			issuer, WithReplayCache(cache))
+		require.EqualError(t, err, "wallet attestation proof of possession: missing iat")
+	})
+
+	t.Run("replay with nonce not generated by the issuer", func(t *testing.T) {
+		cache := NewMemReplayCache()
+
+		proof, err := instanceSigner.ProvePossession(attestation, clientID, issuer, nonce, time.Minute)
+		require.NoError(t, err)
+
+		_, err = proof.Verify(resolver, issuer, WithNonce("other"), WithReplayCache(cache))
+		require.EqualError(t, err, "wallet attestation proof of possession: nonce mismatch")
+
+		_, err = proof.Verify(resolver, issuer, WithNonce(nonce), WithReplayCache(cache))
+		require.NoError(t, err)
+
+		_, err = proof.Verify(resolver, issuer, WithNonce(nonce), WithReplayCache(cache))
+		require.EqualError(t, err, "wallet attestation proof of possession: replayed")
+
+		_, err = proof.Verify(resolver, issuer, WithNonce(nonce), WithReplayCache(cache), WithMaxAge(-time.Minute),
+			WithLeeway(0))
+		require.EqualError(t, err, "wallet attestation proof of possession: issued more than -1m0s ago")
+	})
+
+	t.Run("attestation checks", func(t *testing.T) {
+		expired, err := providerSigner.Attest(walletProvider, clientID, instanceKey, -time.Hour)
+		require.NoError(t, err)
+
+		_, err = (&ClientAttestation{Attestation: expired, PoP: valid.PoP}).Verify(resolver, issuer, WithNonce(nonce))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expired")
+
+		noKey, err := providerSigner.sign(&Claims{Claims: &jwt.Claims{Issuer: walletProvider, Subject: clientID}},
+			AttestationType)
+		require.NoError(t, err)
+
+		_, err = (&ClientAttestation{Attestation: noKey, PoP: valid.PoP}).Verify(resolver, issuer, WithNonce(nonce))
+		require.EqualError(t, err, "wallet attestation: missing wallet instance key")
+
+		noClient, err := providerSigner.sign(&Claims{Claims: &jwt.Claims{Issuer: walletProvider}}, AttestationType)
+		require.NoError(t, err)
+
+		_, err = (&ClientAttestation{Attestation: noClient, PoP: valid.PoP}).Verify(resolver, issuer, WithNonce(nonce))
+		require.EqualError(t, err, "wallet attestation: missing client ID")
+
+		// the proof of possession isn't an attestation
+		_, err = (&ClientAttestation{Attestation: valid.PoP, PoP: valid.PoP}).Verify(
+			jwt.KeyResolverFunc(func(string, string) (*verifier.PublicKey, error) {
+				pubKey, err := instanceKey.PublicKeyBytes()
+
+				return &verifier.PublicKey{Value: pubKey}, err
+			}), issuer, WithNonce(nonce))
+		require.EqualError(t, err, "wallet attestation: typ is not "+AttestationType)
+
+		_, err = (&ClientAttestation{Attestation: "invalid", PoP: valid.PoP}).Verify(resolver, issuer, WithNonce(nonce))
+		require.Error(t, err)
+	})
+
+	t.Run("mandatory arguments", func(t *testing.T) {
+		_, err := providerSigner.Attest(walletProvider, "", instanceKey, time.Hour)
+		require.EqualError(t, err, "issuer, client ID and wallet instance key are mandatory")
+
+		_, err = instanceSigner.ProvePossession(attestation, clientID, "", nonce, time.Minute)
+		require.EqualError(t, err, "attestation, client ID and audience are mandatory")
+	})
+}
+
+func TestNewSigner(t *testing.T) {
+	keys := newTestKeys(t)
+
+	_, err := NewSigner(keys.km, keys.crypto, "unknown", kmsapi.ED25519Type)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "get key unknown")
+
+	keyID, _, err := keys.km.Create(kmsapi.ECDSAP256TypeDER)
+	require.NoError(t, err)
+
+	_, err = NewSigner(keys.km, keys.crypto, keyID, kmsapi.ECDSAP256TypeDER)
+	require.EqualError(t, err, "unsupported key type ECDSAP256DER")
+}
+
+func TestClientAttestation_Transport(t *testing.T) {
+	a := &ClientAttestation{Attestation: "eyJ.attestation.sig", PoP: "eyJ.pop.sig"}
+
+	t.Run("client assertion", func(t *testing.T) {
+		values := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:pre-authorized_code"}}
+		a.AddToValues(values)
+		require.Equal(t, ClientAssertionType, values.Get("client_assertion_type"))
+
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		received, err := FromRequest(req)
+		require.NoError(t, err)
+		require.Equal(t, a, received)
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/token", nil)
+		a.SetHeaders(req.Header)
+
+		received, err := FromRequest(req)
+		require.NoError(t, err)
+		require.Equal(t, a, received)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := FromRequest(httptest.NewRequest(http.MethodPost, "/token", nil))
+		require.EqualError(t, err, "no client attestation in request")
+
+		values := url.Values{"client_assertion_type": {ClientAssertionType}, "client_assertion": {"eyJ.a.b"}}
+		req := httptest.NewRequest(http.MethodPost, "/token", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		_, err = FromRequest(req)
+		require.EqualError(t, err, "invalid client attestation assertion")
+	})
+}
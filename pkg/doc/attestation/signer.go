@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const (
+	algEdDSA = "EdDSA"
+	algES256 = "ES256"
+)
+
+// Signer signs attestations with a key of the KMS: the wallet provider key for wallet attestations and the
+// wallet instance key for their proofs of possession. Ed25519 and P-256 (IEEE P1363) keys are supported.
+type Signer struct {
+	crypto cryptoapi.Crypto
+	kh     interface{}
+	keyID  string
+	alg    string
+	pubKey interface{}
+}
+
+// NewSigner creates a signer with the key of the KMS.
+func NewSigner(km kmsapi.KeyManager, crypto cryptoapi.Crypto, keyID string, keyType kmsapi.KeyType) (*Signer, error) {
+	var alg string
+
+	switch keyType { // nolint:exhaustive
+	case kmsapi.ED25519Type:
+		alg = algEdDSA
+	case kmsapi.ECDSAP256TypeIEEEP1363:
+		alg = algES256
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", keyType)
+	}
+
+	kh, err := km.Get(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key %s: %w", keyID, err)
+	}
+
+	pubKeyBytes, err := km.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("export public key %s: %w", keyID, err)
+	}
+
+	pubKey, err := publicKey(alg, pubKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{crypto: crypto, kh: kh, keyID: keyID, alg: alg, pubKey: pubKey}, nil
+}
+
+// Sign signs the data.
+func (s *Signer) Sign(data []byte) ([]byte, error) {
+	return s.crypto.Sign(data, s.kh)
+}
+
+// Headers returns the JOSE headers of the signer.
+func (s *Signer) Headers() jose.Headers {
+	return jose.Headers{
+		jose.HeaderAlgorithm: s.alg,
+		jose.HeaderKeyID:     s.keyID,
+	}
+}
+
+// PublicJWK returns the public key of the signer, e.g. the wallet instance key to be attested.
+func (s *Signer) PublicJWK() (*jose.JWK, error) {
+	return jose.JWKFromPublicKey(s.pubKey)
+}
+
+func publicKey(alg string, pubKeyBytes []byte) (interface{}, error) {
+	if alg == algEdDSA {
+		if len(pubKeyBytes) != ed25519.PublicKeySize {
+			return nil, errors.New("bad ed25519 public key length")
+		}
+
+		return ed25519.PublicKey(pubKeyBytes), nil
+	}
+
+	x, y := elliptic.Unmarshal(elliptic.P256(), pubKeyBytes)
+	if x == nil {
+		return nil, errors.New("invalid P-256 public key")
+	}
+
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
@@ -0,0 +1,289 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	josejwt "github.com/square/go-jose/v3/jwt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+)
+
+const (
+	p256SignatureSize = 64
+	defaultMaxAge     = 5 * time.Minute
+)
+
+// VerifyOpt configures the verification of a client attestation.
+type VerifyOpt func(o *verifyOpts)
+
+type verifyOpts struct {
+	nonce       string
+	replayCache ReplayCache
+	maxAge      time.Duration
+	leeway      time.Duration
+}
+
+// WithNonce requires the proof of possession to contain the nonce provided by the verifying issuer,
+// which prevents replay of the proof. A nonce which isn't generated by the issuer, e.g. a thread ID chosen by the
+// wallet, binds the proof to the exchange but doesn't prevent its replay: combine it with WithReplayCache.
+func WithNonce(nonce string) VerifyOpt {
+	return func(o *verifyOpts) {
+		o.nonce = nonce
+	}
+}
+
+// WithReplayCache accepts proofs of possession without nonce, if they were issued within the maximum age
+// (see WithMaxAge) and their jti was not seen before by the cache. Combined with WithNonce, proofs of possession
+// must also contain the nonce.
+func WithReplayCache(cache ReplayCache) VerifyOpt {
+	return func(o *verifyOpts) {
+		o.replayCache = cache
+	}
+}
+
+// WithMaxAge sets the maximum age of proofs of possession without nonce (defaults to five minutes).
+func WithMaxAge(maxAge time.Duration) VerifyOpt {
+	return func(o *verifyOpts) {
+		o.maxAge = maxAge
+	}
+}
+
+// WithLeeway sets the allowed clock skew for the validation of the token times (defaults to one minute).
+func WithLeeway(leeway time.Duration) VerifyOpt {
+	return func(o *verifyOpts) {
+		o.leeway = leeway
+	}
+}
+
+// ReplayCache records the jti of the proofs of possession verified without nonce.
+type ReplayCache interface {
+	// Seen records the jti until the given time and returns true if it is already recorded.
+	Seen(jti string, until time.Time) bool
+}
+
+// MemReplayCache is an in-memory ReplayCache, for a single verifier instance.
+type MemReplayCache struct {
+	seen  map[string]time.Time
+	mutex sync.Mutex
+}
+
+// NewMemReplayCache creates an in-memory ReplayCache.
+func NewMemReplayCache() *MemReplayCache {
+	return &MemReplayCache{seen: make(map[string]time.Time)}
+}
+
+// Seen records the jti until the given time and returns true if it is already recorded.
+func (c *MemReplayCache) Seen(jti string, until time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+
+	for id, expiry := range c.seen {
+		if expiry.Before(now) {
+			delete(c.seen, id)
+		}
+	}
+
+	if _, ok := c.seen[jti]; ok {
+		return true
+	}
+
+	c.seen[jti] = until
+
+	return false
+}
+
+// Verify verifies the wallet attestation with the keys of the trusted wallet providers, resolved by the issuer
+// and key ID of the attestation, and the proof of possession of the attested wallet instance key, which must be
+// addressed to the audience, i.e. the verifying issuer. To prevent its replay, the proof of possession must contain
+// the nonce of WithNonce and/or be fresh and seen for the first time by the cache of WithReplayCache.
+// It returns the claims of the attestation.
+func (a *ClientAttestation) Verify(providers jwt.KeyResolver, audience string, opts ...VerifyOpt) (*Claims, error) {
+	o := &verifyOpts{maxAge: defaultMaxAge, leeway: josejwt.DefaultLeeway}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if audience == "" {
+		return nil, errors.New("wallet attestation: audience is mandatory")
+	}
+
+	if o.nonce == "" && o.replayCache == nil {
+		return nil, errors.New("wallet attestation: nonce or replay cache is mandatory")
+	}
+
+	claims := &Claims{}
+
+	err := parse(a.Attestation, AttestationType, func(headers jose.Headers, payload []byte) (*verifier.PublicKey, error) {
+		var c jwt.Claims
+
+		if err := json.Unmarshal(payload, &c); err != nil {
+			return nil, fmt.Errorf("unmarshal claims: %w", err)
+		}
+
+		kid, _ := headers.KeyID()
+
+		return providers.Resolve(c.Issuer, kid)
+	}, claims)
+	if err != nil {
+		return nil, fmt.Errorf("wallet attestation: %w", err)
+	}
+
+	if err = validateAttestation(claims, o); err != nil {
+		return nil, fmt.Errorf("wallet attestation: %w", err)
+	}
+
+	instanceKey, err := claims.Confirmation.JWK.PublicKeyBytes()
+	if err != nil {
+		return nil, fmt.Errorf("wallet attestation: %w", err)
+	}
+
+	pop := &popClaims{}
+
+	err = parse(a.PoP, PoPType, func(jose.Headers, []byte) (*verifier.PublicKey, error) {
+		return &verifier.PublicKey{Value: instanceKey, JWK: claims.Confirmation.JWK}, nil
+	}, pop)
+	if err != nil {
+		return nil, fmt.Errorf("wallet attestation proof of possession: %w", err)
+	}
+
+	if err = validatePoP(pop, claims.Subject, audience, o); err != nil {
+		return nil, fmt.Errorf("wallet attestation proof of possession: %w", err)
+	}
+
+	return claims, nil
+}
+
+func validateAttestation(claims *Claims, o *verifyOpts) error {
+	if claims.Claims == nil || claims.Subject == "" {
+		return errors.New("missing client ID")
+	}
+
+	if claims.Confirmation == nil || claims.Confirmation.JWK == nil {
+		return errors.New("missing wallet instance key")
+	}
+
+	return validateTimes(claims.Claims, o)
+}
+
+func validatePoP(pop *popClaims, clientID, audience string, o *verifyOpts) error {
+	if pop.Claims == nil || pop.Issuer != clientID {
+		return errors.New("issuer is not the attested client ID")
+	}
+
+	if pop.ID == "" {
+		return errors.New("missing jti")
+	}
+
+	if !pop.Audience.Contains(audience) {
+		return fmt.Errorf("audience is not %s", audience)
+	}
+
+	if err := validateTimes(pop.Claims, o); err != nil {
+		return err
+	}
+
+	if o.nonce != "" && pop.Nonce != o.nonce {
+		return errors.New("nonce mismatch")
+	}
+
+	if o.replayCache == nil {
+		return nil
+	}
+
+	if pop.IssuedAt == nil {
+		return errors.New("missing iat")
+	}
+
+	notAfter := pop.IssuedAt.Time().Add(o.maxAge + o.leeway)
+
+	if time.Now().After(notAfter) {
+		return fmt.Errorf("issued more than %s ago", o.maxAge)
+	}
+
+	if o.replayCache.Seen(pop.ID, notAfter) {
+		return errors.New("replayed")
+	}
+
+	return nil
+}
+
+func validateTimes(claims *jwt.Claims, o *verifyOpts) error {
+	if claims.Expiry == nil {
+		return errors.New("missing expiry")
+	}
+
+	return (*josejwt.Claims)(claims).ValidateWithLeeway(josejwt.Expected{Time: time.Now()}, o.leeway)
+}
+
+type keyFunc func(headers jose.Headers, payload []byte) (*verifier.PublicKey, error)
+
+// parse verifies the signature and the type of the JWT and decodes its claims.
+func parse(token, typ string, key keyFunc, claims interface{}) error {
+	jws, err := jose.ParseJWS(token, jose.SignatureVerifierFunc(
+		func(headers jose.Headers, payload, signingInput, signature []byte) error {
+			pubKey, err := key(headers, payload)
+			if err != nil {
+				return fmt.Errorf("resolve key: %w", err)
+			}
+
+			alg, _ := headers.Algorithm()
+
+			return verifySignature(alg, pubKey, signingInput, signature)
+		}))
+	if err != nil {
+		return err
+	}
+
+	if t, _ := jws.ProtectedHeaders.Type(); t != typ {
+		return fmt.Errorf("typ is not %s", typ)
+	}
+
+	return json.Unmarshal(jws.Payload, claims)
+}
+
+func verifySignature(alg string, pubKey *verifier.PublicKey, message, signature []byte) error {
+	switch alg {
+	case algEdDSA:
+		return jwt.VerifyEdDSA(pubKey, message, signature)
+	case algES256:
+		x, y := elliptic.Unmarshal(elliptic.P256(), pubKey.Value)
+		if x == nil {
+			return errors.New("invalid P-256 public key")
+		}
+
+		if len(signature) != p256SignatureSize {
+			return errors.New("invalid ES256 signature size")
+		}
+
+		digest := sha256.Sum256(message)
+		r := new(big.Int).SetBytes(signature[:p256SignatureSize/2])
+		s := new(big.Int).SetBytes(signature[p256SignatureSize/2:])
+
+		if !ecdsa.Verify(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, digest[:], r, s) {
+			return errors.New("signature doesn't match")
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported alg %q", alg)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bundle exports the material needed to verify credentials offline (issuer DID documents and public
+// keys, JSON-LD contexts, credential schemas and status lists) into signed bundles, and verifies credentials
+// entirely from such a bundle, e.g. in field scenarios without connectivity.
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	josejwt "github.com/square/go-jose/v3/jwt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+)
+
+// Bundle holds the material needed to verify credentials of a set of types offline.
+type Bundle struct {
+	// CredentialTypes are the types of the credentials the bundle is made for.
+	CredentialTypes []string `json:"credentialTypes"`
+	// Created is the time the bundle was exported. The status lists are as of that time.
+	Created time.Time `json:"created"`
+	// DIDDocuments are the DID documents of the issuers, by DID.
+	DIDDocuments map[string]json.RawMessage `json:"didDocuments,omitempty"`
+	// PublicKeys are the public keys of issuers without DID document.
+	PublicKeys []*PublicKey `json:"publicKeys,omitempty"`
+	// Contexts are the JSON-LD contexts, by URL.
+	Contexts map[string]json.RawMessage `json:"contexts,omitempty"`
+	// Schemas are the JSON schemas of the credentials, by URL.
+	Schemas map[string]json.RawMessage `json:"schemas,omitempty"`
+	// StatusLists are the status list credentials (JSON or JWT), by URL.
+	StatusLists map[string]string `json:"statusLists,omitempty"`
+}
+
+// PublicKey is a public key of an issuer.
+type PublicKey struct {
+	Issuer string    `json:"issuer"`
+	KeyID  string    `json:"keyId"`
+	Type   string    `json:"type"`
+	Value  []byte    `json:"value,omitempty"`
+	JWK    *jose.JWK `json:"jwk,omitempty"`
+}
+
+type bundleClaims struct {
+	*jwt.Claims
+
+	Bundle *Bundle `json:"bundle"`
+}
+
+// Sign signs the bundle as a JWT of the issuer of the bundle (e.g. the authority operating the verifiers),
+// valid for the given duration.
+func (b *Bundle) Sign(signer jose.Signer, issuer string, validity time.Duration) (string, error) {
+	now := time.Now()
+
+	token, err := jwt.NewSigned(&bundleClaims{
+		Claims: &jwt.Claims{
+			Issuer:   issuer,
+			IssuedAt: josejwt.NewNumericDate(now),
+			Expiry:   josejwt.NewNumericDate(now.Add(validity)),
+		},
+		Bundle: b,
+	}, nil, signer)
+	if err != nil {
+		return "", fmt.Errorf("sign bundle: %w", err)
+	}
+
+	return token.Serialize(false)
+}
+
+// Parse parses the signed bundle and verifies its signature with the keys of the trusted bundle issuers,
+// resolved by issuer and key ID. Expired bundles are rejected.
+func Parse(signed string, issuers jwt.KeyResolver) (*Bundle, error) {
+	token, err := jwt.Parse(signed, jwt.WithSignatureVerifier(jwt.NewVerifier(issuers)))
+	if err != nil {
+		return nil, fmt.Errorf("parse bundle: %w", err)
+	}
+
+	claims := &bundleClaims{}
+
+	if err = token.DecodeClaims(claims); err != nil {
+		return nil, fmt.Errorf("decode bundle: %w", err)
+	}
+
+	if claims.Claims == nil || claims.Expiry == nil || claims.Bundle == nil {
+		return nil, errors.New("parse bundle: missing expiry or bundle")
+	}
+
+	err = (*josejwt.Claims)(claims.Claims).ValidateWithLeeway(josejwt.Expected{Time: time.Now()},
+		josejwt.DefaultLeeway)
+	if err != nil {
+		return nil, fmt.Errorf("parse bundle: %w", err)
+	}
+
+	return claims.Bundle, nil
+}
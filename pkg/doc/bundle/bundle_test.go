@@ -0,0 +1,479 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockvdr "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+)
+
+const (
+	issuerDID      = "did:example:registry"
+	webIssuer      = "https://permits.example.com"
+	authority      = "did:example:border-authority"
+	permitType     = "PermitCredential"
+	licenseType    = "LicenseCredential"
+	baseContext    = "https://www.w3.org/2018/credentials/v1"
+	jsonSchemaType = "JsonSchemaValidator2018"
+	revokedIndex   = 3
+	validIndex     = 5
+
+	permitSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["credentialSubject", "credentialSchema"]
+}`
+)
+
+func TestBundle(t *testing.T) {
+	issuerSigner, err := signature.NewSigner(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	webSigner, err := signature.NewSigner(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	authoritySigner, err := signature.NewSigner(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	otherSigner, err := signature.NewSigner(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	authSigner, err := signature.NewSigner(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	// key-10 comes first to check that key-1 is matched exactly
+	issuerDoc := did.BuildDoc(
+		did.WithAssertion([]did.Verification{
+			*did.NewEmbeddedVerification(did.NewVerificationMethodFromBytes(issuerDID+"#key-10",
+				"Ed25519VerificationKey2018", issuerDID, otherSigner.PublicKeyBytes()), did.AssertionMethod),
+			*did.NewEmbeddedVerification(did.NewVerificationMethodFromBytes(issuerDID+"#key-1",
+				"Ed25519VerificationKey2018", issuerDID, issuerSigner.PublicKeyBytes()), did.AssertionMethod),
+		}),
+		did.WithAuthentication([]did.Verification{
+			*did.NewEmbeddedVerification(did.NewVerificationMethodFromBytes(issuerDID+"#key-2",
+				"Ed25519VerificationKey2018", issuerDID, authSigner.PublicKeyBytes()), did.Authentication),
+		}),
+	)
+	issuerDoc.ID = issuerDID
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	statusListURL := server.URL + "/status/1"
+	schemaURL := server.URL + "/schemas/permit.json"
+
+	mux.HandleFunc("/schemas/permit.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, e := w.Write([]byte(permitSchema))
+		require.NoError(t, e)
+	})
+
+	mux.HandleFunc("/status/1", func(w http.ResponseWriter, _ *http.Request) {
+		_, e := w.Write([]byte(newStatusList(t, issuerSigner, statusListURL, revokedIndex)))
+		require.NoError(t, e)
+	})
+
+	permit := func(index int) *verifiable.Credential {
+		return &verifiable.Credential{
+			Context: []string{baseContext},
+			ID:      fmt.Sprintf("urn:uuid:permit-%d", index),
+			Types:   []string{"VerifiableCredential", permitType},
+			Subject: verifiable.Subject{ID: "did:example:holder"},
+			Issuer:  verifiable.Issuer{ID: issuerDID},
+			Issued:  util.NewTime(time.Now()),
+			Schemas: []verifiable.TypedID{{ID: schemaURL, Type: jsonSchemaType}},
+			Status: &verifiable.TypedID{
+				ID:   fmt.Sprintf("%s#%d", statusListURL, index),
+				Type: StatusList2021Entry,
+				CustomFields: verifiable.CustomFields{
+					"statusPurpose":        "revocation",
+					"statusListIndex":      fmt.Sprint(index),
+					"statusListCredential": statusListURL,
+				},
+			},
+		}
+	}
+
+	validPermit := signCredential(t, permit(validIndex), issuerSigner, issuerDID+"#key-1")
+	revokedPermit := signCredential(t, permit(revokedIndex), issuerSigner, issuerDID+"#key-1")
+
+	license := signCredential(t, &verifiable.Credential{
+		Context: []string{baseContext},
+		ID:      "urn:uuid:license",
+		Types:   []string{"VerifiableCredential", licenseType},
+		Subject: verifiable.Subject{ID: "did:example:holder"},
+		Issuer:  verifiable.Issuer{ID: webIssuer},
+		Issued:  util.NewTime(time.Now()),
+	}, webSigner, "key-1")
+
+	vdr := &mockvdr.MockVDRegistry{
+		ResolveFunc: func(didID string, _ ...vdrapi.ResolveOpts) (*did.Doc, error) {
+			if didID == issuerDID {
+				return issuerDoc, nil
+			}
+
+			return nil, vdrapi.ErrNotFound
+		},
+	}
+
+	req := &Request{
+		PublicKeys: []*PublicKey{{
+			Issuer: webIssuer,
+			KeyID:  webIssuer + "#key-1",
+			Type:   kmsapi.ED25519,
+			Value:  webSigner.PublicKeyBytes(),
+		}},
+	}
+
+	req.AddCredential(permit(validIndex))
+	req.AddCredential(permit(revokedIndex))
+
+	require.Equal(t, []string{permitType}, req.CredentialTypes)
+	require.Equal(t, []string{issuerDID}, req.Issuers)
+	require.Equal(t, []string{baseContext}, req.Contexts)
+	require.Equal(t, []string{schemaURL}, req.Schemas)
+	require.Equal(t, []string{statusListURL}, req.StatusLists)
+
+	req.CredentialTypes = append(req.CredentialTypes, licenseType)
+
+	exporter := NewExporter(vdr, WithHTTPClient(server.Client()))
+
+	exported, err := exporter.Export(req)
+	require.NoError(t, err)
+
+	authorityKeys := jwt.KeyResolverFunc(func(issuer, kid string) (*verifier.PublicKey, error) {
+		if issuer != authority || kid != "key-1" {
+			return nil, errors.New("untrusted bundle issuer")
+		}
+
+		return &verifier.PublicKey{Type: kmsapi.ED25519, Value: authoritySigner.PublicKeyBytes()}, nil
+	})
+
+	signed, err := exported.Sign(&joseSigner{signer: authoritySigner, kid: "key-1"}, authority, time.Hour)
+	require.NoError(t, err)
+
+	// the verifier is offline from now on
+	server.Close()
+
+	t.Run("verify from the bundle", func(t *testing.T) {
+		b, err := Parse(signed, authorityKeys)
+		require.NoError(t, err)
+		require.Equal(t, exported.Created.Unix(), b.Created.Unix())
+
+		v, err := NewVerifier(b)
+		require.NoError(t, err)
+
+		vc, err := v.Verify([]byte(validPermit))
+		require.NoError(t, err)
+		require.Equal(t, "urn:uuid:permit-5", vc.ID)
+
+		vc, err = v.Verify([]byte(license))
+		require.NoError(t, err)
+		require.Equal(t, "urn:uuid:license", vc.ID)
+
+		_, err = v.Verify([]byte(revokedPermit))
+		require.EqualError(t, err, "verify credential offline: credential status is set (revocation) as of "+
+			b.Created.Format(time.RFC3339))
+	})
+
+	t.Run("reject unsigned and tampered credentials", func(t *testing.T) {
+		b, err := Parse(signed, authorityKeys)
+		require.NoError(t, err)
+
+		v, err := NewVerifier(b)
+		require.NoError(t, err)
+
+		unsigned, err := permit(validIndex).MarshalJSON()
+		require.NoError(t, err)
+
+		_, err = v.Verify(unsigned)
+		require.EqualError(t, err, "verify credential offline: credential has no proof")
+
+		claims, err := permit(validIndex).JWTClaims(false)
+		require.NoError(t, err)
+
+		unsecured, err := claims.MarshalUnsecuredJWT()
+		require.NoError(t, err)
+
+		_, err = v.Verify([]byte(unsecured))
+		require.EqualError(t, err, "verify credential offline: credential has no proof")
+
+		parts := strings.Split(validPermit, ".")
+
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+
+		parts[1] = base64.RawURLEncoding.EncodeToString(
+			bytes.Replace(payload, []byte("did:example:holder"), []byte("did:example:thief"), 1))
+
+		_, err = v.Verify([]byte(strings.Join(parts, ".")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "signature doesn't match")
+
+		// signed with a key of the issuer not meant for assertions
+		_, err = v.Verify([]byte(signCredential(t, permit(validIndex), authSigner, issuerDID+"#key-2")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "public key did:example:registry#key-2 of did:example:registry "+
+			"is not in the bundle")
+	})
+
+	t.Run("material missing from the bundle", func(t *testing.T) {
+		b, err := Parse(signed, authorityKeys)
+		require.NoError(t, err)
+
+		b.CredentialTypes = []string{licenseType}
+
+		v, err := NewVerifier(b)
+		require.NoError(t, err)
+
+		_, err = v.Verify([]byte(validPermit))
+		require.EqualError(t, err, "verify credential offline: types [VerifiableCredential PermitCredential] "+
+			"are not covered by the bundle")
+
+		b.CredentialTypes = []string{permitType}
+		b.StatusLists = nil
+
+		_, err = v.Verify([]byte(validPermit))
+		require.EqualError(t, err, fmt.Sprintf("verify credential offline: status list %s is not in the bundle",
+			statusListURL))
+
+		b.Schemas = nil
+
+		v, err = NewVerifier(b)
+		require.NoError(t, err)
+
+		_, err = v.Verify([]byte(validPermit))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), fmt.Sprintf("%s is not in the bundle", schemaURL))
+
+		b.DIDDocuments = nil
+
+		v, err = NewVerifier(b)
+		require.NoError(t, err)
+
+		_, err = v.Verify([]byte(validPermit))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "public key did:example:registry#key-1 of did:example:registry "+
+			"is not in the bundle")
+
+		_, err = v.loader.LoadDocument("https://example.com/context/v1")
+		require.EqualError(t, err, "context https://example.com/context/v1 is not in the bundle")
+	})
+
+	t.Run("invalid bundle", func(t *testing.T) {
+		_, err := Parse(signed, jwt.KeyResolverFunc(func(string, string) (*verifier.PublicKey, error) {
+			return nil, errors.New("untrusted bundle issuer")
+		}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "untrusted bundle issuer")
+
+		expired, err := exported.Sign(&joseSigner{signer: authoritySigner, kid: "key-1"}, authority, -time.Hour)
+		require.NoError(t, err)
+
+		_, err = Parse(expired, authorityKeys)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "token is expired")
+
+		_, err = NewVerifier(&Bundle{DIDDocuments: map[string]json.RawMessage{issuerDID: json.RawMessage("{")}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse DID document did:example:registry")
+	})
+}
+
+func TestExporter_Export(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, e := w.Write([]byte("not JSON"))
+		require.NoError(t, e)
+	}))
+	defer server.Close()
+
+	exporter := NewExporter(&mockvdr.MockVDRegistry{ResolveErr: errors.New("not found")},
+		WithHTTPClient(server.Client()), WithDocumentLoader(verifiable.CachingJSONLDLoader()))
+
+	_, err := exporter.Export(&Request{})
+	require.EqualError(t, err, "export bundle: credential types are mandatory")
+
+	_, err = exporter.Export(&Request{CredentialTypes: []string{permitType}, Issuers: []string{issuerDID}})
+	require.EqualError(t, err, "export bundle: resolve DID did:example:registry: not found")
+
+	_, err = exporter.Export(&Request{CredentialTypes: []string{permitType}, Schemas: []string{server.URL + "/missing"}})
+	require.EqualError(t, err, fmt.Sprintf("export bundle: load schema %s/missing: HTTP status 404", server.URL))
+
+	_, err = exporter.Export(&Request{CredentialTypes: []string{permitType}, Schemas: []string{server.URL}})
+	require.EqualError(t, err, fmt.Sprintf("export bundle: schema %s is not JSON", server.URL))
+
+	_, err = exporter.Export(&Request{CredentialTypes: []string{permitType}, StatusLists: []string{"invalid"}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "export bundle: load status list invalid")
+}
+
+func TestStatusListEntry(t *testing.T) {
+	statusList, index, err := statusListEntry(&verifiable.TypedID{
+		Type: RevocationList2020Status,
+		CustomFields: verifiable.CustomFields{
+			"revocationListIndex":      float64(7),
+			"revocationListCredential": "https://example.com/status/1",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/status/1", statusList)
+	require.Equal(t, 7, index)
+
+	for _, test := range []struct {
+		status *verifiable.TypedID
+		err    string
+	}{
+		{
+			&verifiable.TypedID{Type: "CredentialStatusList2017"},
+			"unsupported credential status type CredentialStatusList2017",
+		},
+		{&verifiable.TypedID{Type: StatusList2021Entry}, "missing statusListCredential"},
+		{
+			&verifiable.TypedID{Type: StatusList2021Entry, CustomFields: verifiable.CustomFields{
+				"statusListCredential": "https://example.com/status/1",
+			}},
+			"missing statusListIndex",
+		},
+		{
+			&verifiable.TypedID{Type: StatusList2021Entry, CustomFields: verifiable.CustomFields{
+				"statusListCredential": "https://example.com/status/1", "statusListIndex": "first",
+			}},
+			`invalid statusListIndex: strconv.Atoi: parsing "first": invalid syntax`,
+		},
+		{
+			&verifiable.TypedID{Type: StatusList2021Entry, CustomFields: verifiable.CustomFields{
+				"statusListCredential": "https://example.com/status/1", "statusListIndex": "-1",
+			}},
+			"invalid statusListIndex -1",
+		},
+	} {
+		_, _, err = statusListEntry(test.status)
+		require.EqualError(t, err, test.err)
+	}
+}
+
+func TestDecodeStatusList(t *testing.T) {
+	encode := func(size int) string {
+		var compressed bytes.Buffer
+
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write(make([]byte, size))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		return base64.StdEncoding.EncodeToString(compressed.Bytes())
+	}
+
+	statusList := func(encodedList string) *verifiable.Credential {
+		return &verifiable.Credential{Subject: []verifiable.Subject{{
+			CustomFields: verifiable.CustomFields{"encodedList": encodedList},
+		}}}
+	}
+
+	bitstring, err := decodeStatusList(statusList(encode(maxStatusListSize)))
+	require.NoError(t, err)
+	require.Len(t, bitstring, maxStatusListSize)
+
+	_, err = decodeStatusList(statusList(encode(maxStatusListSize + 1)))
+	require.EqualError(t, err, fmt.Sprintf("decompressed encodedList exceeds %d bytes", maxStatusListSize))
+
+	_, err = decodeStatusList(statusList("not base64"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "decode encodedList")
+
+	_, err = decodeStatusList(&verifiable.Credential{Subject: "did:example:list"})
+	require.EqualError(t, err, "status list credential must have one subject")
+}
+
+// newStatusList creates a status list credential of 128 entries with the given indexes set.
+func newStatusList(t *testing.T, signer signature.Signer, id string, indexes ...int) string {
+	t.Helper()
+
+	bitstring := make([]byte, 16)
+
+	for _, i := range indexes {
+		bitstring[i/8] |= 1 << (7 - i%8)
+	}
+
+	var compressed bytes.Buffer
+
+	w := gzip.NewWriter(&compressed)
+	_, err := w.Write(bitstring)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return signCredential(t, &verifiable.Credential{
+		Context: []string{baseContext},
+		ID:      id,
+		Types:   []string{"VerifiableCredential", "StatusList2021Credential"},
+		Subject: verifiable.Subject{
+			ID: id + "#list",
+			CustomFields: verifiable.CustomFields{
+				"type":          "StatusList2021",
+				"statusPurpose": "revocation",
+				"encodedList":   base64.RawURLEncoding.EncodeToString(compressed.Bytes()),
+			},
+		},
+		Issuer: verifiable.Issuer{ID: issuerDID},
+		Issued: util.NewTime(time.Now()),
+	}, signer, issuerDID+"#key-1")
+}
+
+func signCredential(t *testing.T, vc *verifiable.Credential, signer signature.Signer, keyID string) string {
+	t.Helper()
+
+	claims, err := vc.JWTClaims(false)
+	require.NoError(t, err)
+
+	jws, err := claims.MarshalJWS(verifiable.EdDSA, signer, keyID)
+	require.NoError(t, err)
+
+	return jws
+}
+
+type joseSigner struct {
+	signer signature.Signer
+	kid    string
+}
+
+func (s *joseSigner) Sign(data []byte) ([]byte, error) {
+	return s.signer.Sign(data)
+}
+
+func (s *joseSigner) Headers() jose.Headers {
+	return jose.Headers{
+		jose.HeaderAlgorithm: "EdDSA",
+		jose.HeaderKeyID:     s.kid,
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+)
+
+const vcType = "VerifiableCredential"
+
+var logger = log.New("aries-framework/doc/bundle")
+
+// Request is the material to export into a bundle.
+type Request struct {
+	CredentialTypes []string
+	// Issuers are the DIDs of the issuers, resolved with the VDR.
+	Issuers []string
+	// PublicKeys are the public keys of issuers without DID document.
+	PublicKeys []*PublicKey
+	// Contexts are the URLs of the JSON-LD contexts, loaded with the JSON-LD document loader.
+	Contexts []string
+	// Schemas are the URLs of the JSON schemas, downloaded with the HTTP client.
+	Schemas []string
+	// StatusLists are the URLs of the status list credentials, downloaded with the HTTP client.
+	StatusLists []string
+}
+
+// AddCredential adds the material needed to verify credentials like the given one: its types (but the base
+// VerifiableCredential type), the DIDs of its issuer and of the verification methods of its proofs, its
+// contexts, schemas and status list.
+func (r *Request) AddCredential(vc *verifiable.Credential) {
+	for _, t := range vc.Types {
+		if t != vcType {
+			r.CredentialTypes = appendNew(r.CredentialTypes, t)
+		}
+	}
+
+	r.Contexts = appendNew(r.Contexts, vc.Context...)
+
+	if strings.HasPrefix(vc.Issuer.ID, "did:") {
+		r.Issuers = appendNew(r.Issuers, vc.Issuer.ID)
+	}
+
+	for _, p := range vc.Proofs {
+		if vm, ok := p["verificationMethod"].(string); ok && strings.HasPrefix(vm, "did:") {
+			r.Issuers = appendNew(r.Issuers, strings.Split(vm, "#")[0])
+		}
+	}
+
+	for _, schema := range vc.Schemas {
+		r.Schemas = appendNew(r.Schemas, schema.ID)
+	}
+
+	if vc.Status != nil {
+		if statusList, _, err := statusListEntry(vc.Status); err == nil {
+			r.StatusLists = appendNew(r.StatusLists, statusList)
+		}
+	}
+}
+
+func appendNew(s []string, values ...string) []string {
+	for _, v := range values {
+		if v != "" && !contains(s, v) {
+			s = append(s, v)
+		}
+	}
+
+	return s
+}
+
+func contains(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Exporter exports bundles, resolving and downloading their material.
+type Exporter struct {
+	vdr            vdrapi.Registry
+	documentLoader ld.DocumentLoader
+	httpClient     *http.Client
+}
+
+// ExporterOpt configures the exporter.
+type ExporterOpt func(e *Exporter)
+
+// WithDocumentLoader sets the JSON-LD document loader of the contexts (defaults to verifiable.CachingJSONLDLoader).
+func WithDocumentLoader(loader ld.DocumentLoader) ExporterOpt {
+	return func(e *Exporter) {
+		e.documentLoader = loader
+	}
+}
+
+// WithHTTPClient sets the HTTP client downloading the schemas and status lists.
+func WithHTTPClient(client *http.Client) ExporterOpt {
+	return func(e *Exporter) {
+		e.httpClient = client
+	}
+}
+
+// NewExporter creates an exporter resolving the DIDs of the issuers with the VDR.
+func NewExporter(vdr vdrapi.Registry, opts ...ExporterOpt) *Exporter {
+	e := &Exporter{vdr: vdr}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.documentLoader == nil {
+		e.documentLoader = verifiable.CachingJSONLDLoader()
+	}
+
+	if e.httpClient == nil {
+		e.httpClient = &http.Client{}
+	}
+
+	return e
+}
+
+// Export exports the material of the request into a bundle. The status lists are downloaded at the time of
+// the export, which is the creation time of the bundle.
+func (e *Exporter) Export(req *Request) (*Bundle, error) {
+	if len(req.CredentialTypes) == 0 {
+		return nil, errors.New("export bundle: credential types are mandatory")
+	}
+
+	b := &Bundle{
+		CredentialTypes: req.CredentialTypes,
+		Created:         time.Now().UTC(),
+		DIDDocuments:    make(map[string]json.RawMessage),
+		PublicKeys:      req.PublicKeys,
+		Contexts:        make(map[string]json.RawMessage),
+		Schemas:         make(map[string]json.RawMessage),
+		StatusLists:     make(map[string]string),
+	}
+
+	for _, issuer := range req.Issuers {
+		doc, err := e.vdr.Resolve(issuer)
+		if err != nil {
+			return nil, fmt.Errorf("export bundle: resolve DID %s: %w", issuer, err)
+		}
+
+		if b.DIDDocuments[issuer], err = doc.JSONBytes(); err != nil {
+			return nil, fmt.Errorf("export bundle: marshal DID document %s: %w", issuer, err)
+		}
+	}
+
+	for _, u := range req.Contexts {
+		remoteDoc, err := e.documentLoader.LoadDocument(u)
+		if err != nil {
+			return nil, fmt.Errorf("export bundle: load context %s: %w", u, err)
+		}
+
+		if b.Contexts[u], err = json.Marshal(remoteDoc.Document); err != nil {
+			return nil, fmt.Errorf("export bundle: marshal context %s: %w", u, err)
+		}
+	}
+
+	for _, u := range req.Schemas {
+		schema, err := e.download(u)
+		if err != nil {
+			return nil, fmt.Errorf("export bundle: load schema %s: %w", u, err)
+		}
+
+		if !json.Valid(schema) {
+			return nil, fmt.Errorf("export bundle: schema %s is not JSON", u)
+		}
+
+		b.Schemas[u] = schema
+	}
+
+	for _, u := range req.StatusLists {
+		statusList, err := e.download(u)
+		if err != nil {
+			return nil, fmt.Errorf("export bundle: load status list %s: %w", u, err)
+		}
+
+		b.StatusLists[u] = strings.TrimSpace(string(statusList))
+	}
+
+	return b, nil
+}
+
+func (e *Exporter) download(u string) ([]byte, error) {
+	resp, err := e.httpClient.Get(u)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if errClose := resp.Body.Close(); errClose != nil {
+			logger.Errorf("closing response body failed [%v]", errClose)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// Supported credential status types.
+const (
+	StatusList2021Entry      = "StatusList2021Entry"
+	RevocationList2020Status = "RevocationList2020Status"
+
+	bitsPerByte = 8
+
+	// maxStatusListSize caps the size of decompressed status list bitstrings (16 MiB, i.e. 128M entries).
+	maxStatusListSize = 16 << 20
+)
+
+// statusListEntry returns the URL of the status list credential and the index of the credential in the list.
+func statusListEntry(status *verifiable.TypedID) (string, int, error) {
+	var listField, indexField string
+
+	switch status.Type {
+	case StatusList2021Entry:
+		listField, indexField = "statusListCredential", "statusListIndex"
+	case RevocationList2020Status:
+		listField, indexField = "revocationListCredential", "revocationListIndex"
+	default:
+		return "", 0, fmt.Errorf("unsupported credential status type %s", status.Type)
+	}
+
+	statusList, ok := status.CustomFields[listField].(string)
+	if !ok || statusList == "" {
+		return "", 0, fmt.Errorf("missing %s", listField)
+	}
+
+	var index int
+
+	switch i := status.CustomFields[indexField].(type) {
+	case string:
+		n, err := strconv.Atoi(i)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid %s: %w", indexField, err)
+		}
+
+		index = n
+	case float64:
+		index = int(i)
+	default:
+		return "", 0, fmt.Errorf("missing %s", indexField)
+	}
+
+	if index < 0 {
+		return "", 0, fmt.Errorf("invalid %s %d", indexField, index)
+	}
+
+	return statusList, index, nil
+}
+
+func (v *Verifier) checkStatus(vc *verifiable.Credential) error {
+	if vc.Status == nil {
+		return nil
+	}
+
+	listURL, index, err := statusListEntry(vc.Status)
+	if err != nil {
+		return fmt.Errorf("credential status: %w", err)
+	}
+
+	rawList, ok := v.bundle.StatusLists[listURL]
+	if !ok {
+		return fmt.Errorf("status list %s is not in the bundle", listURL)
+	}
+
+	statusListVC, err := verifiable.ParseCredential([]byte(rawList), v.CredentialOpts()...)
+	if err != nil {
+		return fmt.Errorf("status list %s: %w", listURL, err)
+	}
+
+	bitstring, err := decodeStatusList(statusListVC)
+	if err != nil {
+		return fmt.Errorf("status list %s: %w", listURL, err)
+	}
+
+	if index/bitsPerByte >= len(bitstring) {
+		return fmt.Errorf("status list %s: index %d out of range", listURL, index)
+	}
+
+	if bitstring[index/bitsPerByte]&(1<<(bitsPerByte-1-index%bitsPerByte)) != 0 {
+		purpose, _ := vc.Status.CustomFields["statusPurpose"].(string) // nolint:errcheck
+		if purpose == "" {
+			purpose = "revocation"
+		}
+
+		return fmt.Errorf("credential status is set (%s) as of %s", purpose, v.bundle.Created.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// decodeStatusList decodes the GZIP-compressed, base64-encoded bitstring of the status list credential.
+func decodeStatusList(vc *verifiable.Credential) ([]byte, error) {
+	subjects, ok := vc.Subject.([]verifiable.Subject)
+	if !ok || len(subjects) != 1 {
+		return nil, errors.New("status list credential must have one subject")
+	}
+
+	encodedList, ok := subjects[0].CustomFields["encodedList"].(string)
+	if !ok {
+		return nil, errors.New("missing encodedList")
+	}
+
+	encodedList = strings.TrimRight(encodedList, "=")
+
+	compressed, err := base64.RawURLEncoding.DecodeString(encodedList)
+	if err != nil {
+		compressed, err = base64.RawStdEncoding.DecodeString(encodedList)
+		if err != nil {
+			return nil, fmt.Errorf("decode encodedList: %w", err)
+		}
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("decompress encodedList: %w", err)
+	}
+
+	bitstring, err := ioutil.ReadAll(io.LimitReader(reader, maxStatusListSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress encodedList: %w", err)
+	}
+
+	if len(bitstring) > maxStatusListSize {
+		return nil, fmt.Errorf("decompressed encodedList exceeds %d bytes", maxStatusListSize)
+	}
+
+	return bitstring, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// Verifier verifies credentials entirely from a bundle: keys, contexts, schemas and status lists missing from
+// the bundle are never fetched, and the credentials referencing them are rejected.
+type Verifier struct {
+	bundle  *Bundle
+	docs    map[string]*did.Doc
+	loader  *documentLoader
+	schemas schemaCache
+}
+
+// NewVerifier creates a verifier of credentials from the bundle, e.g. parsed with Parse.
+func NewVerifier(b *Bundle) (*Verifier, error) {
+	v := &Verifier{
+		bundle:  b,
+		docs:    make(map[string]*did.Doc),
+		loader:  &documentLoader{contexts: make(map[string]interface{})},
+		schemas: schemaCache(b.Schemas),
+	}
+
+	for id, docBytes := range b.DIDDocuments {
+		doc, err := did.ParseDocument(docBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse DID document %s: %w", id, err)
+		}
+
+		v.docs[id] = doc
+	}
+
+	for u, contextBytes := range b.Contexts {
+		context, err := ld.DocumentFromReader(bytes.NewReader(contextBytes))
+		if err != nil {
+			return nil, fmt.Errorf("parse context %s: %w", u, err)
+		}
+
+		v.loader.contexts[u] = context
+	}
+
+	return v, nil
+}
+
+// CredentialOpts returns the options of verifiable.ParseCredential resolving the public keys, contexts and
+// schemas from the bundle only.
+func (v *Verifier) CredentialOpts() []verifiable.CredentialOpt {
+	schemaLoader := verifiable.NewCredentialSchemaLoaderBuilder().
+		SetCache(v.schemas).
+		SetSchemaDownloadClient(&http.Client{Transport: offlineTransport{}}).
+		Build()
+
+	return []verifiable.CredentialOpt{
+		verifiable.WithPublicKeyFetcher(v.resolvePublicKey),
+		verifiable.WithJSONLDDocumentLoader(v.loader),
+		verifiable.WithCredentialSchemaLoader(schemaLoader),
+	}
+}
+
+// Verify parses and verifies the credential from the bundle: the credential must be signed (as a JWS or with
+// at least one embedded proof), it must be of one of the types of the bundle, and its status is checked against
+// the status list of the bundle, as of the creation of the bundle.
+func (v *Verifier) Verify(vcData []byte, opts ...verifiable.CredentialOpt) (*verifiable.Credential, error) {
+	vc, err := verifiable.ParseCredential(vcData, append(opts, v.CredentialOpts()...)...)
+	if err != nil {
+		return nil, fmt.Errorf("verify credential offline: %w", err)
+	}
+
+	// ParseCredential accepts credentials without embedded proof, and unsecured JWTs.
+	if !jwt.IsJWS(strings.TrimSpace(string(vcData))) && len(vc.Proofs) == 0 {
+		return nil, errors.New("verify credential offline: credential has no proof")
+	}
+
+	if !v.coversTypes(vc.Types) {
+		return nil, fmt.Errorf("verify credential offline: types %v are not covered by the bundle", vc.Types)
+	}
+
+	if err = v.checkStatus(vc); err != nil {
+		return nil, fmt.Errorf("verify credential offline: %w", err)
+	}
+
+	return vc, nil
+}
+
+func (v *Verifier) coversTypes(types []string) bool {
+	for _, t := range types {
+		if contains(v.bundle.CredentialTypes, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolvePublicKey resolves the public key of the issuer from the assertion methods of its DID document, or from
+// the public keys of the bundle. The key ID is either a DID URL or a fragment ("#key-1") of the issuer DID.
+func (v *Verifier) resolvePublicKey(issuerID, keyID string) (*sigverifier.PublicKey, error) {
+	if doc, ok := v.docs[issuerID]; ok {
+		for _, verification := range doc.VerificationMethods(did.AssertionMethod)[did.AssertionMethod] {
+			vm := verification.VerificationMethod
+
+			if absoluteKeyID(issuerID, vm.ID) == absoluteKeyID(issuerID, keyID) {
+				return &sigverifier.PublicKey{Type: vm.Type, Value: vm.Value, JWK: vm.JSONWebKey()}, nil
+			}
+		}
+	}
+
+	for _, pk := range v.bundle.PublicKeys {
+		if pk.Issuer == issuerID && keyFragment(pk.KeyID) == keyFragment(keyID) {
+			return &sigverifier.PublicKey{Type: pk.Type, Value: pk.Value, JWK: pk.JWK}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("public key %s of %s is not in the bundle", keyID, issuerID)
+}
+
+func absoluteKeyID(issuerID, keyID string) string {
+	if strings.HasPrefix(keyID, "#") {
+		return issuerID + keyID
+	}
+
+	return keyID
+}
+
+func keyFragment(keyID string) string {
+	return keyID[strings.LastIndex(keyID, "#")+1:]
+}
+
+// documentLoader loads the JSON-LD contexts of the bundle.
+type documentLoader struct {
+	contexts map[string]interface{}
+}
+
+func (l *documentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	context, ok := l.contexts[u]
+	if !ok {
+		return nil, fmt.Errorf("context %s is not in the bundle", u)
+	}
+
+	return &ld.RemoteDocument{DocumentURL: u, Document: context}, nil
+}
+
+// schemaCache serves the schemas of the bundle to the credential schema loader.
+type schemaCache map[string]json.RawMessage
+
+func (c schemaCache) Put(string, []byte) {}
+
+func (c schemaCache) Get(k string) ([]byte, bool) {
+	v, ok := c[k]
+
+	return v, ok
+}
+
+// offlineTransport rejects the download of schemas missing from the bundle.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("%s is not in the bundle", req.URL)
+}
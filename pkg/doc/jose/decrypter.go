@@ -47,10 +47,8 @@ func NewJWEDecrypt(store storage.Store, c cryptoapi.Crypto, k kms.KeyManager) *J
 	}
 }
 
-func getECDHDecPrimitive(cek []byte) (api.CompositeDecrypt, error) {
-	kt := ecdh.AES256GCMKeyTemplateWithCEK(cek)
-
-	kh, err := keyset.NewHandle(kt)
+func getECDHDecPrimitive(encAlg EncAlg, cek []byte) (api.CompositeDecrypt, error) {
+	kh, err := keyset.NewHandle(contentEncryptionKeyTemplate(encAlg, cek))
 	if err != nil {
 		return nil, err
 	}
@@ -119,7 +117,9 @@ func (jd *JWEDecrypt) unwrapCEK(recWK []*cryptoapi.RecipientWrappedKey,
 }
 
 func (jd *JWEDecrypt) decryptJWE(jwe *JSONWebEncryption, cek []byte) ([]byte, error) {
-	decPrimitive, err := getECDHDecPrimitive(cek)
+	encAlg, _ := jwe.ProtectedHeaders.Encryption()
+
+	decPrimitive, err := getECDHDecPrimitive(EncAlg(encAlg), cek)
 	if err != nil {
 		return nil, fmt.Errorf("jwedecrypt: failed to get decryption primitive: %w", err)
 	}
@@ -173,9 +173,8 @@ func (jd *JWEDecrypt) validateAndExtractProtectedHeaders(jwe *JSONWebEncryption)
 		return fmt.Errorf("jwe is missing encryption algorithm 'enc' header")
 	}
 
-	// TODO add support for Chacha content encryption, issue #1684
-	switch encAlg {
-	case string(A256GCM):
+	switch EncAlg(encAlg) {
+	case A256GCM, XC20P:
 	default:
 		return fmt.Errorf("encryption algorithm '%s' not supported", encAlg)
 	}
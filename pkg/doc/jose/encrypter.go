@@ -17,6 +17,7 @@ import (
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/subtle/random"
 	"github.com/square/go-jose/v3"
 
@@ -34,6 +35,8 @@ type EncAlg string
 const (
 	// A256GCM for AES256GCM content encryption.
 	A256GCM = EncAlg(A256GCMALG)
+	// XC20P for XChacha20Poly1305 content encryption.
+	XC20P = EncAlg(XC20PALG)
 )
 
 // Encrypter interface to Encrypt/Decrypt JWE messages.
@@ -64,9 +67,8 @@ func NewJWEEncrypt(encAlg EncAlg, encType, senderKID string, senderKH *keyset.Ha
 		return nil, fmt.Errorf("empty recipientsPubKeys list")
 	}
 
-	// TODO add support for Chacha content encryption, issue #1684
 	switch encAlg {
-	case A256GCM:
+	case A256GCM, XC20P:
 	default:
 		return nil, fmt.Errorf("encryption algorithm '%s' not supported", encAlg)
 	}
@@ -92,10 +94,8 @@ func NewJWEEncrypt(encAlg EncAlg, encType, senderKID string, senderKH *keyset.Ha
 	}, nil
 }
 
-func getECDHEncPrimitive(cek []byte) (api.CompositeEncrypt, error) {
-	kt := ecdh.AES256GCMKeyTemplateWithCEK(cek)
-
-	kh, err := keyset.NewHandle(kt)
+func getECDHEncPrimitive(encAlg EncAlg, cek []byte) (api.CompositeEncrypt, error) {
+	kh, err := keyset.NewHandle(contentEncryptionKeyTemplate(encAlg, cek))
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +108,16 @@ func getECDHEncPrimitive(cek []byte) (api.CompositeEncrypt, error) {
 	return ecdh.NewECDHEncrypt(pubKH)
 }
 
+// contentEncryptionKeyTemplate returns the template of the key executing the composite primitives with the cek for
+// the content encryption algorithm.
+func contentEncryptionKeyTemplate(encAlg EncAlg, cek []byte) *tinkpb.KeyTemplate {
+	if encAlg == XC20P {
+		return ecdh.XChaCha20Poly1305KeyTemplateWithCEK(cek)
+	}
+
+	return ecdh.AES256GCMKeyTemplateWithCEK(cek)
+}
+
 // Encrypt encrypt plaintext with AAD and returns a JSONWebEncryption instance to serialize a JWE instance.
 func (je *JWEEncrypt) Encrypt(plaintext []byte) (*JSONWebEncryption, error) {
 	return je.EncryptWithAuthData(plaintext, nil)
@@ -127,7 +137,7 @@ func (je *JWEEncrypt) EncryptWithAuthData(plaintext, aad []byte) (*JSONWebEncryp
 	cek := random.GetRandomBytes(uint32(cryptoapi.DefKeySize))
 
 	// creating the crypto primitive requires a pre-built cek
-	encPrimitive, err := getECDHEncPrimitive(cek)
+	encPrimitive, err := getECDHEncPrimitive(je.encAlg, cek)
 	if err != nil {
 		return nil, fmt.Errorf("jweencrypt: failed to get encryption primitive: %w", err)
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/google/tink/go/subtle"
 	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
 
 	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
@@ -197,6 +198,34 @@ func TestJWEEncryptRoundTripWithSingleRecipient(t *testing.T) {
 	require.EqualValues(t, pt, msg)
 }
 
+func TestJWEEncryptRoundTripWithXC20P(t *testing.T) {
+	recECKeys, recKHs, _ := createRecipients(t, 2)
+
+	c, k := createCryptoAndKMSServices(t, recKHs)
+
+	jweEncrypter, err := ariesjose.NewJWEEncrypt(ariesjose.XC20P, ariesjose.DIDCommEncType, "", nil, recECKeys, c)
+	require.NoError(t, err)
+
+	pt := []byte("some msg")
+	jwe, err := jweEncrypter.Encrypt(pt)
+	require.NoError(t, err)
+	require.Len(t, jwe.IV, chacha20poly1305.NonceSizeX)
+
+	serializedJWE, err := jwe.FullSerialize(json.Marshal)
+	require.NoError(t, err)
+
+	localJWE, err := ariesjose.Deserialize(serializedJWE)
+	require.NoError(t, err)
+
+	enc, ok := localJWE.ProtectedHeaders.Encryption()
+	require.True(t, ok)
+	require.Equal(t, ariesjose.XC20PALG, enc)
+
+	msg, err := ariesjose.NewJWEDecrypt(nil, c, k).Decrypt(localJWE)
+	require.NoError(t, err)
+	require.EqualValues(t, pt, msg)
+}
+
 func TestInteropWithGoJoseEncryptAndLocalJoseDecryptUsingCompactSerialize(t *testing.T) {
 	recECKeys, recKHs, recKIDs := createRecipients(t, 1)
 	gjRecipients := convertToGoJoseRecipients(t, recECKeys, recKIDs)
@@ -0,0 +1,376 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presexch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// OIDC4VPResponseType is the response type of OpenID for Verifiable Presentations authorization requests.
+const OIDC4VPResponseType = "vp_token"
+
+// OpenID for Verifiable Presentations (https://openid.net/specs/openid-4-verifiable-presentations-1_0.html)
+// authorization request and response parameters.
+const (
+	oidc4vpResponseType              = "response_type"
+	oidc4vpClientID                  = "client_id"
+	oidc4vpRedirectURI               = "redirect_uri"
+	oidc4vpResponseMode              = "response_mode"
+	oidc4vpScope                     = "scope"
+	oidc4vpNonce                     = "nonce"
+	oidc4vpState                     = "state"
+	oidc4vpPresentationDefinition    = "presentation_definition"
+	oidc4vpPresentationDefinitionURI = "presentation_definition_uri"
+	oidc4vpVPToken                   = "vp_token"
+	oidc4vpPresentationSubmission    = "presentation_submission"
+)
+
+// OIDC4VPRequest is an OpenID for Verifiable Presentations authorization request of a verifier.
+// The presentation definition is either embedded or referenced by PresentationDefinitionURI.
+type OIDC4VPRequest struct {
+	ResponseType              string
+	ClientID                  string
+	RedirectURI               string
+	ResponseMode              string
+	Scope                     string
+	Nonce                     string
+	State                     string
+	PresentationDefinition    *PresentationDefinition
+	PresentationDefinitionURI string
+}
+
+// OIDC4VPRequestOpt configures an OpenID for Verifiable Presentations authorization request.
+type OIDC4VPRequestOpt func(r *OIDC4VPRequest)
+
+// WithOIDC4VPRedirectURI sets the redirect URI of the request.
+func WithOIDC4VPRedirectURI(uri string) OIDC4VPRequestOpt {
+	return func(r *OIDC4VPRequest) {
+		r.RedirectURI = uri
+	}
+}
+
+// WithOIDC4VPResponseMode sets the response mode of the request, e.g. "direct_post".
+func WithOIDC4VPResponseMode(mode string) OIDC4VPRequestOpt {
+	return func(r *OIDC4VPRequest) {
+		r.ResponseMode = mode
+	}
+}
+
+// WithOIDC4VPScope sets the scope of the request.
+func WithOIDC4VPScope(scope string) OIDC4VPRequestOpt {
+	return func(r *OIDC4VPRequest) {
+		r.Scope = scope
+	}
+}
+
+// WithOIDC4VPState sets the state of the request, returned unchanged in the response.
+func WithOIDC4VPState(state string) OIDC4VPRequestOpt {
+	return func(r *OIDC4VPRequest) {
+		r.State = state
+	}
+}
+
+// WithOIDC4VPDefinitionURI references the presentation definition by the given URI, where the verifier
+// serves it, instead of embedding it into the request.
+func WithOIDC4VPDefinitionURI(uri string) OIDC4VPRequestOpt {
+	return func(r *OIDC4VPRequest) {
+		r.PresentationDefinitionURI = uri
+	}
+}
+
+// OIDC4VPRequest creates an OpenID for Verifiable Presentations authorization request for the presentation
+// definition, so that the same definition can be requested both with DIDComm present proof and OpenID.
+func (pd *PresentationDefinition) OIDC4VPRequest(clientID, nonce string,
+	opts ...OIDC4VPRequestOpt) (*OIDC4VPRequest, error) {
+	if clientID == "" || nonce == "" {
+		return nil, errors.New("client ID and nonce are mandatory")
+	}
+
+	if err := pd.ValidateSchema(); err != nil {
+		return nil, fmt.Errorf("presentation definition: %w", err)
+	}
+
+	r := &OIDC4VPRequest{
+		ResponseType: OIDC4VPResponseType,
+		ClientID:     clientID,
+		Nonce:        nonce,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.PresentationDefinitionURI == "" {
+		r.PresentationDefinition = pd
+	}
+
+	return r, nil
+}
+
+// Values returns the parameters of the request, e.g. to be encoded into the query of an authorization URL.
+func (r *OIDC4VPRequest) Values() (url.Values, error) {
+	values := url.Values{}
+
+	setValue(values, oidc4vpResponseType, r.ResponseType)
+	setValue(values, oidc4vpClientID, r.ClientID)
+	setValue(values, oidc4vpRedirectURI, r.RedirectURI)
+	setValue(values, oidc4vpResponseMode, r.ResponseMode)
+	setValue(values, oidc4vpScope, r.Scope)
+	setValue(values, oidc4vpNonce, r.Nonce)
+	setValue(values, oidc4vpState, r.State)
+	setValue(values, oidc4vpPresentationDefinitionURI, r.PresentationDefinitionURI)
+
+	if r.PresentationDefinition != nil {
+		pdBytes, err := json.Marshal(r.PresentationDefinition)
+		if err != nil {
+			return nil, fmt.Errorf("marshal presentation definition: %w", err)
+		}
+
+		values.Set(oidc4vpPresentationDefinition, string(pdBytes))
+	}
+
+	return values, nil
+}
+
+// ParseOIDC4VPRequest parses the parameters of an OpenID for Verifiable Presentations authorization request.
+// If the presentation definition is referenced by PresentationDefinitionURI, the caller fetches it from there.
+func ParseOIDC4VPRequest(values url.Values) (*OIDC4VPRequest, error) {
+	r := &OIDC4VPRequest{
+		ResponseType:              values.Get(oidc4vpResponseType),
+		ClientID:                  values.Get(oidc4vpClientID),
+		RedirectURI:               values.Get(oidc4vpRedirectURI),
+		ResponseMode:              values.Get(oidc4vpResponseMode),
+		Scope:                     values.Get(oidc4vpScope),
+		Nonce:                     values.Get(oidc4vpNonce),
+		State:                     values.Get(oidc4vpState),
+		PresentationDefinitionURI: values.Get(oidc4vpPresentationDefinitionURI),
+	}
+
+	if !stringsContain(strings.Fields(r.ResponseType), OIDC4VPResponseType) {
+		return nil, fmt.Errorf("response type %q is not %s", r.ResponseType, OIDC4VPResponseType)
+	}
+
+	if r.ClientID == "" || r.Nonce == "" {
+		return nil, errors.New("client ID and nonce are mandatory")
+	}
+
+	pd := values.Get(oidc4vpPresentationDefinition)
+
+	if (pd == "") == (r.PresentationDefinitionURI == "") {
+		return nil, fmt.Errorf("exactly one of %s and %s is required", oidc4vpPresentationDefinition,
+			oidc4vpPresentationDefinitionURI)
+	}
+
+	if pd == "" {
+		return r, nil
+	}
+
+	r.PresentationDefinition = &PresentationDefinition{}
+
+	if err := json.Unmarshal([]byte(pd), r.PresentationDefinition); err != nil {
+		return nil, fmt.Errorf("unmarshal presentation definition: %w", err)
+	}
+
+	if err := r.PresentationDefinition.ValidateSchema(); err != nil {
+		return nil, fmt.Errorf("presentation definition: %w", err)
+	}
+
+	return r, nil
+}
+
+// OIDC4VPResponse is an OpenID for Verifiable Presentations authorization response of a holder.
+type OIDC4VPResponse struct {
+	// VPToken is the JSON or JWT encoded verifiable presentation.
+	VPToken                string
+	PresentationSubmission *PresentationSubmission
+	State                  string
+}
+
+// NewOIDC4VPResponse creates the response for the verifiable presentation created for a presentation definition
+// (see CreateVP). The presentation is kept as is, so that its proof stays valid, and its presentation submission
+// is also returned as a response parameter. To respond with a JWT presentation, set VPToken to the JWT.
+func NewOIDC4VPResponse(vp *verifiable.Presentation, state string) (*OIDC4VPResponse, error) {
+	submission, err := presentationSubmission(vp)
+	if err != nil {
+		return nil, err
+	}
+
+	vpBytes, err := vp.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshal vp: %w", err)
+	}
+
+	return &OIDC4VPResponse{
+		VPToken:                string(vpBytes),
+		PresentationSubmission: submission,
+		State:                  state,
+	}, nil
+}
+
+// Values returns the parameters of the response.
+func (r *OIDC4VPResponse) Values() (url.Values, error) {
+	submissionBytes, err := json.Marshal(r.PresentationSubmission)
+	if err != nil {
+		return nil, fmt.Errorf("marshal presentation submission: %w", err)
+	}
+
+	values := url.Values{}
+
+	values.Set(oidc4vpVPToken, r.VPToken)
+	values.Set(oidc4vpPresentationSubmission, string(submissionBytes))
+	setValue(values, oidc4vpState, r.State)
+
+	return values, nil
+}
+
+// ParseOIDC4VPResponse parses the parameters of an OpenID for Verifiable Presentations authorization response.
+func ParseOIDC4VPResponse(values url.Values) (*OIDC4VPResponse, error) {
+	r := &OIDC4VPResponse{
+		VPToken: values.Get(oidc4vpVPToken),
+		State:   values.Get(oidc4vpState),
+	}
+
+	submission := values.Get(oidc4vpPresentationSubmission)
+
+	if r.VPToken == "" || submission == "" {
+		return nil, fmt.Errorf("%s and %s are mandatory", oidc4vpVPToken, oidc4vpPresentationSubmission)
+	}
+
+	r.PresentationSubmission = &PresentationSubmission{}
+
+	if err := json.Unmarshal([]byte(submission), r.PresentationSubmission); err != nil {
+		return nil, fmt.Errorf("unmarshal presentation submission: %w", err)
+	}
+
+	return r, nil
+}
+
+// Presentation parses and verifies the verifiable presentation of the response to the request. The response
+// must carry the state of the request, and the presentation must be bound to the nonce of the request: it is the
+// nonce claim of a JWS presentation, or the challenge of every proof of a linked data presentation. Unsecured JWT
+// presentations are rejected, as anyone can forge them.
+// The presentation submission of the response is attached to the parsed presentation afterwards, so that it can
+// be matched against the presentation definition (see Match) the same way as a presentation received with
+// DIDComm present proof.
+func (r *OIDC4VPResponse) Presentation(req *OIDC4VPRequest,
+	opts ...verifiable.PresentationOpt) (*verifiable.Presentation, error) {
+	if req == nil || req.Nonce == "" {
+		return nil, errors.New("request with nonce is mandatory")
+	}
+
+	if r.State != req.State {
+		return nil, fmt.Errorf("state %q of the response doesn't match the request", r.State)
+	}
+
+	if jwt.IsJWTUnsecured(strings.TrimSpace(r.VPToken)) {
+		return nil, errors.New("unsecured vp token is not accepted")
+	}
+
+	vp, err := verifiable.ParsePresentation([]byte(r.VPToken), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("parse vp token: %w", err)
+	}
+
+	if err = checkNonce(r.VPToken, vp, req.Nonce); err != nil {
+		return nil, err
+	}
+
+	submissionBytes, err := json.Marshal(r.PresentationSubmission)
+	if err != nil {
+		return nil, fmt.Errorf("marshal presentation submission: %w", err)
+	}
+
+	var submission map[string]interface{}
+
+	if err = json.Unmarshal(submissionBytes, &submission); err != nil {
+		return nil, fmt.Errorf("unmarshal presentation submission: %w", err)
+	}
+
+	if vp.CustomFields == nil {
+		vp.CustomFields = make(verifiable.CustomFields)
+	}
+
+	vp.CustomFields[submissionProperty] = submission
+
+	if !stringsContain(vp.Context, PresentationSubmissionJSONLDContext) {
+		vp.Context = append(vp.Context, PresentationSubmissionJSONLDContext)
+	}
+
+	if !stringsContain(vp.Type, PresentationSubmissionJSONLDType) {
+		vp.Type = append(vp.Type, PresentationSubmissionJSONLDType)
+	}
+
+	return vp, nil
+}
+
+// checkNonce checks that the presentation, verified by ParsePresentation, is bound to the nonce.
+func checkNonce(vpToken string, vp *verifiable.Presentation, nonce string) error {
+	vpToken = strings.TrimSpace(vpToken)
+
+	if jwt.IsJWS(vpToken) {
+		// the signature was checked by ParsePresentation
+		token, err := jwt.Parse(vpToken, jwt.WithSignatureVerifier(jose.SignatureVerifierFunc(
+			func(jose.Headers, []byte, []byte, []byte) error {
+				return nil
+			})))
+		if err != nil {
+			return fmt.Errorf("parse vp token: %w", err)
+		}
+
+		if token.Payload[oidc4vpNonce] != nonce {
+			return errors.New("vp token is not bound to the nonce of the request")
+		}
+
+		return nil
+	}
+
+	if len(vp.Proofs) == 0 {
+		return errors.New("vp token is not bound to the nonce of the request")
+	}
+
+	for _, proof := range vp.Proofs {
+		if proof["challenge"] != nonce {
+			return errors.New("vp token is not bound to the nonce of the request")
+		}
+	}
+
+	return nil
+}
+
+func presentationSubmission(vp *verifiable.Presentation) (*PresentationSubmission, error) {
+	submission, ok := vp.CustomFields[submissionProperty]
+	if !ok {
+		return nil, fmt.Errorf("missing '%s' on verifiable presentation", submissionProperty)
+	}
+
+	submissionBytes, err := json.Marshal(submission)
+	if err != nil {
+		return nil, fmt.Errorf("marshal presentation submission: %w", err)
+	}
+
+	var result PresentationSubmission
+
+	if err = json.Unmarshal(submissionBytes, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal presentation submission: %w", err)
+	}
+
+	return &result, nil
+}
+
+func setValue(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
@@ -0,0 +1,392 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presexch
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+func newOIDC4VPDefinition(uri string) *PresentationDefinition {
+	return &PresentationDefinition{
+		ID:      uuid.New().String(),
+		Purpose: "KYC",
+		InputDescriptors: []*InputDescriptor{{
+			ID:     uuid.New().String(),
+			Schema: []*Schema{{URI: uri}},
+		}},
+	}
+}
+
+func TestPresentationDefinition_OIDC4VPRequest(t *testing.T) {
+	pd := newOIDC4VPDefinition(randomURI())
+
+	t.Run("embedded definition", func(t *testing.T) {
+		r, err := pd.OIDC4VPRequest("https://verifier.example.com", "n-0S6_WzA2Mj",
+			WithOIDC4VPRedirectURI("https://verifier.example.com/cb"), WithOIDC4VPResponseMode("direct_post"),
+			WithOIDC4VPScope("openid"), WithOIDC4VPState("af0ifjsldkj"))
+		require.NoError(t, err)
+
+		values, err := r.Values()
+		require.NoError(t, err)
+		require.Equal(t, "vp_token", values.Get("response_type"))
+		require.Equal(t, "https://verifier.example.com/cb", values.Get("redirect_uri"))
+		require.Empty(t, values.Get("presentation_definition_uri"))
+
+		// the parameters survive the authorization URL
+		values, err = url.ParseQuery(values.Encode())
+		require.NoError(t, err)
+
+		parsed, err := ParseOIDC4VPRequest(values)
+		require.NoError(t, err)
+		require.Equal(t, r, parsed)
+		require.Equal(t, pd, parsed.PresentationDefinition)
+	})
+
+	t.Run("referenced definition", func(t *testing.T) {
+		r, err := pd.OIDC4VPRequest("https://verifier.example.com", "n-0S6_WzA2Mj",
+			WithOIDC4VPDefinitionURI("https://verifier.example.com/pd/1"))
+		require.NoError(t, err)
+		require.Nil(t, r.PresentationDefinition)
+
+		values, err := r.Values()
+		require.NoError(t, err)
+		require.Empty(t, values.Get("presentation_definition"))
+
+		parsed, err := ParseOIDC4VPRequest(values)
+		require.NoError(t, err)
+		require.Equal(t, "https://verifier.example.com/pd/1", parsed.PresentationDefinitionURI)
+		require.Nil(t, parsed.PresentationDefinition)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := pd.OIDC4VPRequest("", "n-0S6_WzA2Mj")
+		require.EqualError(t, err, "client ID and nonce are mandatory")
+
+		_, err = (&PresentationDefinition{}).OIDC4VPRequest("https://verifier.example.com", "n-0S6_WzA2Mj")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "presentation definition: ")
+	})
+}
+
+func TestParseOIDC4VPRequest(t *testing.T) {
+	valid := url.Values{
+		"response_type":           {"vp_token id_token"},
+		"client_id":               {"https://verifier.example.com"},
+		"nonce":                   {"n-0S6_WzA2Mj"},
+		"presentation_definition": {`{"id":"32f54163","input_descriptors":[{"id":"bankaccount_input","schema":[{"uri":"https://bank-standards.example.com/fullaccountroute.json"}]}]}`},
+	}
+
+	r, err := ParseOIDC4VPRequest(valid)
+	require.NoError(t, err)
+	require.Equal(t, "32f54163", r.PresentationDefinition.ID)
+
+	tests := []struct {
+		name   string
+		values map[string][]string
+		err    string
+	}{
+		{
+			name:   "response type",
+			values: map[string][]string{"response_type": {"code"}},
+			err:    `response type "code" is not vp_token`,
+		},
+		{
+			name:   "client ID",
+			values: map[string][]string{"client_id": nil},
+			err:    "client ID and nonce are mandatory",
+		},
+		{
+			name:   "both definition and URI",
+			values: map[string][]string{"presentation_definition_uri": {"https://verifier.example.com/pd/1"}},
+			err:    "exactly one of presentation_definition and presentation_definition_uri is required",
+		},
+		{
+			name:   "no definition",
+			values: map[string][]string{"presentation_definition": nil},
+			err:    "exactly one of presentation_definition and presentation_definition_uri is required",
+		},
+		{
+			name:   "invalid JSON",
+			values: map[string][]string{"presentation_definition": {"{"}},
+			err:    "unmarshal presentation definition",
+		},
+		{
+			name:   "invalid definition",
+			values: map[string][]string{"presentation_definition": {`{"id":"32f54163"}`}},
+			err:    "presentation definition: presentation_definition: input_descriptors is required",
+		},
+	}
+
+	for _, tc := range tests {
+		values := url.Values{}
+
+		for k, v := range valid {
+			values[k] = v
+		}
+
+		for k, v := range tc.values {
+			values[k] = v
+		}
+
+		_, err := ParseOIDC4VPRequest(values)
+		require.Error(t, err, tc.name)
+		require.Contains(t, err.Error(), tc.err, tc.name)
+	}
+}
+
+func TestOIDC4VPResponse(t *testing.T) {
+	const (
+		nonce = "n-0S6_WzA2Mj"
+		state = "af0ifjsldkj"
+	)
+
+	uri := randomURI()
+	pd := newOIDC4VPDefinition(uri)
+
+	req, err := pd.OIDC4VPRequest("https://verifier.example.com", nonce, WithOIDC4VPState(state))
+	require.NoError(t, err)
+
+	vc := newVC([]string{uri})
+	vc.Schemas = []verifiable.TypedID{{ID: uri, Type: "ExampleSchema"}}
+
+	vp, err := pd.CreateVP(vc)
+	require.NoError(t, err)
+
+	// the proofs are not checked, only their challenge
+	vp.Proofs = []verifiable.Proof{{"type": "Ed25519Signature2018", "challenge": nonce}}
+
+	loaderOpt := verifiable.WithPresJSONLDDocumentLoader(oidc4vpContextLoader(t, uri))
+
+	t.Run("presentation matches definition", func(t *testing.T) {
+		r, err := NewOIDC4VPResponse(vp, state)
+		require.NoError(t, err)
+		require.Equal(t, pd.ID, r.PresentationSubmission.DefinitionID)
+
+		values, err := r.Values()
+		require.NoError(t, err)
+
+		parsed, err := ParseOIDC4VPResponse(values)
+		require.NoError(t, err)
+		require.Equal(t, r, parsed)
+
+		received, err := parsed.Presentation(req, verifiable.WithPresDisabledProofCheck(), loaderOpt)
+		require.NoError(t, err)
+
+		matched, err := pd.Match(received, WithJSONLDDocumentLoader(jsonldContextLoader(t, uri)))
+		require.NoError(t, err)
+		require.Len(t, matched, 1)
+	})
+
+	t.Run("submission is attached to presentation", func(t *testing.T) {
+		r, err := NewOIDC4VPResponse(vp, state)
+		require.NoError(t, err)
+
+		plainVP := &verifiable.Presentation{
+			Context: []string{CredentialsJSONLDContext},
+			Type:    []string{VerifiablePresentationJSONLDType},
+			Proofs:  vp.Proofs,
+		}
+		require.NoError(t, plainVP.SetCredentials(vp.Credentials()...))
+
+		vpBytes, err := plainVP.MarshalJSON()
+		require.NoError(t, err)
+
+		r.VPToken = string(vpBytes)
+
+		received, err := r.Presentation(req, verifiable.WithPresDisabledProofCheck(), loaderOpt)
+		require.NoError(t, err)
+		require.Contains(t, received.Context, PresentationSubmissionJSONLDContext)
+		require.Contains(t, received.Type, PresentationSubmissionJSONLDType)
+
+		matched, err := pd.Match(received, WithJSONLDDocumentLoader(jsonldContextLoader(t, uri)))
+		require.NoError(t, err)
+		require.Len(t, matched, 1)
+	})
+
+	t.Run("JWS presentation bound to the nonce", func(t *testing.T) {
+		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		keyOpt := verifiable.WithPresPublicKeyFetcher(verifiable.SingleKey(pubKey, "Ed25519VerificationKey2018"))
+
+		r, err := NewOIDC4VPResponse(vp, state)
+		require.NoError(t, err)
+
+		r.VPToken = newSignedVPToken(t, vp, nonce, privKey)
+
+		_, err = r.Presentation(req, keyOpt, loaderOpt)
+		require.NoError(t, err)
+
+		r.VPToken = newSignedVPToken(t, vp, "other nonce", privKey)
+
+		_, err = r.Presentation(req, keyOpt, loaderOpt)
+		require.EqualError(t, err, "vp token is not bound to the nonce of the request")
+
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		r.VPToken = newSignedVPToken(t, vp, nonce, otherKey)
+
+		_, err = r.Presentation(req, keyOpt, loaderOpt)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse vp token")
+	})
+
+	t.Run("unsecured JWT presentation", func(t *testing.T) {
+		r, err := NewOIDC4VPResponse(vp, state)
+		require.NoError(t, err)
+
+		r.VPToken = newUnsecuredVPToken(t, vp, nonce)
+
+		_, err = r.Presentation(req, verifiable.WithPresDisabledProofCheck(), loaderOpt)
+		require.EqualError(t, err, "unsecured vp token is not accepted")
+	})
+
+	t.Run("response doesn't match the request", func(t *testing.T) {
+		r, err := NewOIDC4VPResponse(vp, "other state")
+		require.NoError(t, err)
+
+		_, err = r.Presentation(req, verifiable.WithPresDisabledProofCheck(), loaderOpt)
+		require.EqualError(t, err, `state "other state" of the response doesn't match the request`)
+
+		otherReq, err := pd.OIDC4VPRequest("https://verifier.example.com", "other nonce", WithOIDC4VPState(state))
+		require.NoError(t, err)
+
+		r, err = NewOIDC4VPResponse(vp, state)
+		require.NoError(t, err)
+
+		// a presentation replayed to another request
+		_, err = r.Presentation(otherReq, verifiable.WithPresDisabledProofCheck(), loaderOpt)
+		require.EqualError(t, err, "vp token is not bound to the nonce of the request")
+
+		unboundVP := *vp
+		unboundVP.Proofs = nil
+
+		r, err = NewOIDC4VPResponse(&unboundVP, state)
+		require.NoError(t, err)
+
+		_, err = r.Presentation(req, verifiable.WithPresDisabledProofCheck(), loaderOpt)
+		require.EqualError(t, err, "vp token is not bound to the nonce of the request")
+
+		_, err = r.Presentation(nil, verifiable.WithPresDisabledProofCheck(), loaderOpt)
+		require.EqualError(t, err, "request with nonce is mandatory")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewOIDC4VPResponse(&verifiable.Presentation{}, "")
+		require.EqualError(t, err, "missing 'presentation_submission' on verifiable presentation")
+
+		_, err = ParseOIDC4VPResponse(url.Values{"vp_token": {"{}"}})
+		require.EqualError(t, err, "vp_token and presentation_submission are mandatory")
+
+		_, err = ParseOIDC4VPResponse(url.Values{"vp_token": {"{}"}, "presentation_submission": {"{"}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal presentation submission")
+
+		_, err = (&OIDC4VPResponse{VPToken: "{", PresentationSubmission: &PresentationSubmission{}, State: state}).
+			Presentation(req)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse vp token")
+	})
+}
+
+// newSignedVPToken creates a JWS presentation with the nonce claim, signed with the EdDSA key.
+func newSignedVPToken(t *testing.T, vp *verifiable.Presentation, nonce string, privKey ed25519.PrivateKey) string {
+	t.Helper()
+
+	token, err := jwt.NewSigned(vpTokenPayload(t, vp, nonce), nil, ed25519JWTSigner(privKey))
+	require.NoError(t, err)
+
+	serialized, err := token.Serialize(false)
+	require.NoError(t, err)
+
+	return serialized
+}
+
+// newUnsecuredVPToken creates an unsecured JWT presentation with the nonce claim.
+func newUnsecuredVPToken(t *testing.T, vp *verifiable.Presentation, nonce string) string {
+	t.Helper()
+
+	token, err := jwt.NewUnsecured(vpTokenPayload(t, vp, nonce), nil)
+	require.NoError(t, err)
+
+	serialized, err := token.Serialize(false)
+	require.NoError(t, err)
+
+	return serialized
+}
+
+func vpTokenPayload(t *testing.T, vp *verifiable.Presentation, nonce string) map[string]interface{} {
+	t.Helper()
+
+	claims, err := vp.JWTClaims(nil, false)
+	require.NoError(t, err)
+
+	claimsBytes, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	var payload map[string]interface{}
+
+	require.NoError(t, json.Unmarshal(claimsBytes, &payload))
+
+	payload["iss"] = "did:example:holder"
+	payload["nonce"] = nonce
+
+	return payload
+}
+
+type ed25519JWTSigner ed25519.PrivateKey
+
+func (s ed25519JWTSigner) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(ed25519.PrivateKey(s), data), nil
+}
+
+func (s ed25519JWTSigner) Headers() jose.Headers {
+	return jose.Headers{jose.HeaderAlgorithm: "EdDSA"}
+}
+
+// oidc4vpContextLoader caches the contexts of the presentations of the tests, so that they are parsed offline.
+func oidc4vpContextLoader(t *testing.T, contextURL string) *ld.CachingDocumentLoader {
+	t.Helper()
+
+	const submissionContext = `{
+  "@context": {
+    "@version": 1.1,
+    "PresentationSubmission": {
+      "@id": "https://identity.foundation/presentation-exchange/#presentation-submission",
+      "@context": {
+        "@version": 1.1,
+        "presentation_submission": {
+          "@id": "https://identity.foundation/presentation-exchange/#presentation-submission",
+          "@type": "@json"
+        }
+      }
+    }
+  }
+}`
+
+	reader, err := ld.DocumentFromReader(strings.NewReader(submissionContext))
+	require.NoError(t, err)
+
+	loader := jsonldContextLoader(t, contextURL)
+	loader.AddDocument(PresentationSubmissionJSONLDContext, reader)
+
+	return loader
+}
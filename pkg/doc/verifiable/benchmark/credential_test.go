@@ -0,0 +1,198 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package benchmark
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+)
+
+// stage is a single step of the credential pipeline run once per benchmark iteration.
+type stage func(vcBytes []byte) error
+
+func parseStage() stage {
+	return func(vcBytes []byte) error {
+		_, err := verifiable.ParseUnverifiedCredential(vcBytes)
+
+		return err
+	}
+}
+
+func schemaValidationStage() stage {
+	return func(vcBytes []byte) error {
+		_, err := verifiable.ParseCredential(vcBytes,
+			verifiable.WithDisabledProofCheck(),
+			verifiable.WithNoCustomSchemaCheck(),
+			verifiable.WithBaseContextExtendedValidation(
+				[]string{examplesContext, jwk2020Context},
+				[]string{"UniversityDegreeCredential"}))
+
+		return err
+	}
+}
+
+func canonicalizationStage(loader ld.DocumentLoader) stage {
+	return func(vcBytes []byte) error {
+		var doc map[string]interface{}
+
+		err := json.Unmarshal(vcBytes, &doc)
+		if err != nil {
+			return err
+		}
+
+		_, err = jsonld.Default().GetCanonicalDocument(doc, jsonld.WithDocumentLoader(loader))
+
+		return err
+	}
+}
+
+func signStage(s *signatureSuite, loader ld.DocumentLoader) stage {
+	return func(vcBytes []byte) error {
+		vc, err := verifiable.ParseUnverifiedCredential(vcBytes)
+		if err != nil {
+			return err
+		}
+
+		return vc.AddLinkedDataProof(s.proofContext(), jsonld.WithDocumentLoader(loader))
+	}
+}
+
+func verifyStage(s *signatureSuite, loader ld.DocumentLoader) stage {
+	return func(vcBytes []byte) error {
+		_, err := verifiable.ParseCredential(vcBytes,
+			verifiable.WithNoCustomSchemaCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader),
+			verifiable.WithEmbeddedSignatureSuites(s.suite),
+			verifiable.WithPublicKeyFetcher(s.publicKeyFetcher()))
+
+		return err
+	}
+}
+
+func runStage(b *testing.B, run stage, vcBytes []byte) {
+	b.Helper()
+
+	// fail early instead of measuring an error path
+	require.NoError(b, run(vcBytes))
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(vcBytes)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := run(vcBytes); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, size := range sizes {
+		vcBytes := newCredential(b, size)
+
+		b.Run(size.name, func(b *testing.B) {
+			runStage(b, parseStage(), vcBytes)
+		})
+	}
+}
+
+func BenchmarkSchemaValidation(b *testing.B) {
+	for _, size := range sizes {
+		vcBytes := newCredential(b, size)
+
+		b.Run(size.name, func(b *testing.B) {
+			runStage(b, schemaValidationStage(), vcBytes)
+		})
+	}
+}
+
+func BenchmarkCanonicalization(b *testing.B) {
+	loader := newDocumentLoader(b)
+
+	for _, size := range sizes {
+		vcBytes := newCredential(b, size)
+
+		b.Run(size.name, func(b *testing.B) {
+			runStage(b, canonicalizationStage(loader), vcBytes)
+		})
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	loader := newDocumentLoader(b)
+
+	for _, s := range newSignatureSuites(b) {
+		for _, size := range sizes {
+			vcBytes := newCredential(b, size)
+			s := s
+
+			b.Run(s.name+"/"+size.name, func(b *testing.B) {
+				runStage(b, signStage(s, loader), vcBytes)
+			})
+		}
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	loader := newDocumentLoader(b)
+
+	for _, s := range newSignatureSuites(b) {
+		for _, size := range sizes {
+			vcBytes := newSignedCredential(b, size, s, loader)
+			s := s
+
+			b.Run(s.name+"/"+size.name, func(b *testing.B) {
+				runStage(b, verifyStage(s, loader), vcBytes)
+			})
+		}
+	}
+}
+
+// TestPipeline runs every benchmarked stage once, so broken fixtures are detected by unit tests
+// rather than when benchmarking.
+func TestPipeline(t *testing.T) {
+	loader := newDocumentLoader(t)
+	suites := newSignatureSuites(t)
+
+	for _, size := range sizes {
+		vcBytes := newCredential(t, size)
+
+		t.Run(size.name, func(t *testing.T) {
+			require.NoError(t, parseStage()(vcBytes))
+			require.NoError(t, schemaValidationStage()(vcBytes))
+			require.NoError(t, canonicalizationStage(loader)(vcBytes))
+
+			for _, s := range suites {
+				require.NoError(t, signStage(s, loader)(vcBytes), s.name)
+				require.NoError(t, verifyStage(s, loader)(newSignedCredential(t, size, s, loader)), s.name)
+			}
+		})
+	}
+
+	t.Run("verification of tampered credential fails", func(t *testing.T) {
+		s := suites[0]
+
+		var vc map[string]interface{}
+
+		require.NoError(t, json.Unmarshal(newSignedCredential(t, sizes[0], s, loader), &vc))
+
+		vc["issuanceDate"] = "2011-01-01T19:23:24Z"
+
+		tampered, err := json.Marshal(vc)
+		require.NoError(t, err)
+
+		err = verifyStage(s, loader)(tampered)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "check embedded proof")
+	})
+}
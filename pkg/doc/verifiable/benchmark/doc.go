@@ -0,0 +1,27 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package benchmark contains benchmarks of the Verifiable Credential pipeline: parsing, JSON Schema validation,
+// JSON-LD canonicalization, signing and verification of linked data proofs. Each stage is measured for small,
+// medium and large credentials and, where signatures are involved, for every benchmarked signature suite.
+//
+// All benchmarks report allocations and use only preloaded JSON-LD contexts, so the results do not depend on
+// network access. Run them with:
+//
+//	make benchmark
+//
+// which writes results along with CPU and memory profiles to build/benchmark. BENCH, BENCH_COUNT and
+// BENCH_OUTPUT_DIR environment variables select the benchmarks, number of runs and output directory.
+//
+// Profiles of a single stage can be collected by running go test directly:
+//
+//	go test -run=^$ -bench=Verify/large -benchmem -cpuprofile=cpu.out -memprofile=mem.out \
+//		./pkg/doc/verifiable/benchmark
+//	go tool pprof -http=:8080 cpu.out
+//
+// To detect regressions, save results of the base and changed revisions (BENCH_COUNT=10) and compare them
+// using benchstat.
+package benchmark
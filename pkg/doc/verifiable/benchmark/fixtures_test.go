@@ -0,0 +1,209 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/signer"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/jsonwebsignature2020"
+	sigverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util/signature"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const (
+	jsonldContextPrefix = "../testdata/context"
+
+	examplesContext  = "https://www.w3.org/2018/credentials/examples/v1"
+	jwk2020Context   = "https://trustbloc.github.io/context/vc/credentials-v1.jsonld"
+	verificationKey  = "did:example:76e12ec712ebc6f1c221ebfeb1f#key1"
+	credentialIssuer = "did:example:76e12ec712ebc6f1c221ebfeb1f"
+)
+
+// credentialSize defines the size of a generated credential by the number of degrees of its subject.
+type credentialSize struct {
+	name    string
+	degrees int
+}
+
+// nolint:gochecknoglobals
+var sizes = []credentialSize{
+	{name: "small", degrees: 1},
+	{name: "medium", degrees: 25},
+	{name: "large", degrees: 250},
+}
+
+// ldSuite is a linked data signature suite able to both sign and verify.
+type ldSuite interface {
+	signer.SignatureSuite
+	sigverifier.SignatureSuite
+}
+
+// signatureSuite is a signature suite under benchmark together with a key to sign and verify with.
+type signatureSuite struct {
+	name                    string
+	signatureType           string
+	signatureRepresentation verifiable.SignatureRepresentation
+	suite                   ldSuite
+	publicKey               *sigverifier.PublicKey
+}
+
+func (s *signatureSuite) proofContext() *verifiable.LinkedDataProofContext {
+	return &verifiable.LinkedDataProofContext{
+		SignatureType:           s.signatureType,
+		SignatureRepresentation: s.signatureRepresentation,
+		Suite:                   s.suite,
+		VerificationMethod:      verificationKey,
+	}
+}
+
+func (s *signatureSuite) publicKeyFetcher() verifiable.PublicKeyFetcher {
+	return func(issuerID, keyID string) (*sigverifier.PublicKey, error) {
+		return s.publicKey, nil
+	}
+}
+
+func newSignatureSuites(tb testing.TB) []*signatureSuite {
+	tb.Helper()
+
+	edSigner, err := signature.NewSigner(kms.ED25519Type)
+	require.NoError(tb, err)
+
+	ecSigner, err := signature.NewSigner(kms.ECDSAP256TypeIEEEP1363)
+	require.NoError(tb, err)
+
+	ecJWK, err := jose.JWKFromPublicKey(ecSigner.PublicKey())
+	require.NoError(tb, err)
+
+	return []*signatureSuite{
+		{
+			name:                    "Ed25519Signature2018",
+			signatureType:           "Ed25519Signature2018",
+			signatureRepresentation: verifiable.SignatureProofValue,
+			suite: ed25519signature2018.New(
+				suite.WithSigner(edSigner),
+				suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier())),
+			publicKey: &sigverifier.PublicKey{
+				Type:  kms.ED25519,
+				Value: edSigner.PublicKeyBytes(),
+			},
+		},
+		{
+			name:                    "JsonWebSignature2020-P256",
+			signatureType:           "JsonWebSignature2020",
+			signatureRepresentation: verifiable.SignatureJWS,
+			suite: jsonwebsignature2020.New(
+				suite.WithSigner(ecSigner),
+				suite.WithVerifier(jsonwebsignature2020.NewPublicKeyVerifier())),
+			publicKey: &sigverifier.PublicKey{
+				Type:  "JwsVerificationKey2020",
+				Value: ecSigner.PublicKeyBytes(),
+				JWK:   ecJWK,
+			},
+		},
+	}
+}
+
+// newCredential generates a university degree credential whose subject holds the given number of degrees.
+func newCredential(tb testing.TB, size credentialSize) []byte {
+	tb.Helper()
+
+	degrees := make([]interface{}, size.degrees)
+
+	for i := range degrees {
+		degrees[i] = map[string]interface{}{
+			"type":         "BachelorDegree",
+			"degreeType":   fmt.Sprintf("Bachelor of Science and Arts #%d", i),
+			"degreeSchool": fmt.Sprintf("School of Engineering #%d", i),
+			"college":      "Example University",
+		}
+	}
+
+	vc := map[string]interface{}{
+		"@context": []interface{}{
+			"https://www.w3.org/2018/credentials/v1",
+			examplesContext,
+			jwk2020Context,
+		},
+		"id":   "http://example.edu/credentials/1872",
+		"type": []interface{}{"VerifiableCredential", "UniversityDegreeCredential"},
+		"credentialSubject": map[string]interface{}{
+			"id":         "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"givenName":  "Jayden",
+			"familyName": "Doe",
+			"degree":     degrees,
+		},
+		"issuer": map[string]interface{}{
+			"id":   credentialIssuer,
+			"name": "Example University",
+		},
+		"issuanceDate":   "2010-01-01T19:23:24Z",
+		"expirationDate": "2030-01-01T19:23:24Z",
+	}
+
+	vcBytes, err := json.Marshal(vc)
+	require.NoError(tb, err)
+
+	return vcBytes
+}
+
+// newDocumentLoader creates JSON-LD document loader with all contexts used by generated credentials and
+// signature suites preloaded, so no network access is made while benchmarking.
+func newDocumentLoader(tb testing.TB) *ld.CachingDocumentLoader {
+	tb.Helper()
+
+	loader := verifiable.CachingJSONLDLoader()
+
+	contexts := map[string]string{
+		examplesContext:                     "vc_example.jsonld",
+		jwk2020Context:                      "trustbloc_jwk2020_example.jsonld",
+		"https://www.w3.org/ns/odrl.jsonld": "odrl.jsonld",
+		"https://w3id.org/security/v1":      "security_v1.jsonld",
+		"https://w3id.org/security/v2":      "security_v2.jsonld",
+	}
+
+	for contextURL, contextFile := range contexts {
+		content, err := ioutil.ReadFile(filepath.Clean(filepath.Join(jsonldContextPrefix, contextFile)))
+		require.NoError(tb, err)
+
+		doc, err := ld.DocumentFromReader(strings.NewReader(string(content)))
+		require.NoError(tb, err)
+
+		loader.AddDocument(contextURL, doc)
+	}
+
+	return loader
+}
+
+// newSignedCredential generates a credential of the given size secured with a linked data proof of the given suite.
+func newSignedCredential(tb testing.TB, size credentialSize, s *signatureSuite, loader ld.DocumentLoader) []byte {
+	tb.Helper()
+
+	vc, err := verifiable.ParseUnverifiedCredential(newCredential(tb, size))
+	require.NoError(tb, err)
+
+	require.NoError(tb, vc.AddLinkedDataProof(s.proofContext(), jsonld.WithDocumentLoader(loader)))
+
+	vcBytes, err := vc.MarshalJSON()
+	require.NoError(tb, err)
+
+	return vcBytes
+}
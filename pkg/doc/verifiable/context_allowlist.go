@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+// ErrContextNotAllowed is returned when a credential references a JSON-LD context which is not allowed.
+var ErrContextNotAllowed = errors.New("@context is not allowed")
+
+// ContextAllowList restricts the JSON-LD contexts which can be referenced by credentials. Entries are
+// context URLs where "*" matches any sequence of characters, e.g. "https://w3id.org/citizenship/*".
+// The base context of Verifiable Credentials is always allowed.
+type ContextAllowList struct {
+	patterns []*regexp.Regexp
+}
+
+// NewContextAllowList creates an allow-list of the given context URL patterns.
+func NewContextAllowList(patterns ...string) *ContextAllowList {
+	l := &ContextAllowList{}
+
+	for _, p := range append([]string{baseContext}, patterns...) {
+		expr := strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+
+		l.patterns = append(l.patterns, regexp.MustCompile("^"+expr+"$"))
+	}
+
+	return l
+}
+
+// Allowed checks if the context URL is allowed.
+func (l *ContextAllowList) Allowed(context string) bool {
+	for _, p := range l.patterns {
+		if p.MatchString(context) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check checks that all contexts referenced by URL in the "@context" of the JSON document are allowed.
+// Embedded contexts are not checked, as they are not fetched; contexts they import are rejected by
+// the document loader of the allow-list.
+func (l *ContextAllowList) Check(docBytes []byte) error {
+	var doc struct {
+		Context interface{} `json:"@context"`
+	}
+
+	if err := json.Unmarshal(docBytes, &doc); err != nil {
+		return fmt.Errorf("check @context: %w", err)
+	}
+
+	contexts := []interface{}{doc.Context}
+
+	if c, ok := doc.Context.([]interface{}); ok {
+		contexts = c
+	}
+
+	for _, c := range contexts {
+		if url, ok := c.(string); ok && !l.Allowed(url) {
+			return fmt.Errorf("%w: %s", ErrContextNotAllowed, url)
+		}
+	}
+
+	return nil
+}
+
+// DocumentLoader wraps the JSON-LD document loader, so that only allowed contexts are loaded.
+func (l *ContextAllowList) DocumentLoader(loader ld.DocumentLoader) ld.DocumentLoader {
+	return &allowListDocumentLoader{allowList: l, next: loader}
+}
+
+type allowListDocumentLoader struct {
+	allowList *ContextAllowList
+	next      ld.DocumentLoader
+}
+
+func (l *allowListDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	if !l.allowList.Allowed(u) {
+		return nil, fmt.Errorf("%w: %s", ErrContextNotAllowed, u)
+	}
+
+	return l.next.LoadDocument(u)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+)
+
+// recordingLoader records the documents it is asked to load.
+type recordingLoader struct {
+	loaded []string
+}
+
+func (l *recordingLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	l.loaded = append(l.loaded, u)
+
+	return &ld.RemoteDocument{DocumentURL: u, Document: map[string]interface{}{}}, nil
+}
+
+func TestContextAllowList_Allowed(t *testing.T) {
+	l := NewContextAllowList("https://w3id.org/citizenship/*", "https://www.w3.org/2018/credentials/examples/v1")
+
+	for _, c := range []string{
+		"https://www.w3.org/2018/credentials/v1",
+		"https://www.w3.org/2018/credentials/examples/v1",
+		"https://w3id.org/citizenship/v1",
+		"https://w3id.org/citizenship/v2/extra",
+	} {
+		require.True(t, l.Allowed(c), c)
+	}
+
+	for _, c := range []string{
+		"https://www.w3.org/2018/credentials/examples/v2",
+		"https://w3id.org/citizenship",
+		"https://w3id.org/citizenshipXv1",
+		"https://evil.example.com/https://w3id.org/citizenship/v1",
+		"",
+	} {
+		require.False(t, l.Allowed(c), c)
+	}
+
+	require.True(t, NewContextAllowList("*").Allowed("https://example.com/any"))
+}
+
+func TestContextAllowList_Check(t *testing.T) {
+	l := NewContextAllowList("https://w3id.org/citizenship/*")
+
+	require.NoError(t, l.Check([]byte(`{"@context": "https://www.w3.org/2018/credentials/v1"}`)))
+	require.NoError(t, l.Check([]byte(`{"@context": ["https://www.w3.org/2018/credentials/v1",
+		{"name": "https://schema.org/name"}, "https://w3id.org/citizenship/v1"]}`)))
+	require.NoError(t, l.Check([]byte(`{}`)))
+
+	err := l.Check([]byte(`{"@context": ["https://www.w3.org/2018/credentials/v1",
+		{"name": "https://schema.org/name"}, "https://example.com/unknown/v1"]}`))
+	require.True(t, errors.Is(err, ErrContextNotAllowed))
+	require.EqualError(t, err, "@context is not allowed: https://example.com/unknown/v1")
+
+	err = l.Check([]byte(`{`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "check @context")
+}
+
+func TestContextAllowList_DocumentLoader(t *testing.T) {
+	next := &recordingLoader{}
+	loader := NewContextAllowList("https://w3id.org/citizenship/*").DocumentLoader(next)
+
+	_, err := loader.LoadDocument("https://w3id.org/citizenship/v1")
+	require.NoError(t, err)
+
+	_, err = loader.LoadDocument("https://example.com/imported/v1")
+	require.True(t, errors.Is(err, ErrContextNotAllowed))
+	require.Equal(t, []string{"https://w3id.org/citizenship/v1"}, next.loaded)
+}
+
+func TestParseCredentialWithContextAllowList(t *testing.T) {
+	vcJSON := `{
+		"@context": ["https://www.w3.org/2018/credentials/v1", "https://example.com/unknown/v1"],
+		"id": "http://example.edu/credentials/1872",
+		"type": "VerifiableCredential",
+		"credentialSubject": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+		"issuanceDate": "2010-01-01T19:23:24Z",
+		"proof": {
+			"type": "Ed25519Signature2018",
+			"created": "2020-01-01T00:00:00Z",
+			"proofPurpose": "assertionMethod",
+			"verificationMethod": "did:example:76e12ec712ebc6f1c221ebfeb1f#key-1",
+			"jws": "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..c2lnbmF0dXJl"
+		}
+	}`
+
+	t.Run("test unknown context rejected before any fetch", func(t *testing.T) {
+		loader := &recordingLoader{}
+
+		_, err := ParseCredential([]byte(vcJSON),
+			WithContextAllowList("https://w3id.org/citizenship/*"),
+			WithJSONLDDocumentLoader(loader),
+			WithPublicKeyFetcher(func(issuerID, keyID string) (*verifier.PublicKey, error) {
+				t.Fatal("proof must not be checked")
+
+				return nil, nil
+			}))
+		require.True(t, errors.Is(err, ErrContextNotAllowed))
+		require.Empty(t, loader.loaded)
+
+		jwt, err := (&Credential{
+			Context: []string{"https://www.w3.org/2018/credentials/v1", "https://example.com/unknown/v1"},
+			Types:   []string{"VerifiableCredential"},
+			Subject: "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			Issuer:  Issuer{ID: "did:example:76e12ec712ebc6f1c221ebfeb1f"},
+			Issued:  util.NewTime(time.Now()),
+		}).JWTClaims(false)
+		require.NoError(t, err)
+
+		unsecuredJWT, err := jwt.MarshalUnsecuredJWT()
+		require.NoError(t, err)
+
+		_, err = ParseCredential([]byte(unsecuredJWT), WithContextAllowList(), WithJSONLDDocumentLoader(loader))
+		require.True(t, errors.Is(err, ErrContextNotAllowed))
+		require.Empty(t, loader.loaded)
+	})
+
+	t.Run("test allowed context", func(t *testing.T) {
+		vc, err := ParseCredential([]byte(vcJSON),
+			WithContextAllowList("https://example.com/*"),
+			WithDisabledProofCheck(),
+			WithBaseContextExtendedValidation([]string{"https://example.com/unknown/v1"}, nil))
+		require.NoError(t, err)
+		require.Equal(t, "http://example.edu/credentials/1872", vc.ID)
+	})
+}
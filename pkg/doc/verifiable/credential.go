@@ -573,6 +573,10 @@ type credentialOpts struct {
 	disabledProofCheck    bool
 	strictValidation      bool
 	ldpSuites             []verifier.SignatureSuite
+	contextAllowList      *ContextAllowList
+	proofThreshold        *ProofThreshold
+	postVerificationHooks []PostVerificationHook
+	riskThreshold         *float64
 
 	jsonldCredentialOpts
 }
@@ -656,6 +660,15 @@ func WithJSONLDDocumentLoader(documentLoader ld.DocumentLoader) CredentialOpt {
 	}
 }
 
+// WithContextAllowList restricts the JSON-LD contexts the VC can reference to the given URL patterns,
+// where "*" matches any sequence of characters (see ContextAllowList). A VC referencing other contexts
+// is rejected before its proof is checked, so that unknown contexts are never fetched.
+func WithContextAllowList(patterns ...string) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.contextAllowList = NewContextAllowList(patterns...)
+	}
+}
+
 // WithStrictValidation enabled strict validation of VC.
 //
 // In case of JSON Schema validation, additionalProperties=true is set on the schema.
@@ -783,6 +796,34 @@ func ParseCredential(vcData []byte, opts ...CredentialOpt) (*Credential, error)
 	// Apply options.
 	vcOpts := getCredentialOpts(opts)
 
+	if err := checkPostVerificationOpts(vcOpts); err != nil {
+		return nil, err
+	}
+
+	vc, err := parseCredential(vcData, vcOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vcOpts.postVerificationHooks) == 0 {
+		return vc, nil
+	}
+
+	result := &VerificationResult{Checks: verificationChecks(vc, vcData, vcOpts)}
+
+	err = evaluatePostVerificationHooks(vc, result, vcOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Verified {
+		return nil, fmt.Errorf("%w: %s", ErrCredentialRejected, result.Error)
+	}
+
+	return vc, nil
+}
+
+func parseCredential(vcData []byte, vcOpts *credentialOpts) (*Credential, error) {
 	// Decode credential (e.g. from JWT).
 	vcDataDecoded, err := decodeRaw(vcData, vcOpts)
 	if err != nil {
@@ -1037,12 +1078,16 @@ func decodeRaw(vcData []byte, vcOpts *credentialOpts) ([]byte, error) {
 			return nil, errors.New("public key fetcher is not defined")
 		}
 
+		if vcOpts.proofThreshold != nil && !vcOpts.disabledProofCheck {
+			return nil, errors.New("proof threshold requires linked data proofs")
+		}
+
 		vcDecodedBytes, err := decodeCredJWS(vcStr, !vcOpts.disabledProofCheck, vcOpts.publicKeyFetcher)
 		if err != nil {
 			return nil, fmt.Errorf("JWS decoding: %w", err)
 		}
 
-		return vcDecodedBytes, nil
+		return vcDecodedBytes, checkAllowedContexts(vcDecodedBytes, vcOpts)
 	}
 
 	if jwt.IsJWTUnsecured(vcStr) { // Embedded proof.
@@ -1051,18 +1096,31 @@ func decodeRaw(vcData []byte, vcOpts *credentialOpts) ([]byte, error) {
 			return nil, fmt.Errorf("unsecured JWT decoding: %w", err)
 		}
 
-		return checkEmbeddedProof(vcDecodedBytes, getEmbeddedProofCheckOpts(vcOpts))
+		vcData = vcDecodedBytes
+	}
+
+	if err := checkAllowedContexts(vcData, vcOpts); err != nil {
+		return nil, err
 	}
 
 	// Embedded proof.
 	return checkEmbeddedProof(vcData, getEmbeddedProofCheckOpts(vcOpts))
 }
 
+func checkAllowedContexts(vcData []byte, vcOpts *credentialOpts) error {
+	if vcOpts.contextAllowList == nil {
+		return nil
+	}
+
+	return vcOpts.contextAllowList.Check(vcData)
+}
+
 func getEmbeddedProofCheckOpts(vcOpts *credentialOpts) *embeddedProofCheckOpts {
 	return &embeddedProofCheckOpts{
 		publicKeyFetcher:     vcOpts.publicKeyFetcher,
 		disabledProofCheck:   vcOpts.disabledProofCheck,
 		ldpSuites:            vcOpts.ldpSuites,
+		proofThreshold:       vcOpts.proofThreshold,
 		jsonldCredentialOpts: vcOpts.jsonldCredentialOpts,
 	}
 }
@@ -1084,6 +1142,10 @@ func getCredentialOpts(opts []CredentialOpt) *credentialOpts {
 		crOpts.jsonldDocumentLoader = CachingJSONLDLoader()
 	}
 
+	if crOpts.contextAllowList != nil {
+		crOpts.jsonldDocumentLoader = crOpts.contextAllowList.DocumentLoader(crOpts.jsonldDocumentLoader)
+	}
+
 	return crOpts
 }
 
@@ -0,0 +1,374 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ClaimChangeType defines how a claim has changed between an original and a re-issued credential.
+type ClaimChangeType string
+
+const (
+	// ClaimAdded is a claim present in the re-issued credential only.
+	ClaimAdded ClaimChangeType = "added"
+
+	// ClaimRemoved is a claim present in the original credential only.
+	ClaimRemoved ClaimChangeType = "removed"
+
+	// ClaimModified is a claim with different values in the original and the re-issued credential.
+	ClaimModified ClaimChangeType = "modified"
+)
+
+const subjectPath = "/credentialSubject"
+
+// ClaimChange describes a single change between an original and a re-issued credential. The old value of added
+// claims and the new value of removed claims are null.
+type ClaimChange struct {
+	// Path is a JSON Pointer (RFC 6901) to the changed claim, e.g. "/credentialSubject/degree/type".
+	Path     string          `json:"path"`
+	Type     ClaimChangeType `json:"type"`
+	OldValue interface{}     `json:"oldValue"`
+	NewValue interface{}     `json:"newValue"`
+}
+
+// CredentialChangeReport is a structured report of what has changed when a credential was re-issued.
+// Changes of the credential ID are reported in OldID and NewID; changes of the order of types and of proofs
+// are not reported.
+type CredentialChangeReport struct {
+	OldID     string        `json:"oldId,omitempty"`
+	NewID     string        `json:"newId,omitempty"`
+	Types     []string      `json:"type"`
+	SubjectID string        `json:"subjectId,omitempty"`
+	Changes   []ClaimChange `json:"changes"`
+}
+
+// HasChanges returns true if any claim of the credential has changed.
+func (r *CredentialChangeReport) HasChanges() bool {
+	return len(r.Changes) > 0
+}
+
+// SubjectChanges returns the changes of credential subject claims only, without changes of credential
+// metadata like issuance or expiration date.
+func (r *CredentialChangeReport) SubjectChanges() []ClaimChange {
+	var changes []ClaimChange
+
+	for _, change := range r.Changes {
+		if change.Path == subjectPath || strings.HasPrefix(change.Path, subjectPath+"/") {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// DiffCredentials computes which claims have changed between the original and the re-issued credential.
+// Both credentials must be of the same types and have the same subject.
+func DiffCredentials(oldVC, newVC *Credential) (*CredentialChangeReport, error) {
+	if !sameTypes(oldVC.Types, newVC.Types) {
+		return nil, fmt.Errorf("credential types differ: %v and %v", oldVC.Types, newVC.Types)
+	}
+
+	// credentials without a single subject ID (e.g. bearer credentials) are compared as is
+	oldSubjectID, _ := SubjectID(oldVC.Subject) // nolint:errcheck
+	newSubjectID, _ := SubjectID(newVC.Subject) // nolint:errcheck
+
+	if oldSubjectID != newSubjectID {
+		return nil, fmt.Errorf("credential subjects differ: '%s' and '%s'", oldSubjectID, newSubjectID)
+	}
+
+	oldDoc, err := credentialDiffDoc(oldVC)
+	if err != nil {
+		return nil, fmt.Errorf("original credential: %w", err)
+	}
+
+	newDoc, err := credentialDiffDoc(newVC)
+	if err != nil {
+		return nil, fmt.Errorf("re-issued credential: %w", err)
+	}
+
+	report := &CredentialChangeReport{
+		OldID:     oldVC.ID,
+		NewID:     newVC.ID,
+		Types:     newVC.Types,
+		SubjectID: newSubjectID,
+		Changes:   []ClaimChange{},
+	}
+
+	diffClaims("", oldDoc, newDoc, &report.Changes)
+
+	return report, nil
+}
+
+// PatchCredential applies the changes of the report (a changed ID and claim changes) to the credential and returns
+// the patched credential. Proofs of the credential are dropped as they do not secure patched claims.
+func PatchCredential(vc *Credential, report *CredentialChangeReport) (*Credential, error) {
+	vcBytes, err := vc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var vcMap map[string]interface{}
+
+	err = json.Unmarshal(vcBytes, &vcMap)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal credential: %w", err)
+	}
+
+	delete(vcMap, "proof")
+
+	if report.NewID != report.OldID {
+		if report.NewID == "" {
+			delete(vcMap, "id")
+		} else {
+			vcMap["id"] = report.NewID
+		}
+	}
+
+	var doc interface{} = vcMap
+
+	for i := range report.Changes {
+		change := report.Changes[i]
+
+		tokens, e := parsePointer(change.Path)
+		if e != nil {
+			return nil, e
+		}
+
+		doc, e = applyClaimChange(doc, tokens, &change)
+		if e != nil {
+			return nil, fmt.Errorf("apply %s change of '%s': %w", change.Type, change.Path, e)
+		}
+	}
+
+	patchedBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patched credential: %w", err)
+	}
+
+	patched, err := ParseUnverifiedCredential(patchedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse patched credential: %w", err)
+	}
+
+	return patched, nil
+}
+
+func credentialDiffDoc(vc *Credential) (map[string]interface{}, error) {
+	vcBytes, err := vc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+
+	err = json.Unmarshal(vcBytes, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal credential: %w", err)
+	}
+
+	// types are compared as a set and reported separately
+	delete(doc, "id")
+	delete(doc, "type")
+	delete(doc, "proof")
+
+	return doc, nil
+}
+
+func sameTypes(types1, types2 []string) bool {
+	if len(types1) != len(types2) {
+		return false
+	}
+
+	set := make(map[string]bool, len(types1))
+	for _, t := range types1 {
+		set[t] = true
+	}
+
+	for _, t := range types2 {
+		if !set[t] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func diffClaims(path string, oldValue, newValue interface{}, changes *[]ClaimChange) {
+	switch oldTyped := oldValue.(type) {
+	case map[string]interface{}:
+		if newTyped, ok := newValue.(map[string]interface{}); ok {
+			diffObjects(path, oldTyped, newTyped, changes)
+
+			return
+		}
+
+	case []interface{}:
+		if newTyped, ok := newValue.([]interface{}); ok {
+			diffArrays(path, oldTyped, newTyped, changes)
+
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, ClaimChange{Path: path, Type: ClaimModified, OldValue: oldValue, NewValue: newValue})
+	}
+}
+
+func diffObjects(path string, oldObj, newObj map[string]interface{}, changes *[]ClaimChange) {
+	keys := make([]string, 0, len(oldObj)+len(newObj))
+
+	for k := range oldObj {
+		keys = append(keys, k)
+	}
+
+	for k := range newObj {
+		if _, ok := oldObj[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		claimPath := path + "/" + escapePointerToken(k)
+		oldValue, inOld := oldObj[k]
+		newValue, inNew := newObj[k]
+
+		switch {
+		case !inNew:
+			*changes = append(*changes, ClaimChange{Path: claimPath, Type: ClaimRemoved, OldValue: oldValue})
+		case !inOld:
+			*changes = append(*changes, ClaimChange{Path: claimPath, Type: ClaimAdded, NewValue: newValue})
+		default:
+			diffClaims(claimPath, oldValue, newValue, changes)
+		}
+	}
+}
+
+func diffArrays(path string, oldArr, newArr []interface{}, changes *[]ClaimChange) {
+	common := len(oldArr)
+	if len(newArr) < common {
+		common = len(newArr)
+	}
+
+	for i := 0; i < common; i++ {
+		diffClaims(path+"/"+strconv.Itoa(i), oldArr[i], newArr[i], changes)
+	}
+
+	for i := common; i < len(newArr); i++ {
+		*changes = append(*changes, ClaimChange{Path: path + "/" + strconv.Itoa(i), Type: ClaimAdded, NewValue: newArr[i]})
+	}
+
+	// removed items are reported from the end, so the changes can be applied one by one
+	for i := len(oldArr) - 1; i >= common; i-- {
+		*changes = append(*changes, ClaimChange{Path: path + "/" + strconv.Itoa(i), Type: ClaimRemoved, OldValue: oldArr[i]})
+	}
+}
+
+// applyClaimChange applies the change to the value at the given path of the node and returns the updated node.
+func applyClaimChange(node interface{}, tokens []string, change *ClaimChange) (interface{}, error) {
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		return applyToObject(typed, tokens, change)
+	case []interface{}:
+		return applyToArray(typed, tokens, change)
+	default:
+		return nil, fmt.Errorf("path token '%s' points into a scalar value", tokens[0])
+	}
+}
+
+func applyToObject(obj map[string]interface{}, tokens []string, change *ClaimChange) (interface{}, error) {
+	key := tokens[0]
+	value, exists := obj[key]
+
+	if len(tokens) > 1 {
+		if !exists {
+			return nil, fmt.Errorf("claim '%s' not found", key)
+		}
+
+		updated, err := applyClaimChange(value, tokens[1:], change)
+		if err != nil {
+			return nil, err
+		}
+
+		obj[key] = updated
+
+		return obj, nil
+	}
+
+	switch change.Type {
+	case ClaimAdded, ClaimModified:
+		obj[key] = change.NewValue
+	case ClaimRemoved:
+		if !exists {
+			return nil, fmt.Errorf("claim '%s' not found", key)
+		}
+
+		delete(obj, key)
+	default:
+		return nil, fmt.Errorf("unsupported change type '%s'", change.Type)
+	}
+
+	return obj, nil
+}
+
+func applyToArray(arr []interface{}, tokens []string, change *ClaimChange) (interface{}, error) {
+	i, err := strconv.Atoi(tokens[0])
+	if err != nil || i < 0 || i > len(arr) || (i == len(arr) && (len(tokens) > 1 || change.Type != ClaimAdded)) {
+		return nil, fmt.Errorf("invalid array index '%s'", tokens[0])
+	}
+
+	if len(tokens) > 1 {
+		arr[i], err = applyClaimChange(arr[i], tokens[1:], change)
+		if err != nil {
+			return nil, err
+		}
+
+		return arr, nil
+	}
+
+	switch change.Type {
+	case ClaimAdded:
+		arr = append(arr, nil)
+		copy(arr[i+1:], arr[i:])
+		arr[i] = change.NewValue
+	case ClaimModified:
+		arr[i] = change.NewValue
+	case ClaimRemoved:
+		arr = append(arr[:i], arr[i+1:]...)
+	default:
+		return nil, fmt.Errorf("unsupported change type '%s'", change.Type)
+	}
+
+	return arr, nil
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func parsePointer(path string) ([]string, error) {
+	// "" points to the whole credential, which can't be changed as a claim; "/" points to the claim named ""
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.New("claim path must be a non-root JSON pointer")
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,329 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:lll
+const originalDegreeCredential = `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1",
+    "https://www.w3.org/2018/credentials/examples/v1"
+  ],
+  "id": "http://example.edu/credentials/1872",
+  "type": ["VerifiableCredential", "UniversityDegreeCredential"],
+  "credentialSubject": {
+    "id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+    "degree": {
+      "type": "BachelorDegree",
+      "name": "Bachelor of Science"
+    },
+    "name": "Jayden Doe",
+    "spouse": "did:example:c276e12ec21ebfeb1f712ebc6f1",
+    "courses": ["math", "physics", "chemistry"]
+  },
+  "issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+  "issuanceDate": "2010-01-01T19:23:24Z",
+  "proof": {
+    "type": "Ed25519Signature2018",
+    "created": "2010-01-01T19:23:24Z",
+    "jws": "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..signature-1",
+    "proofPurpose": "assertionMethod",
+    "verificationMethod": "did:example:76e12ec712ebc6f1c221ebfeb1f#key1"
+  }
+}`
+
+//nolint:lll
+const reissuedDegreeCredential = `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1",
+    "https://www.w3.org/2018/credentials/examples/v1"
+  ],
+  "id": "http://example.edu/credentials/1873",
+  "type": ["UniversityDegreeCredential", "VerifiableCredential"],
+  "credentialSubject": {
+    "id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+    "degree": {
+      "type": "MasterDegree",
+      "name": "Bachelor of Science"
+    },
+    "name": "Jayden Doe",
+    "alumniOf": "Example University",
+    "courses": ["math"]
+  },
+  "issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+  "issuanceDate": "2011-01-01T19:23:24Z",
+  "expirationDate": "2021-01-01T19:23:24Z",
+  "proof": {
+    "type": "Ed25519Signature2018",
+    "created": "2011-01-01T19:23:24Z",
+    "jws": "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..signature-2",
+    "proofPurpose": "assertionMethod",
+    "verificationMethod": "did:example:76e12ec712ebc6f1c221ebfeb1f#key1"
+  }
+}`
+
+func TestDiffCredentials(t *testing.T) {
+	oldVC, err := ParseUnverifiedCredential([]byte(originalDegreeCredential))
+	require.NoError(t, err)
+
+	newVC, err := ParseUnverifiedCredential([]byte(reissuedDegreeCredential))
+	require.NoError(t, err)
+
+	t.Run("test diff of re-issued credential", func(t *testing.T) {
+		report, err := DiffCredentials(oldVC, newVC)
+		require.NoError(t, err)
+		require.True(t, report.HasChanges())
+		require.Equal(t, "http://example.edu/credentials/1872", report.OldID)
+		require.Equal(t, "http://example.edu/credentials/1873", report.NewID)
+		require.Equal(t, "did:example:ebfeb1f712ebc6f1c276e12ec21", report.SubjectID)
+		require.Equal(t, newVC.Types, report.Types)
+
+		require.Equal(t, []ClaimChange{
+			{Path: "/credentialSubject/alumniOf", Type: ClaimAdded, NewValue: "Example University"},
+			{Path: "/credentialSubject/courses/2", Type: ClaimRemoved, OldValue: "chemistry"},
+			{Path: "/credentialSubject/courses/1", Type: ClaimRemoved, OldValue: "physics"},
+			{Path: "/credentialSubject/degree/type", Type: ClaimModified, OldValue: "BachelorDegree", NewValue: "MasterDegree"},
+			{Path: "/credentialSubject/spouse", Type: ClaimRemoved, OldValue: "did:example:c276e12ec21ebfeb1f712ebc6f1"},
+			{Path: "/expirationDate", Type: ClaimAdded, NewValue: "2021-01-01T19:23:24Z"},
+			{Path: "/issuanceDate", Type: ClaimModified, OldValue: "2010-01-01T19:23:24Z", NewValue: "2011-01-01T19:23:24Z"},
+		}, report.Changes)
+
+		require.Len(t, report.SubjectChanges(), 5)
+
+		reportBytes, err := json.Marshal(report)
+		require.NoError(t, err)
+		require.Contains(t, string(reportBytes), `"path":"/credentialSubject/degree/type","type":"modified"`)
+	})
+
+	t.Run("test diff of same credential", func(t *testing.T) {
+		report, err := DiffCredentials(oldVC, oldVC)
+		require.NoError(t, err)
+		require.False(t, report.HasChanges())
+		require.Empty(t, report.SubjectChanges())
+	})
+
+	t.Run("test added array items and escaped claim names", func(t *testing.T) {
+		vc1, err := ParseUnverifiedCredential([]byte(`{
+			"@context": ["https://www.w3.org/2018/credentials/v1"],
+			"type": "VerifiableCredential",
+			"credentialSubject": {"a/b": ["x"], "c~d": 1},
+			"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+			"issuanceDate": "2010-01-01T19:23:24Z"
+		}`))
+		require.NoError(t, err)
+
+		vc2, err := ParseUnverifiedCredential([]byte(`{
+			"@context": ["https://www.w3.org/2018/credentials/v1"],
+			"type": "VerifiableCredential",
+			"credentialSubject": {"a/b": ["x", "y", {"z": true}], "c~d": "1"},
+			"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+			"issuanceDate": "2010-01-01T19:23:24Z"
+		}`))
+		require.NoError(t, err)
+
+		report, err := DiffCredentials(vc1, vc2)
+		require.NoError(t, err)
+		require.Empty(t, report.SubjectID)
+		require.Equal(t, []ClaimChange{
+			{Path: "/credentialSubject/a~1b/1", Type: ClaimAdded, NewValue: "y"},
+			{Path: "/credentialSubject/a~1b/2", Type: ClaimAdded, NewValue: map[string]interface{}{"z": true}},
+			{Path: "/credentialSubject/c~0d", Type: ClaimModified, OldValue: float64(1), NewValue: "1"},
+		}, report.Changes)
+
+		patched, err := PatchCredential(vc1, report)
+		require.NoError(t, err)
+		require.Equal(t, vc2.Subject, patched.Subject)
+	})
+
+	t.Run("test different types", func(t *testing.T) {
+		vc := *newVC
+		vc.Types = []string{"VerifiableCredential"}
+
+		report, err := DiffCredentials(oldVC, &vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "credential types differ")
+		require.Nil(t, report)
+
+		vc.Types = []string{"VerifiableCredential", "DriversLicense"}
+
+		_, err = DiffCredentials(oldVC, &vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "credential types differ")
+	})
+
+	t.Run("test different subjects", func(t *testing.T) {
+		vc := *newVC
+		vc.Subject = "did:example:other"
+
+		report, err := DiffCredentials(oldVC, &vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "credential subjects differ")
+		require.Nil(t, report)
+	})
+
+	t.Run("test marshalling errors", func(t *testing.T) {
+		vc := *newVC
+		vc.CustomFields = CustomFields{"invalid": make(chan int)}
+
+		_, err := DiffCredentials(oldVC, &vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "re-issued credential")
+
+		_, err = DiffCredentials(&vc, newVC)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "original credential")
+
+		_, err = PatchCredential(&vc, &CredentialChangeReport{})
+		require.Error(t, err)
+	})
+}
+
+func TestPatchCredential(t *testing.T) {
+	oldVC, err := ParseUnverifiedCredential([]byte(originalDegreeCredential))
+	require.NoError(t, err)
+
+	newVC, err := ParseUnverifiedCredential([]byte(reissuedDegreeCredential))
+	require.NoError(t, err)
+
+	t.Run("test patch with change report", func(t *testing.T) {
+		report, err := DiffCredentials(oldVC, newVC)
+		require.NoError(t, err)
+
+		patched, err := PatchCredential(oldVC, report)
+		require.NoError(t, err)
+		require.Empty(t, patched.Proofs)
+		require.Equal(t, newVC.ID, patched.ID)
+		require.Equal(t, newVC.Subject, patched.Subject)
+		require.Equal(t, newVC.Issued, patched.Issued)
+		require.Equal(t, newVC.Expired, patched.Expired)
+
+		report, err = DiffCredentials(patched, newVC)
+		require.NoError(t, err)
+		require.False(t, report.HasChanges())
+	})
+
+	t.Run("test patch errors", func(t *testing.T) {
+		tests := []struct {
+			change ClaimChange
+			err    string
+		}{
+			{change: ClaimChange{Path: "credentialSubject", Type: ClaimAdded}, err: "non-root JSON pointer"},
+			{change: ClaimChange{Path: "", Type: ClaimAdded}, err: "non-root JSON pointer"},
+			{change: ClaimChange{Path: "/credentialSubject/unknown", Type: ClaimRemoved}, err: "claim 'unknown' not found"},
+			{change: ClaimChange{Path: "/unknown/name", Type: ClaimModified}, err: "claim 'unknown' not found"},
+			{change: ClaimChange{Path: "/issuer/name", Type: ClaimAdded}, err: "points into a scalar value"},
+			{change: ClaimChange{Path: "/credentialSubject/name", Type: "renamed"}, err: "unsupported change type"},
+			{change: ClaimChange{Path: "/credentialSubject/courses/x", Type: ClaimModified}, err: "invalid array index 'x'"},
+			{change: ClaimChange{Path: "/credentialSubject/courses/3", Type: ClaimModified}, err: "invalid array index '3'"},
+			{change: ClaimChange{Path: "/credentialSubject/courses/4", Type: ClaimAdded}, err: "invalid array index '4'"},
+			{change: ClaimChange{Path: "/credentialSubject/courses/0", Type: "renamed"}, err: "unsupported change type"},
+			{change: ClaimChange{Path: "/credentialSubject/courses/0/x", Type: ClaimAdded}, err: "scalar value"},
+			{change: ClaimChange{Path: "/type", Type: ClaimRemoved}, err: "parse patched credential"},
+		}
+
+		for _, tc := range tests {
+			_, err := PatchCredential(oldVC, &CredentialChangeReport{Changes: []ClaimChange{tc.change}})
+			require.Error(t, err, tc.change.Path)
+			require.Contains(t, err.Error(), tc.err, tc.change.Path)
+		}
+	})
+
+	t.Run("test patch of array items", func(t *testing.T) {
+		patched, err := PatchCredential(oldVC, &CredentialChangeReport{Changes: []ClaimChange{
+			{Path: "/credentialSubject/courses/0", Type: ClaimAdded, NewValue: "biology"},
+			{Path: "/credentialSubject/courses/1", Type: ClaimModified, NewValue: "algebra"},
+			{Path: "/credentialSubject/courses/3", Type: ClaimRemoved},
+		}})
+		require.NoError(t, err)
+
+		subject, ok := patched.Subject.([]Subject)
+		require.True(t, ok)
+		require.Equal(t, []interface{}{"biology", "algebra", "physics"}, subject[0].CustomFields["courses"])
+	})
+	t.Run("test patch of changed ID", func(t *testing.T) {
+		patched, err := PatchCredential(oldVC, &CredentialChangeReport{OldID: oldVC.ID, NewID: oldVC.ID})
+		require.NoError(t, err)
+		require.Equal(t, oldVC.ID, patched.ID)
+
+		patched, err = PatchCredential(oldVC, &CredentialChangeReport{OldID: oldVC.ID})
+		require.NoError(t, err)
+		require.Empty(t, patched.ID)
+
+		patched, err = PatchCredential(patched, &CredentialChangeReport{NewID: newVC.ID})
+		require.NoError(t, err)
+		require.Equal(t, newVC.ID, patched.ID)
+	})
+
+	t.Run("test patch of claim with empty name", func(t *testing.T) {
+		patched, err := PatchCredential(oldVC, &CredentialChangeReport{Changes: []ClaimChange{
+			{Path: "/", Type: ClaimAdded, NewValue: "empty name"},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, "empty name", patched.CustomFields[""])
+
+		report, err := DiffCredentials(oldVC, patched)
+		require.NoError(t, err)
+		require.Equal(t, []ClaimChange{{Path: "/", Type: ClaimAdded, NewValue: "empty name"}}, report.Changes)
+
+		patched, err = PatchCredential(patched, &CredentialChangeReport{Changes: []ClaimChange{
+			{Path: "/", Type: ClaimRemoved},
+		}})
+		require.NoError(t, err)
+		require.NotContains(t, patched.CustomFields, "")
+	})
+
+	t.Run("test round trip of falsy values", func(t *testing.T) {
+		vc := *newVC
+		vc.CustomFields = CustomFields{"active": true, "count": 1, "note": "x", "tags": []interface{}{"a"}}
+
+		falsy := vc
+		falsy.CustomFields = CustomFields{"active": false, "count": 0, "note": "", "tags": []interface{}{}}
+
+		report, err := DiffCredentials(&vc, &falsy)
+		require.NoError(t, err)
+
+		reportBytes, err := json.Marshal(report)
+		require.NoError(t, err)
+
+		var received CredentialChangeReport
+		require.NoError(t, json.Unmarshal(reportBytes, &received))
+		require.Len(t, received.Changes, 4)
+
+		patched, err := PatchCredential(&vc, &received)
+		require.NoError(t, err)
+		require.Equal(t, false, patched.CustomFields["active"])
+		require.Equal(t, float64(0), patched.CustomFields["count"])
+		require.Equal(t, "", patched.CustomFields["note"])
+
+		report, err = DiffCredentials(patched, &falsy)
+		require.NoError(t, err)
+		require.False(t, report.HasChanges())
+
+		// and back
+		report, err = DiffCredentials(&falsy, &vc)
+		require.NoError(t, err)
+
+		reportBytes, err = json.Marshal(report)
+		require.NoError(t, err)
+
+		received = CredentialChangeReport{}
+		require.NoError(t, json.Unmarshal(reportBytes, &received))
+
+		patched, err = PatchCredential(&falsy, &received)
+		require.NoError(t, err)
+
+		report, err = DiffCredentials(patched, &vc)
+		require.NoError(t, err)
+		require.False(t, report.HasChanges())
+	})
+}
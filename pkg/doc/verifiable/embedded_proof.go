@@ -50,6 +50,8 @@ type embeddedProofCheckOpts struct {
 
 	ldpSuites []verifier.SignatureSuite
 
+	proofThreshold *ProofThreshold
+
 	jsonldCredentialOpts
 }
 
@@ -66,6 +68,10 @@ func checkEmbeddedProof(docBytes []byte, opts *embeddedProofCheckOpts) ([]byte,
 
 	proofElement, ok := jsonldDoc["proof"]
 	if !ok || proofElement == nil {
+		if opts.proofThreshold != nil {
+			return nil, errors.New("check embedded proof: proof threshold requires linked data proofs")
+		}
+
 		// do not make a check if there is no proof defined as proof presence is not mandatory
 		return docBytes, nil
 	}
@@ -75,6 +81,10 @@ func checkEmbeddedProof(docBytes []byte, opts *embeddedProofCheckOpts) ([]byte,
 		return nil, fmt.Errorf("check embedded proof: %w", err)
 	}
 
+	if err = checkProofThreshold(proofs, opts.proofThreshold); err != nil {
+		return nil, fmt.Errorf("check embedded proof: %w", err)
+	}
+
 	ldpSuites, err := getSuites(proofs, opts)
 	if err != nil {
 		return nil, err
@@ -100,6 +110,20 @@ func checkEmbeddedProof(docBytes []byte, opts *embeddedProofCheckOpts) ([]byte,
 	return docBytes, nil
 }
 
+func checkProofThreshold(proofs []map[string]interface{}, threshold *ProofThreshold) error {
+	if threshold == nil {
+		return nil
+	}
+
+	proofSet := make([]Proof, len(proofs))
+
+	for i := range proofs {
+		proofSet[i] = proofs[i]
+	}
+
+	return threshold.Check(proofSet)
+}
+
 func getSuites(proofs []map[string]interface{}, opts *embeddedProofCheckOpts) ([]verifier.SignatureSuite, error) {
 	ldpSuites := opts.ldpSuites
 
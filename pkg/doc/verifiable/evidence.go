@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	// register hash functions used by subresource integrity digests.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// ErrDigestMismatch is returned when content of a linked resource doesn't match its digest.
+var ErrDigestMismatch = errors.New("linked resource does not match its digest")
+
+// sriAlgorithms are the hash algorithms of subresource integrity digests ordered by strength
+// (https://www.w3.org/TR/SRI/#cryptographic-hash-functions).
+var sriAlgorithms = []struct { // nolint:gochecknoglobals
+	name string
+	hash crypto.Hash
+}{
+	{name: "sha512", hash: crypto.SHA512},
+	{name: "sha384", hash: crypto.SHA384},
+	{name: "sha256", hash: crypto.SHA256},
+}
+
+// LinkedResource is an external resource (for example a document or an image) referenced by the evidence
+// of a credential together with the digest of its content.
+type LinkedResource struct {
+	// ID is the URL of the resource.
+	ID string
+	// DigestSRI is the subresource integrity digest (https://www.w3.org/TR/SRI/) of the resource content,
+	// e.g. "sha256-..." with base64 encoded hash.
+	DigestSRI string
+	// MediaType is the expected media type of the resource, if set.
+	MediaType string
+}
+
+// LinkedResources returns the resources referenced by the evidence of the credential. Only evidence entries
+// with an "id" and a "digestSRI" are linked resources, as the content of other resources can't be verified.
+func (vc *Credential) LinkedResources() ([]*LinkedResource, error) {
+	var entries []interface{}
+
+	switch e := vc.Evidence.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		entries = e
+	case []map[string]interface{}:
+		for _, entry := range e {
+			entries = append(entries, entry)
+		}
+	default:
+		entries = []interface{}{e}
+	}
+
+	var resources []*LinkedResource
+
+	for i, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("evidence %d is not an object", i)
+		}
+
+		id, _ := fields["id"].(string)            // nolint:errcheck
+		digest, _ := fields["digestSRI"].(string) // nolint:errcheck
+
+		if id == "" || digest == "" {
+			continue
+		}
+
+		mediaType, _ := fields["mediaType"].(string) // nolint:errcheck
+
+		resources = append(resources, &LinkedResource{ID: id, DigestSRI: digest, MediaType: mediaType})
+	}
+
+	return resources, nil
+}
+
+// Verify checks the content of the resource against its digest. Following subresource integrity, only
+// digests of the strongest algorithm present are checked and content matching any of them is accepted.
+func (r *LinkedResource) Verify(content []byte) error {
+	digests := make(map[string][]string)
+
+	for _, token := range strings.Fields(r.DigestSRI) {
+		// options after '?' are reserved and ignored
+		token = strings.SplitN(token, "?", 2)[0] // nolint:gomnd
+
+		parts := strings.SplitN(token, "-", 2) // nolint:gomnd
+		if len(parts) == 2 {                   // nolint:gomnd
+			digests[parts[0]] = append(digests[parts[0]], parts[1])
+		}
+	}
+
+	for _, alg := range sriAlgorithms {
+		expected, ok := digests[alg.name]
+		if !ok {
+			continue
+		}
+
+		h := alg.hash.New()
+		h.Write(content) // nolint:errcheck,gosec
+
+		actual := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+		for _, digest := range expected {
+			if subtle.ConstantTimeCompare([]byte(actual), []byte(digest)) == 1 {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w: %s", ErrDigestMismatch, r.ID)
+	}
+
+	return fmt.Errorf("linked resource %s has no supported digest", r.ID)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sri(alg string, sum []byte) string {
+	return alg + "-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+func TestCredential_LinkedResources(t *testing.T) {
+	t.Run("test evidence array", func(t *testing.T) {
+		vc, err := ParseUnverifiedCredential([]byte(`{
+			"@context": ["https://www.w3.org/2018/credentials/v1"],
+			"type": "VerifiableCredential",
+			"credentialSubject": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+			"issuanceDate": "2010-01-01T19:23:24Z",
+			"evidence": [
+				{
+					"id": "https://example.edu/evidence/transcript.pdf",
+					"type": ["DocumentVerification"],
+					"digestSRI": "sha384-OLBgp1GsljhM2TJ+sbHjaiH9txEUvgdDTAzHv2P24donTt6/529l+9Ua0vFImLlb",
+					"mediaType": "application/pdf"
+				},
+				{
+					"id": "https://example.edu/evidence/f2aeec97-fc0d-42bf-8ca7-0548192d4231",
+					"type": ["DocumentVerification"],
+					"verifier": "https://example.edu/issuers/14"
+				}
+			]
+		}`))
+		require.NoError(t, err)
+
+		resources, err := vc.LinkedResources()
+		require.NoError(t, err)
+		require.Equal(t, []*LinkedResource{{
+			ID:        "https://example.edu/evidence/transcript.pdf",
+			DigestSRI: "sha384-OLBgp1GsljhM2TJ+sbHjaiH9txEUvgdDTAzHv2P24donTt6/529l+9Ua0vFImLlb",
+			MediaType: "application/pdf",
+		}}, resources)
+	})
+
+	t.Run("test single evidence object", func(t *testing.T) {
+		vc := &Credential{Evidence: map[string]interface{}{
+			"id":        "https://example.edu/evidence/photo.jpg",
+			"digestSRI": "sha256-abc",
+		}}
+
+		resources, err := vc.LinkedResources()
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		require.Equal(t, "https://example.edu/evidence/photo.jpg", resources[0].ID)
+
+		vc.Evidence = []map[string]interface{}{
+			{"id": "https://example.edu/evidence/1", "digestSRI": "sha256-abc"},
+			{"id": "https://example.edu/evidence/2", "digestSRI": "sha256-def"},
+		}
+
+		resources, err = vc.LinkedResources()
+		require.NoError(t, err)
+		require.Len(t, resources, 2)
+	})
+
+	t.Run("test no evidence", func(t *testing.T) {
+		resources, err := (&Credential{}).LinkedResources()
+		require.NoError(t, err)
+		require.Empty(t, resources)
+	})
+
+	t.Run("test invalid evidence", func(t *testing.T) {
+		_, err := (&Credential{Evidence: "https://example.edu/evidence/1"}).LinkedResources()
+		require.EqualError(t, err, "evidence 0 is not an object")
+	})
+}
+
+func TestLinkedResource_Verify(t *testing.T) {
+	content := []byte("transcript of records")
+	sum256 := sha256.Sum256(content)
+	sum512 := sha512.Sum512(content)
+	other := sha256.Sum256([]byte("other"))
+
+	tests := []struct {
+		name   string
+		digest string
+		err    error
+	}{
+		{name: "sha256", digest: sri("sha256", sum256[:])},
+		{name: "sha512 with options", digest: sri("sha512", sum512[:]) + "?ct=application/pdf"},
+		{name: "any digest of strongest algorithm", digest: sri("sha256", other[:]) + " " + sri("sha256", sum256[:])},
+		{name: "weaker algorithm ignored", digest: sri("sha256", other[:]) + " " + sri("sha512", sum512[:])},
+		{name: "mismatch", digest: sri("sha256", other[:]), err: ErrDigestMismatch},
+		{name: "strongest algorithm must match", digest: sri("sha256", sum256[:]) + " " + sri("sha512", other[:]),
+			err: ErrDigestMismatch},
+	}
+
+	for _, tc := range tests {
+		err := (&LinkedResource{ID: "https://example.edu/evidence/1", DigestSRI: tc.digest}).Verify(content)
+		if tc.err == nil {
+			require.NoError(t, err, tc.name)
+
+			continue
+		}
+
+		require.True(t, errors.Is(err, tc.err), tc.name)
+		require.Contains(t, err.Error(), "https://example.edu/evidence/1", tc.name)
+	}
+
+	for _, digest := range []string{"md5-1B2M2Y8AsgTpgAmY7PhCfg==", "sha256", ""} {
+		err := (&LinkedResource{ID: "https://example.edu/evidence/1", DigestSRI: digest}).Verify(content)
+		require.EqualError(t, err, "linked resource https://example.edu/evidence/1 has no supported digest", digest)
+	}
+}
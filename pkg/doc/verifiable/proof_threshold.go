@@ -0,0 +1,265 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/ed25519signature2018"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/suite/jsonwebsignature2020"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+// ProofThreshold is an m-of-n policy on the proof set of a credential: the linked data proofs of at least
+// Threshold of the Signers (e.g. the DIDs of a registrar and a notary) are required. The signer of a proof is
+// the DID of its verification method.
+type ProofThreshold struct {
+	Threshold int
+	Signers   []string
+}
+
+// NewProofThreshold creates a policy requiring the proofs of threshold of the signers.
+func NewProofThreshold(threshold int, signers ...string) (*ProofThreshold, error) {
+	t := &ProofThreshold{Threshold: threshold, Signers: signers}
+
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *ProofThreshold) validate() error {
+	if t.Threshold < 1 || t.Threshold > len(t.Signers) {
+		return fmt.Errorf("invalid proof threshold: %d of %d signers", t.Threshold, len(t.Signers))
+	}
+
+	for i, signer := range t.Signers {
+		if signer == "" || stringsContain(t.Signers[:i], signer) {
+			return fmt.Errorf("invalid proof threshold: empty or duplicate signer %q", signer)
+		}
+	}
+
+	return nil
+}
+
+// Signed returns the signers of the policy who made one of the proofs, in the order of the proofs.
+func (t *ProofThreshold) Signed(proofs []Proof) []string {
+	var signed []string
+
+	for _, p := range proofs {
+		signer := proofSigner(p)
+
+		if stringsContain(t.Signers, signer) && !stringsContain(signed, signer) {
+			signed = append(signed, signer)
+		}
+	}
+
+	return signed
+}
+
+// Check checks that at least Threshold of the signers made one of the proofs. Proofs of other signers are not
+// counted. The proofs themselves are not verified.
+func (t *ProofThreshold) Check(proofs []Proof) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+
+	if signed := t.Signed(proofs); len(signed) < t.Threshold {
+		return fmt.Errorf("proof threshold not met: %d of %d required signers", len(signed), t.Threshold)
+	}
+
+	return nil
+}
+
+// WithProofThreshold requires the proof set of the VC to contain the linked data proofs of at least threshold
+// of the signers (see ProofThreshold). It applies only if the proof check is enabled, in which case all the
+// proofs are verified and a VC without linked data proofs (e.g. a JWT VC) is rejected.
+func WithProofThreshold(threshold int, signers ...string) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.proofThreshold = &ProofThreshold{Threshold: threshold, Signers: signers}
+	}
+}
+
+// proofSigner returns the DID of the verification method (or creator) of the proof.
+func proofSigner(p Proof) string {
+	keyID, ok := p["verificationMethod"].(string)
+	if !ok || keyID == "" {
+		keyID, _ = p["creator"].(string) // nolint:errcheck
+	}
+
+	return keyController(keyID)
+}
+
+// keyController returns the DID of the key ID.
+func keyController(keyID string) string {
+	return strings.Split(keyID, "#")[0]
+}
+
+func stringsContain(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ThresholdIssuance coordinates the issuance of a credential signed by several issuers under a proof threshold.
+// It collects the linked data proofs of the signers, either made locally (see Sign and KMSProofContext) or made
+// by each signer on its own copy of the credential (see AddProofs), into the proof set of the credential.
+type ThresholdIssuance struct {
+	vc        *Credential
+	threshold *ProofThreshold
+	mutex     sync.Mutex
+}
+
+// NewThresholdIssuance creates a coordinator of the issuance of the credential under the proof threshold.
+// Proofs already set on the credential are kept.
+func NewThresholdIssuance(vc *Credential, threshold *ProofThreshold) (*ThresholdIssuance, error) {
+	if vc == nil || threshold == nil {
+		return nil, errors.New("credential and proof threshold are mandatory")
+	}
+
+	if err := threshold.validate(); err != nil {
+		return nil, err
+	}
+
+	return &ThresholdIssuance{vc: vc, threshold: threshold}, nil
+}
+
+// Sign adds the linked data proof of one of the signers, whose DID is the one of the verification method
+// of the proof context.
+func (i *ThresholdIssuance) Sign(context *LinkedDataProofContext, jsonldOpts ...jsonld.ProcessorOpts) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if err := i.checkSigner(keyController(context.VerificationMethod)); err != nil {
+		return err
+	}
+
+	return i.vc.AddLinkedDataProof(context, jsonldOpts...)
+}
+
+// AddProofs adds the proofs made by signers on their own copy of the credential, e.g. the Proofs of the copy
+// returned by a remote signer. Proofs already in the proof set are skipped.
+func (i *ThresholdIssuance) AddProofs(proofs ...Proof) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, p := range proofs {
+		if containsProof(i.vc.Proofs, p) {
+			continue
+		}
+
+		if err := i.checkSigner(proofSigner(p)); err != nil {
+			return err
+		}
+
+		i.vc.Proofs = append(i.vc.Proofs, p)
+	}
+
+	return nil
+}
+
+func (i *ThresholdIssuance) checkSigner(signer string) error {
+	if !stringsContain(i.threshold.Signers, signer) {
+		return fmt.Errorf("%q is not a signer of the proof threshold", signer)
+	}
+
+	if stringsContain(i.threshold.Signed(i.vc.Proofs), signer) {
+		return fmt.Errorf("%q already signed", signer)
+	}
+
+	return nil
+}
+
+func containsProof(proofs []Proof, p Proof) bool {
+	for _, e := range proofs {
+		if reflect.DeepEqual(e, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pending returns the signers who didn't sign yet.
+func (i *ThresholdIssuance) Pending() []string {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	signed := i.threshold.Signed(i.vc.Proofs)
+
+	var pending []string
+
+	for _, signer := range i.threshold.Signers {
+		if !stringsContain(signed, signer) {
+			pending = append(pending, signer)
+		}
+	}
+
+	return pending
+}
+
+// Complete returns true if the proofs of the threshold of the signers are collected.
+func (i *ThresholdIssuance) Complete() bool {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.threshold.Check(i.vc.Proofs) == nil
+}
+
+// Credential returns the credential with the collected proofs if the threshold is met.
+func (i *ThresholdIssuance) Credential() (*Credential, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if err := i.threshold.Check(i.vc.Proofs); err != nil {
+		return nil, err
+	}
+
+	return i.vc, nil
+}
+
+// KMSProofContext creates the context of a linked data proof signed with the key of the KMS, of the signature
+// type Ed25519Signature2018 (Ed25519 keys) or JsonWebSignature2020 (Ed25519 or ECDSA keys).
+func KMSProofContext(km kmsapi.KeyManager, crypto cryptoapi.Crypto, keyID, signatureType,
+	verificationMethod string) (*LinkedDataProofContext, error) {
+	kh, err := km.Get(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get key %s: %w", keyID, err)
+	}
+
+	signer := suite.WithSigner(suite.NewCryptoSigner(crypto, kh))
+
+	context := &LinkedDataProofContext{
+		SignatureType:      signatureType,
+		VerificationMethod: verificationMethod,
+	}
+
+	switch signatureType {
+	case ed25519Signature2018:
+		context.Suite = ed25519signature2018.New(signer)
+		context.SignatureRepresentation = SignatureProofValue
+	case jsonWebSignature2020:
+		context.Suite = jsonwebsignature2020.New(signer)
+		context.SignatureRepresentation = SignatureJWS
+	default:
+		return nil, fmt.Errorf("unsupported signature type %s", signatureType)
+	}
+
+	return context, nil
+}
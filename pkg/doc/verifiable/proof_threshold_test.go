@@ -0,0 +1,218 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/jsonld"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+)
+
+const (
+	registrarDID = "did:example:registrar"
+	notaryDID    = "did:example:notary"
+	auditorDID   = "did:example:auditor"
+)
+
+func TestProofThreshold(t *testing.T) {
+	t.Run("invalid threshold", func(t *testing.T) {
+		_, err := NewProofThreshold(0, registrarDID)
+		require.EqualError(t, err, "invalid proof threshold: 0 of 1 signers")
+
+		_, err = NewProofThreshold(3, registrarDID, notaryDID)
+		require.EqualError(t, err, "invalid proof threshold: 3 of 2 signers")
+
+		_, err = NewProofThreshold(1, registrarDID, registrarDID)
+		require.EqualError(t, err, `invalid proof threshold: empty or duplicate signer "did:example:registrar"`)
+
+		err = (&ProofThreshold{Threshold: 1}).Check(nil)
+		require.EqualError(t, err, "invalid proof threshold: 1 of 0 signers")
+	})
+
+	t.Run("check signers", func(t *testing.T) {
+		threshold, err := NewProofThreshold(2, registrarDID, notaryDID, auditorDID)
+		require.NoError(t, err)
+
+		proofs := []Proof{
+			{"verificationMethod": notaryDID + "#key-1"},
+			{"verificationMethod": "did:example:other#key-1"},
+			{"verificationMethod": notaryDID + "#key-2"},
+		}
+
+		require.Equal(t, []string{notaryDID}, threshold.Signed(proofs))
+		require.EqualError(t, threshold.Check(proofs), "proof threshold not met: 1 of 2 required signers")
+
+		proofs = append(proofs, Proof{"creator": registrarDID + "#key-1"})
+
+		require.Equal(t, []string{notaryDID, registrarDID}, threshold.Signed(proofs))
+		require.NoError(t, threshold.Check(proofs))
+	})
+}
+
+func TestThresholdIssuance(t *testing.T) {
+	localKMS, err := createKMS()
+	require.NoError(t, err)
+
+	crypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keys := make(map[string][]byte)
+
+	newProofContext := func(did string) *LinkedDataProofContext {
+		keyID, _, e := localKMS.Create(kmsapi.ED25519Type)
+		require.NoError(t, e)
+
+		keys[did+"#"+keyID], e = localKMS.ExportPubKeyBytes(keyID)
+		require.NoError(t, e)
+
+		context, e := KMSProofContext(localKMS, crypto, keyID, ed25519Signature2018, did+"#"+keyID)
+		require.NoError(t, e)
+
+		return context
+	}
+
+	pubKeyFetcher := func(issuerID, keyID string) (*verifier.PublicKey, error) {
+		key, ok := keys[issuerID+keyID]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %s%s", issuerID, keyID)
+		}
+
+		return &verifier.PublicKey{Type: kmsapi.ED25519, Value: key}, nil
+	}
+
+	loader := jsonld.WithDocumentLoader(createTestJSONLDDocumentLoader())
+
+	threshold, err := NewProofThreshold(2, registrarDID, notaryDID, auditorDID)
+	require.NoError(t, err)
+
+	t.Run("collect proofs of the signers", func(t *testing.T) {
+		vc, err := parseTestCredential([]byte(validCredential))
+		require.NoError(t, err)
+
+		issuance, err := NewThresholdIssuance(vc, threshold)
+		require.NoError(t, err)
+
+		require.NoError(t, issuance.Sign(newProofContext(registrarDID), loader))
+		require.False(t, issuance.Complete())
+		require.Equal(t, []string{notaryDID, auditorDID}, issuance.Pending())
+
+		_, err = issuance.Credential()
+		require.EqualError(t, err, "proof threshold not met: 1 of 2 required signers")
+
+		err = issuance.Sign(newProofContext(registrarDID), loader)
+		require.EqualError(t, err, `"did:example:registrar" already signed`)
+
+		err = issuance.Sign(newProofContext("did:example:other"), loader)
+		require.EqualError(t, err, `"did:example:other" is not a signer of the proof threshold`)
+
+		// the notary signs its own copy of the credential
+		notaryCopy, err := parseTestCredential([]byte(validCredential))
+		require.NoError(t, err)
+		require.NoError(t, notaryCopy.AddLinkedDataProof(newProofContext(notaryDID), loader))
+
+		require.NoError(t, issuance.AddProofs(append(vc.Proofs, notaryCopy.Proofs...)...))
+		require.True(t, issuance.Complete())
+		require.Equal(t, []string{auditorDID}, issuance.Pending())
+
+		issued, err := issuance.Credential()
+		require.NoError(t, err)
+		require.Len(t, issued.Proofs, 2)
+
+		vcBytes, err := issued.MarshalJSON()
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(vcBytes, WithPublicKeyFetcher(pubKeyFetcher),
+			WithProofThreshold(2, registrarDID, notaryDID, auditorDID))
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(vcBytes, WithPublicKeyFetcher(pubKeyFetcher),
+			WithProofThreshold(2, registrarDID, auditorDID))
+		require.EqualError(t, err, "decode new credential: check embedded proof: "+
+			"proof threshold not met: 1 of 2 required signers")
+	})
+
+	t.Run("threshold requires linked data proofs", func(t *testing.T) {
+		_, err := parseTestCredential([]byte(validCredential), WithPublicKeyFetcher(pubKeyFetcher),
+			WithProofThreshold(1, registrarDID))
+		require.EqualError(t, err, "decode new credential: check embedded proof: "+
+			"proof threshold requires linked data proofs")
+
+		vc, err := parseTestCredential([]byte(validCredential))
+		require.NoError(t, err)
+
+		jwtClaims, err := vc.JWTClaims(false)
+		require.NoError(t, err)
+
+		unsecuredJWT, err := jwtClaims.MarshalUnsecuredJWT()
+		require.NoError(t, err)
+
+		_, err = parseTestCredential([]byte(unsecuredJWT), WithPublicKeyFetcher(pubKeyFetcher),
+			WithProofThreshold(1, registrarDID))
+		require.EqualError(t, err, "decode new credential: check embedded proof: "+
+			"proof threshold requires linked data proofs")
+
+		signer, err := newCryptoSigner(kmsapi.ED25519Type)
+		require.NoError(t, err)
+
+		jws, err := jwtClaims.MarshalJWS(EdDSA, signer, registrarDID+"#key-1")
+		require.NoError(t, err)
+
+		_, err = parseTestCredential([]byte(jws), WithPublicKeyFetcher(pubKeyFetcher),
+			WithProofThreshold(1, registrarDID))
+		require.EqualError(t, err, "decode new credential: proof threshold requires linked data proofs")
+
+		// the threshold doesn't apply if the proof check is disabled
+		_, err = parseTestCredential([]byte(validCredential), WithDisabledProofCheck(),
+			WithProofThreshold(1, registrarDID))
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid issuance", func(t *testing.T) {
+		_, err := NewThresholdIssuance(nil, threshold)
+		require.EqualError(t, err, "credential and proof threshold are mandatory")
+
+		_, err = NewThresholdIssuance(&Credential{}, &ProofThreshold{Threshold: 1})
+		require.EqualError(t, err, "invalid proof threshold: 1 of 0 signers")
+
+		issuance, err := NewThresholdIssuance(&Credential{}, threshold)
+		require.NoError(t, err)
+
+		err = issuance.AddProofs(Proof{"verificationMethod": "did:example:other#key-1"})
+		require.EqualError(t, err, `"did:example:other" is not a signer of the proof threshold`)
+	})
+}
+
+func TestKMSProofContext(t *testing.T) {
+	localKMS, err := createKMS()
+	require.NoError(t, err)
+
+	crypto, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	keyID, _, err := localKMS.Create(kmsapi.ED25519Type)
+	require.NoError(t, err)
+
+	context, err := KMSProofContext(localKMS, crypto, keyID, jsonWebSignature2020, registrarDID+"#"+keyID)
+	require.NoError(t, err)
+	require.Equal(t, SignatureJWS, context.SignatureRepresentation)
+	require.True(t, context.Suite.Accept(jsonWebSignature2020))
+
+	_, err = KMSProofContext(localKMS, crypto, keyID, bbsBlsSignature2020, registrarDID+"#"+keyID)
+	require.EqualError(t, err, "unsupported signature type BbsBlsSignature2020")
+
+	_, err = KMSProofContext(&mockkms.KeyManager{GetKeyErr: errors.New("no key")}, crypto, keyID,
+		ed25519Signature2018, registrarDID+"#"+keyID)
+	require.EqualError(t, err, fmt.Sprintf("get key %s: no key", keyID))
+}
@@ -0,0 +1,207 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jwt"
+)
+
+// Checks made by the verification of a credential.
+const (
+	ProofCheck            = "proof"
+	DataModelCheck        = "dataModel"
+	ContextAllowListCheck = "contextAllowList"
+	ProofThresholdCheck   = "proofThreshold"
+)
+
+// ErrCredentialRejected is returned when a verified credential is rejected by its post-verification hooks.
+var ErrCredentialRejected = errors.New("credential rejected by post-verification hooks")
+
+// VerificationResult is the structured result of the verification of a credential, extended by the risk scores
+// and the policy verdicts of the post-verification hooks.
+type VerificationResult struct {
+	// Verified is true if the credential passed the checks, all the policy verdicts and the risk threshold.
+	Verified bool `json:"verified"`
+	// Checks are the checks which ran to verify the credential, empty if it couldn't be parsed.
+	Checks []string `json:"checks,omitempty"`
+	// Error is the reason of a failed verification.
+	Error string `json:"error,omitempty"`
+	// RiskScore is the highest risk score of the post-verification hooks, from 0 (no risk) to 1.
+	RiskScore float64 `json:"riskScore"`
+	// Verdicts are the policy verdicts of the post-verification hooks.
+	Verdicts []*PolicyVerdict `json:"verdicts,omitempty"`
+}
+
+// PolicyVerdict is the verdict of a post-verification hook on a policy, e.g. a velocity check or a device binding.
+type PolicyVerdict struct {
+	Policy string `json:"policy"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// HookResult is the result of a post-verification hook.
+type HookResult struct {
+	// RiskScore is the risk score of the credential, from 0 (no risk) to 1.
+	RiskScore float64
+	// Verdicts are the additional policy verdicts on the credential.
+	Verdicts []*PolicyVerdict
+}
+
+// PostVerificationHook evaluates a credential after it has passed the checks of the verification. It receives the
+// result of the verification so far, including the risk scores and verdicts of the previous hooks.
+type PostVerificationHook interface {
+	Evaluate(vc *Credential, result *VerificationResult) (*HookResult, error)
+}
+
+// PostVerificationHookFunc is a function wrapper for PostVerificationHook.
+type PostVerificationHookFunc func(vc *Credential, result *VerificationResult) (*HookResult, error)
+
+// Evaluate evaluates the credential.
+func (f PostVerificationHookFunc) Evaluate(vc *Credential, result *VerificationResult) (*HookResult, error) {
+	return f(vc, result)
+}
+
+// WithPostVerificationHooks adds hooks evaluating the credential after it has passed the checks of the
+// verification. Their risk scores and verdicts are merged into the result of VerifyCredential, and
+// ParseCredential fails with ErrCredentialRejected if the credential doesn't pass them.
+func WithPostVerificationHooks(hooks ...PostVerificationHook) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.postVerificationHooks = append(opts.postVerificationHooks, hooks...)
+	}
+}
+
+// WithRiskThreshold rejects credentials with a risk score of the post-verification hooks above the threshold
+// (defaults to 1, i.e. risk scores alone don't reject credentials). It requires post-verification hooks.
+func WithRiskThreshold(threshold float64) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.riskThreshold = &threshold
+	}
+}
+
+// VerifyCredential verifies the credential like ParseCredential and returns the structured result of the
+// verification, extended by the post-verification hooks. A failed verification is reported by the result,
+// while the error is returned if a hook fails. Unlike ParseCredential, a credential without proof (neither a JWS
+// nor embedded proofs) fails the verification, unless the proof check is disabled. The credential is returned
+// if it passed the checks.
+func VerifyCredential(vcData []byte, opts ...CredentialOpt) (*Credential, *VerificationResult, error) {
+	vcOpts := getCredentialOpts(opts)
+
+	if err := checkPostVerificationOpts(vcOpts); err != nil {
+		return nil, nil, err
+	}
+
+	result := &VerificationResult{}
+
+	vc, err := parseCredential(vcData, vcOpts)
+	if err != nil {
+		result.Error = err.Error()
+
+		return nil, result, nil
+	}
+
+	result.Checks = verificationChecks(vc, vcData, vcOpts)
+
+	if !vcOpts.disabledProofCheck && !hasProof(vc, vcData) {
+		result.Error = "credential has no proof"
+
+		return nil, result, nil
+	}
+
+	err = evaluatePostVerificationHooks(vc, result, vcOpts)
+	if err != nil {
+		return vc, result, err
+	}
+
+	return vc, result, nil
+}
+
+func checkPostVerificationOpts(vcOpts *credentialOpts) error {
+	if vcOpts.riskThreshold != nil && len(vcOpts.postVerificationHooks) == 0 {
+		return errors.New("risk threshold requires post-verification hooks")
+	}
+
+	return nil
+}
+
+// verificationChecks returns the checks which ran to verify the parsed credential. The proof checks run only
+// on credentials with a proof.
+func verificationChecks(vc *Credential, vcData []byte, vcOpts *credentialOpts) []string {
+	var checks []string
+
+	if !vcOpts.disabledProofCheck && hasProof(vc, vcData) {
+		checks = append(checks, ProofCheck)
+
+		if vcOpts.proofThreshold != nil {
+			checks = append(checks, ProofThresholdCheck)
+		}
+	}
+
+	checks = append(checks, DataModelCheck)
+
+	if vcOpts.contextAllowList != nil {
+		checks = append(checks, ContextAllowListCheck)
+	}
+
+	return checks
+}
+
+// hasProof checks if the credential is a JWS or has embedded proofs.
+func hasProof(vc *Credential, vcData []byte) bool {
+	return len(vc.Proofs) > 0 || jwt.IsJWS(strings.TrimSpace(string(vcData)))
+}
+
+// evaluatePostVerificationHooks merges the results of the hooks into the result of the verified credential.
+func evaluatePostVerificationHooks(vc *Credential, result *VerificationResult, vcOpts *credentialOpts) error {
+	result.Verified = true
+
+	for i, hook := range vcOpts.postVerificationHooks {
+		hookResult, err := hook.Evaluate(vc, result)
+		if err != nil {
+			result.Verified = false
+			result.Error = fmt.Sprintf("post-verification hook %d: %s", i, err)
+
+			return fmt.Errorf("post-verification hook %d: %w", i, err)
+		}
+
+		if hookResult == nil {
+			continue
+		}
+
+		if hookResult.RiskScore > result.RiskScore {
+			result.RiskScore = hookResult.RiskScore
+		}
+
+		result.Verdicts = append(result.Verdicts, hookResult.Verdicts...)
+	}
+
+	var reasons, failed []string
+
+	for _, verdict := range result.Verdicts {
+		if !verdict.Passed {
+			failed = append(failed, verdict.Policy)
+		}
+	}
+
+	if len(failed) > 0 {
+		reasons = append(reasons, "failed policies: "+strings.Join(failed, ", "))
+	}
+
+	if vcOpts.riskThreshold != nil && result.RiskScore > *vcOpts.riskThreshold {
+		reasons = append(reasons, fmt.Sprintf("risk score %g exceeds %g", result.RiskScore, *vcOpts.riskThreshold))
+	}
+
+	if len(reasons) > 0 {
+		result.Verified = false
+		result.Error = strings.Join(reasons, "; ")
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	kmsapi "github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const hookCredential = `{
+	"@context": "https://www.w3.org/2018/credentials/v1",
+	"id": "http://example.edu/credentials/1872",
+	"type": "VerifiableCredential",
+	"credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"},
+	"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+	"issuanceDate": "2010-01-01T19:23:24Z"
+}`
+
+func riskHook(score float64, verdicts ...*PolicyVerdict) PostVerificationHook {
+	return PostVerificationHookFunc(func(vc *Credential, result *VerificationResult) (*HookResult, error) {
+		return &HookResult{RiskScore: score, Verdicts: verdicts}, nil
+	})
+}
+
+func TestVerifyCredential(t *testing.T) {
+	t.Run("hooks results are merged", func(t *testing.T) {
+		var received []*VerificationResult
+
+		recorder := PostVerificationHookFunc(func(vc *Credential, result *VerificationResult) (*HookResult, error) {
+			require.Equal(t, "http://example.edu/credentials/1872", vc.ID)
+
+			received = append(received, result)
+
+			return nil, nil
+		})
+
+		vc, result, err := VerifyCredential([]byte(hookCredential), WithDisabledProofCheck(),
+			WithPostVerificationHooks(recorder, riskHook(0.3, &PolicyVerdict{Policy: "velocity", Passed: true})),
+			WithPostVerificationHooks(riskHook(0.2, &PolicyVerdict{Policy: "deviceBinding", Passed: true}), recorder))
+		require.NoError(t, err)
+		require.NotNil(t, vc)
+		require.True(t, result.Verified)
+		require.Equal(t, []string{DataModelCheck}, result.Checks)
+		require.Equal(t, 0.3, result.RiskScore)
+		require.Len(t, result.Verdicts, 2)
+		require.Empty(t, result.Error)
+
+		// hooks receive the result so far
+		require.Len(t, received, 2)
+		require.True(t, received[0].Verified)
+	})
+
+	t.Run("failed verdicts and risk threshold", func(t *testing.T) {
+		vc, result, err := VerifyCredential([]byte(hookCredential), WithDisabledProofCheck(),
+			WithContextAllowList(), WithRiskThreshold(0.5),
+			WithPostVerificationHooks(riskHook(0.9, &PolicyVerdict{Policy: "velocity", Reason: "10 presentations/min"}),
+				riskHook(0, &PolicyVerdict{Policy: "deviceBinding", Passed: true})))
+		require.NoError(t, err)
+		require.NotNil(t, vc)
+		require.False(t, result.Verified)
+		require.Equal(t, []string{DataModelCheck, ContextAllowListCheck}, result.Checks)
+		require.Equal(t, "failed policies: velocity; risk score 0.9 exceeds 0.5", result.Error)
+
+		_, err = ParseCredential([]byte(hookCredential), WithDisabledProofCheck(), WithRiskThreshold(0.5),
+			WithPostVerificationHooks(riskHook(0.9)))
+		require.True(t, errors.Is(err, ErrCredentialRejected))
+		require.EqualError(t, err, "credential rejected by post-verification hooks: risk score 0.9 exceeds 0.5")
+
+		// risk scores alone don't reject credentials by default
+		vc, err = ParseCredential([]byte(hookCredential), WithDisabledProofCheck(),
+			WithPostVerificationHooks(riskHook(1)))
+		require.NoError(t, err)
+		require.NotNil(t, vc)
+	})
+
+	t.Run("verification failure", func(t *testing.T) {
+		called := false
+
+		vc, result, err := VerifyCredential([]byte(`{"@context": "https://www.w3.org/2018/credentials/v1"}`),
+			WithProofThreshold(1, "did:example:registrar"), WithPostVerificationHooks(PostVerificationHookFunc(
+				func(*Credential, *VerificationResult) (*HookResult, error) {
+					called = true
+
+					return nil, nil
+				})))
+		require.NoError(t, err)
+		require.Nil(t, vc)
+		require.False(t, result.Verified)
+		require.Empty(t, result.Checks)
+		require.NotEmpty(t, result.Error)
+		require.False(t, called)
+	})
+
+	t.Run("proof check", func(t *testing.T) {
+		signer, err := newCryptoSigner(kmsapi.ED25519Type)
+		require.NoError(t, err)
+
+		vc, err := parseTestCredential([]byte(hookCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		claims, err := vc.JWTClaims(false)
+		require.NoError(t, err)
+
+		jws, err := claims.MarshalJWS(EdDSA, signer, "did:example:76e12ec712ebc6f1c221ebfeb1f#key-1")
+		require.NoError(t, err)
+
+		pubKeyFetcher := SingleKey(signer.PublicKeyBytes(), kmsapi.ED25519)
+
+		vc, result, err := VerifyCredential([]byte(jws), WithPublicKeyFetcher(pubKeyFetcher))
+		require.NoError(t, err)
+		require.NotNil(t, vc)
+		require.True(t, result.Verified)
+		require.Equal(t, []string{ProofCheck, DataModelCheck}, result.Checks)
+
+		// credentials without proof are not verified
+		vc, result, err = VerifyCredential([]byte(hookCredential), WithPublicKeyFetcher(pubKeyFetcher),
+			WithPostVerificationHooks(riskHook(0)))
+		require.NoError(t, err)
+		require.Nil(t, vc)
+		require.False(t, result.Verified)
+		require.Equal(t, []string{DataModelCheck}, result.Checks)
+		require.Equal(t, "credential has no proof", result.Error)
+		require.Empty(t, result.Verdicts)
+
+		unsecuredJWT, err := claims.MarshalUnsecuredJWT()
+		require.NoError(t, err)
+
+		_, result, err = VerifyCredential([]byte(unsecuredJWT), WithPublicKeyFetcher(pubKeyFetcher))
+		require.NoError(t, err)
+		require.False(t, result.Verified)
+		require.Equal(t, "credential has no proof", result.Error)
+	})
+
+	t.Run("risk threshold without hooks", func(t *testing.T) {
+		_, _, err := VerifyCredential([]byte(hookCredential), WithDisabledProofCheck(), WithRiskThreshold(0.5))
+		require.EqualError(t, err, "risk threshold requires post-verification hooks")
+
+		_, err = ParseCredential([]byte(hookCredential), WithDisabledProofCheck(), WithRiskThreshold(0.5))
+		require.EqualError(t, err, "risk threshold requires post-verification hooks")
+	})
+
+	t.Run("hook error", func(t *testing.T) {
+		failing := PostVerificationHookFunc(func(*Credential, *VerificationResult) (*HookResult, error) {
+			return nil, errors.New("scoring service unavailable")
+		})
+
+		_, result, err := VerifyCredential([]byte(hookCredential), WithDisabledProofCheck(),
+			WithPostVerificationHooks(riskHook(0.1), failing))
+		require.EqualError(t, err, "post-verification hook 1: scoring service unavailable")
+		require.False(t, result.Verified)
+		require.Equal(t, "post-verification hook 1: scoring service unavailable", result.Error)
+
+		_, err = ParseCredential([]byte(hookCredential), WithDisabledProofCheck(), WithPostVerificationHooks(failing))
+		require.EqualError(t, err, "post-verification hook 0: scoring service unavailable")
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
@@ -19,6 +20,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/anoncrypt"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/authcrypt"
 	legacy "github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/legacy/authcrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/chunking"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/introduce"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/issuecredential"
@@ -30,7 +32,6 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/presentproof"
 	didcommtransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	arieshttp "github.com/hyperledger/aries-framework-go/pkg/didcomm/transport/http"
-	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
@@ -42,6 +43,8 @@ import (
 
 // defFrameworkOpts provides default framework options.
 func defFrameworkOpts(frameworkOpts *Aries) error {
+	frameworkOpts.fipsMode = frameworkOpts.fipsMode || fips.Enabled()
+
 	// TODO https://github.com/hyperledger/aries-framework-go/issues/209 Move default providers to the sub-package
 	if len(frameworkOpts.outboundTransports) == 0 {
 		outbound, err := arieshttp.NewOutbound(arieshttp.WithOutboundHTTPClient(&http.Client{}))
@@ -68,7 +71,8 @@ func defFrameworkOpts(frameworkOpts *Aries) error {
 	// - Introduce depends on OutOfBand
 	frameworkOpts.protocolSvcCreators = append(frameworkOpts.protocolSvcCreators,
 		newMessagePickupSvc(), newRouteSvc(), newExchangeSvc(), newOutOfBandSvc(),
-		newIntroduceSvc(), newIssueCredentialSvc(), newPresentProofSvc())
+		newIntroduceSvc(), newIssueCredentialSvc(frameworkOpts.saveCredentialOpts...), newPresentProofSvc(),
+		newChunkingSvc())
 
 	if frameworkOpts.secretLock == nil && frameworkOpts.kmsCreator == nil {
 		err = createDefSecretLock(frameworkOpts)
@@ -79,13 +83,21 @@ func defFrameworkOpts(frameworkOpts *Aries) error {
 
 	if frameworkOpts.kmsCreator == nil {
 		frameworkOpts.kmsCreator = func(provider kms.Provider) (kms.KeyManager, error) {
-			return localkms.New(defaultMasterKeyURI, provider)
+			return localkms.New(defaultMasterKeyURI, provider, localKMSOpts(frameworkOpts)...)
 		}
 	}
 
 	return setAdditionalDefaultOpts(frameworkOpts)
 }
 
+func localKMSOpts(frameworkOpts *Aries) []localkms.Opt {
+	if frameworkOpts.fipsMode {
+		return []localkms.Opt{localkms.WithFIPSMode()}
+	}
+
+	return nil
+}
+
 func newExchangeSvc() api.ProtocolSvcCreator {
 	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
 		return didexchange.New(prv)
@@ -98,7 +110,7 @@ func newIntroduceSvc() api.ProtocolSvcCreator {
 	}
 }
 
-func newIssueCredentialSvc() api.ProtocolSvcCreator {
+func newIssueCredentialSvc(opts ...mdissuecredential.Option) api.ProtocolSvcCreator {
 	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
 		service, err := issuecredential.New(prv)
 		if err != nil {
@@ -106,7 +118,7 @@ func newIssueCredentialSvc() api.ProtocolSvcCreator {
 		}
 
 		// sets default middleware to the service
-		service.Use(mdissuecredential.SaveCredentials(prv))
+		service.Use(mdissuecredential.SaveCredentials(prv, opts...))
 
 		return service, nil
 	}
@@ -143,6 +155,12 @@ func newMessagePickupSvc() api.ProtocolSvcCreator {
 	}
 }
 
+func newChunkingSvc() api.ProtocolSvcCreator {
+	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
+		return chunking.New(prv)
+	}
+}
+
 func newOutOfBandSvc() api.ProtocolSvcCreator {
 	return func(prv api.Provider) (dispatcher.ProtocolService, error) {
 		return outofband.New(prv)
@@ -152,13 +170,19 @@ func newOutOfBandSvc() api.ProtocolSvcCreator {
 func setAdditionalDefaultOpts(frameworkOpts *Aries) error {
 	if frameworkOpts.kmsCreator == nil {
 		frameworkOpts.kmsCreator = func(provider kms.Provider) (kms.KeyManager, error) {
-			return localkms.New("local-lock://", provider)
+			return localkms.New("local-lock://", provider, localKMSOpts(frameworkOpts)...)
 		}
 	}
 
 	if frameworkOpts.crypto == nil {
 		// create default tink crypto if not passed in frameworkOpts
-		cr, err := tinkcrypto.New()
+		var cryptoOpts []tinkcrypto.Opt
+
+		if frameworkOpts.fipsMode {
+			cryptoOpts = append(cryptoOpts, tinkcrypto.WithFIPSMode())
+		}
+
+		cr, err := tinkcrypto.New(cryptoOpts...)
 		if err != nil {
 			return fmt.Errorf("context creation failed: %w", err)
 		}
@@ -167,21 +191,7 @@ func setAdditionalDefaultOpts(frameworkOpts *Aries) error {
 	}
 
 	if frameworkOpts.packerCreator == nil {
-		frameworkOpts.packerCreator = func(provider packer.Provider) (packer.Packer, error) {
-			return legacy.New(provider), nil
-		}
-
-		frameworkOpts.packerCreators = []packer.Creator{
-			func(provider packer.Provider) (packer.Packer, error) {
-				return legacy.New(provider), nil
-			},
-			func(provider packer.Provider) (packer.Packer, error) {
-				return authcrypt.New(provider, jose.A256GCM)
-			},
-			func(provider packer.Provider) (packer.Packer, error) {
-				return anoncrypt.New(provider, jose.A256GCM)
-			},
-		}
+		setDefaultPackers(frameworkOpts)
 	}
 
 	if frameworkOpts.packagerCreator == nil {
@@ -234,3 +244,33 @@ type noOpMessageServiceProvider struct{}
 func (n *noOpMessageServiceProvider) Services() []dispatcher.MessageService {
 	return []dispatcher.MessageService{}
 }
+
+func setDefaultPackers(frameworkOpts *Aries) {
+	authcryptCreator := func(provider packer.Provider) (packer.Packer, error) {
+		return authcrypt.New(provider, frameworkOpts.encPreferences.Defaults()[0])
+	}
+
+	anoncryptCreator := func(provider packer.Provider) (packer.Packer, error) {
+		return anoncrypt.New(provider, frameworkOpts.encPreferences.Defaults()[0])
+	}
+
+	// legacy packer relies on Ed25519/X25519 keys and (X)Salsa20/ChaCha20 encryption which are not FIPS approved.
+	if frameworkOpts.fipsMode {
+		frameworkOpts.packerCreator = authcryptCreator
+		frameworkOpts.packerCreators = []packer.Creator{anoncryptCreator}
+
+		return
+	}
+
+	frameworkOpts.packerCreator = func(provider packer.Provider) (packer.Packer, error) {
+		return legacy.New(provider), nil
+	}
+
+	frameworkOpts.packerCreators = []packer.Creator{
+		func(provider packer.Provider) (packer.Packer, error) {
+			return legacy.New(provider), nil
+		},
+		authcryptCreator,
+		anoncryptCreator,
+	}
+}
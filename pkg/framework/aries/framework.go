@@ -19,14 +19,19 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/messenger"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packager"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/encpref"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
+	mdissuecredential "github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/middleware/issuecredential"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	verifiableapi "github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/store/connection"
 	"github.com/hyperledger/aries-framework-go/pkg/store/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/vdr/key"
@@ -47,6 +52,7 @@ type Aries struct {
 	msgSvcProvider             api.MessageServiceProvider
 	outboundDispatcher         dispatcher.Outbound
 	messenger                  service.MessengerHandler
+	messengerOpts              []messenger.Opt
 	outboundTransports         []transport.OutboundTransport
 	inboundTransports          []transport.InboundTransport
 	kms                        kms.KeyManager
@@ -59,9 +65,14 @@ type Aries struct {
 	packerCreators             []packer.Creator
 	primaryPacker              packer.Packer
 	packers                    []packer.Packer
+	encAlgs                    []jose.EncAlg
+	fipsOnlyEncryption         bool
+	fipsMode                   bool
+	encPreferences             *encpref.Preferences
 	vdrRegistry                vdrapi.Registry
 	vdr                        []vdrapi.VDR
 	verifiableStore            verifiable.Store
+	saveCredentialOpts         []mdissuecredential.Option
 	transportReturnRoute       string
 	id                         string
 }
@@ -260,6 +271,36 @@ func WithPacker(primary packer.Creator, additionalPackers ...packer.Creator) Opt
 	}
 }
 
+// WithEncryptionAlgorithms sets the preferred content encryption algorithms used by the JWE packers, most
+// preferred first. The most preferred algorithm is used unless overridden per connection (see
+// encpref.Preferences). The framework fails to start with algorithms the packers don't support.
+func WithEncryptionAlgorithms(algs ...jose.EncAlg) Option {
+	return func(opts *Aries) error {
+		opts.encAlgs = algs
+		return nil
+	}
+}
+
+// WithFIPSOnlyEncryption restricts content encryption algorithms to FIPS approved ones.
+func WithFIPSOnlyEncryption() Option {
+	return func(opts *Aries) error {
+		opts.fipsOnlyEncryption = true
+		return nil
+	}
+}
+
+// WithFIPSMode enables FIPS 140 mode: the crypto, KMS and packers only allow FIPS approved algorithms and fail
+// when non compliant ones (Ed25519, XChaCha20Poly1305, ...) are requested. The default packers are replaced by
+// JWE authcrypt (primary) and anoncrypt packers as the legacy packer is not compliant.
+// Note: FIPS mode applies to the default crypto and KMS of the framework instance only, custom ones must be set up
+// in FIPS mode too. It is always enabled when built with the `fips` build tag.
+func WithFIPSMode() Option {
+	return func(opts *Aries) error {
+		opts.fipsMode = true
+		return nil
+	}
+}
+
 // WithVerifiableStore injects a verifiable credential store.
 func WithVerifiableStore(store verifiable.Store) Option {
 	return func(opts *Aries) error {
@@ -268,6 +309,34 @@ func WithVerifiableStore(store verifiable.Store) Option {
 	}
 }
 
+// WithCredentialContextAllowList restricts the JSON-LD contexts of the credentials accepted by the default
+// issue credential middleware to the given URL patterns, where "*" matches any sequence of characters.
+// Credentials referencing other contexts are rejected before any context is fetched.
+func WithCredentialContextAllowList(patterns ...string) Option {
+	return func(opts *Aries) error {
+		opts.saveCredentialOpts = append(opts.saveCredentialOpts, mdissuecredential.WithContextAllowList(patterns...))
+		return nil
+	}
+}
+
+// WithCredentialPostVerificationHooks rejects the credentials received by the default issue credential middleware
+// which don't pass the post-verification hooks, e.g. risk scoring or device binding checks.
+func WithCredentialPostVerificationHooks(hooks ...verifiableapi.PostVerificationHook) Option {
+	return func(opts *Aries) error {
+		opts.saveCredentialOpts = append(opts.saveCredentialOpts, mdissuecredential.WithPostVerificationHooks(hooks...))
+		return nil
+	}
+}
+
+// WithMessageTraceListener sets the listener notified of the inbound and outbound messages of the default
+// messenger together with their ~trace_context decorator, e.g. to record OpenTelemetry spans correlated across agents.
+func WithMessageTraceListener(l messenger.TraceListener) Option {
+	return func(opts *Aries) error {
+		opts.messengerOpts = append(opts.messengerOpts, messenger.WithTraceListener(l))
+		return nil
+	}
+}
+
 // Context provides a handle to the framework context.
 func (a *Aries) Context() (*context.Provider, error) {
 	return context.New(
@@ -284,6 +353,7 @@ func (a *Aries) Context() (*context.Provider, error) {
 		context.WithProtocolStateStorageProvider(a.protocolStateStoreProvider),
 		context.WithPacker(a.primaryPacker, a.packers...),
 		context.WithPackager(a.packager),
+		context.WithEncryptionPreferences(a.encPreferences),
 		context.WithVDRegistry(a.vdrRegistry),
 		context.WithTransportReturnRoute(a.transportReturnRoute),
 		context.WithAriesFrameworkID(a.id),
@@ -398,7 +468,7 @@ func createMessengerHandler(frameworkOpts *Aries) error {
 		return fmt.Errorf("context creation failed: %w", err)
 	}
 
-	frameworkOpts.messenger, err = messenger.NewMessenger(ctx)
+	frameworkOpts.messenger, err = messenger.NewMessenger(ctx, frameworkOpts.messengerOpts...)
 
 	return err
 }
@@ -409,6 +479,7 @@ func createOutboundDispatcher(frameworkOpts *Aries) error {
 		context.WithCrypto(frameworkOpts.crypto),
 		context.WithOutboundTransports(frameworkOpts.outboundTransports...),
 		context.WithPackager(frameworkOpts.packager),
+		context.WithEncryptionPreferences(frameworkOpts.encPreferences),
 		context.WithTransportReturnRoute(frameworkOpts.transportReturnRoute),
 		context.WithVDRegistry(frameworkOpts.vdrRegistry),
 	)
@@ -460,6 +531,7 @@ func loadServices(frameworkOpts *Aries) error {
 		context.WithKMS(frameworkOpts.kms),
 		context.WithCrypto(frameworkOpts.crypto),
 		context.WithPackager(frameworkOpts.packager),
+		context.WithEncryptionPreferences(frameworkOpts.encPreferences),
 		context.WithServiceEndpoint(serviceEndpoint(frameworkOpts)),
 		context.WithRouterEndpoint(routingEndpoint(frameworkOpts)),
 		context.WithVDRegistry(frameworkOpts.vdrRegistry),
@@ -488,6 +560,11 @@ func loadServices(frameworkOpts *Aries) error {
 }
 
 func createPackersAndPackager(frameworkOpts *Aries) error {
+	err := createEncryptionPreferences(frameworkOpts)
+	if err != nil {
+		return err
+	}
+
 	ctx, err := context.New(
 		context.WithCrypto(frameworkOpts.crypto),
 		context.WithStorageProvider(frameworkOpts.storeProvider),
@@ -529,6 +606,38 @@ func createPackersAndPackager(frameworkOpts *Aries) error {
 	return nil
 }
 
+func createEncryptionPreferences(frameworkOpts *Aries) error {
+	var opts []encpref.Opt
+
+	if len(frameworkOpts.encAlgs) > 0 {
+		opts = append(opts, encpref.WithDefaults(frameworkOpts.encAlgs...))
+	}
+
+	if frameworkOpts.fipsOnlyEncryption || frameworkOpts.fipsMode {
+		opts = append(opts, encpref.WithFIPSOnly())
+	}
+
+	ctx, err := context.New(context.WithStorageProvider(frameworkOpts.storeProvider),
+		context.WithProtocolStateStorageProvider(frameworkOpts.protocolStateStoreProvider))
+	if err != nil {
+		return fmt.Errorf("create encryption preferences context failed: %w", err)
+	}
+
+	connections, err := connection.NewLookup(ctx)
+	if err != nil {
+		return fmt.Errorf("create encryption preferences connection lookup failed: %w", err)
+	}
+
+	prefs, err := encpref.New(ctx, append(opts, encpref.WithConnectionLookup(connections))...)
+	if err != nil {
+		return fmt.Errorf("create encryption preferences failed: %w", err)
+	}
+
+	frameworkOpts.encPreferences = prefs
+
+	return nil
+}
+
 func serviceEndpoint(frameworkOpts *Aries) string {
 	return fetchEndpoint(frameworkOpts, "ws")
 }
@@ -20,15 +20,21 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/anoncrypt"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/authcrypt"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/decorator"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/context"
 	mocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
@@ -598,6 +604,94 @@ func TestFramework(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, mockStore, aries.verifiableStore)
 	})
+
+	t.Run("test credential context allow-list option", func(t *testing.T) {
+		aries, err := New(WithCredentialContextAllowList("https://w3id.org/citizenship/*"))
+		require.NoError(t, err)
+		require.Len(t, aries.saveCredentialOpts, 1)
+		require.NoError(t, aries.Close())
+	})
+
+	t.Run("test credential post-verification hooks option", func(t *testing.T) {
+		aries, err := New(WithCredentialPostVerificationHooks(nil))
+		require.NoError(t, err)
+		require.Len(t, aries.saveCredentialOpts, 1)
+		require.NoError(t, aries.Close())
+	})
+
+	t.Run("test message trace listener option", func(t *testing.T) {
+		aries, err := New(WithMessageTraceListener(nil))
+		require.NoError(t, err)
+		require.Len(t, aries.messengerOpts, 1)
+		require.NotNil(t, aries.Messenger())
+		require.NoError(t, aries.Close())
+	})
+
+	t.Run("test FIPS mode option", func(t *testing.T) {
+		aries, err := New(WithFIPSMode())
+		require.NoError(t, err)
+		require.True(t, aries.fipsMode)
+		require.True(t, aries.encPreferences.FIPSOnly())
+		require.Len(t, aries.packers, 1)
+
+		_, isAuthcrypt := aries.primaryPacker.(*authcrypt.Packer)
+		require.True(t, isAuthcrypt)
+
+		_, isAnoncrypt := aries.packers[0].(*anoncrypt.Packer)
+		require.True(t, isAnoncrypt)
+
+		_, _, err = aries.kms.Create(kms.ED25519Type)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		edKH, err := keyset.NewHandle(signature.ED25519KeyWithoutPrefixTemplate())
+		require.NoError(t, err)
+
+		_, err = aries.crypto.Sign([]byte("msg"), edKH)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		require.NoError(t, aries.Close())
+
+		// FIPS mode is scoped to the framework instance
+		aries, err = New()
+		require.NoError(t, err)
+		require.False(t, aries.encPreferences.FIPSOnly())
+
+		_, _, err = aries.kms.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = aries.crypto.Sign([]byte("msg"), edKH)
+		require.NoError(t, err)
+
+		require.NoError(t, aries.Close())
+	})
+
+	t.Run("test encryption algorithms options", func(t *testing.T) {
+		aries, err := New(WithEncryptionAlgorithms(jose.A256GCM), WithFIPSOnlyEncryption())
+		require.NoError(t, err)
+		require.True(t, aries.encPreferences.FIPSOnly())
+		require.Equal(t, []jose.EncAlg{jose.A256GCM}, aries.encPreferences.Defaults())
+
+		ctx, err := aries.Context()
+		require.NoError(t, err)
+		require.Equal(t, aries.encPreferences, ctx.EncryptionPreferences())
+		require.NoError(t, aries.Close())
+
+		aries, err = New(WithEncryptionAlgorithms(jose.XC20P, jose.A256GCM))
+		require.NoError(t, err)
+		require.Equal(t, []jose.EncAlg{jose.XC20P, jose.A256GCM}, aries.encPreferences.Defaults())
+		require.NoError(t, aries.Close())
+
+		for _, alg := range []jose.EncAlg{"A128CBC-HS256", "A256CBC-HS512"} {
+			aries, err = New(WithEncryptionAlgorithms(alg))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), fmt.Sprintf("content encryption algorithm '%s' not supported", alg))
+			require.Nil(t, aries)
+		}
+
+		aries, err = New(WithEncryptionAlgorithms(jose.XC20P), WithFIPSOnlyEncryption())
+		require.Error(t, err)
+		require.Nil(t, aries)
+	})
 }
 
 func Test_Packager(t *testing.T) {
@@ -14,6 +14,7 @@ import (
 	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/encpref"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
@@ -38,6 +39,7 @@ type Provider struct {
 	packager                   commontransport.Packager
 	primaryPacker              packer.Packer
 	packers                    []packer.Packer
+	encPreferences             *encpref.Preferences
 	serviceEndpoint            string
 	routerEndpoint             string
 	outboundDispatcher         dispatcher.Outbound
@@ -118,6 +120,11 @@ func (p *Provider) Packager() commontransport.Packager {
 	return p.packager
 }
 
+// EncryptionPreferences returns the content encryption algorithm preferences.
+func (p *Provider) EncryptionPreferences() *encpref.Preferences {
+	return p.encPreferences
+}
+
 // Messenger returns a messenger.
 func (p *Provider) Messenger() service.Messenger {
 	return p.messenger
@@ -385,3 +392,11 @@ func WithVerifiableStore(store verifiable.Store) ProviderOption {
 		return nil
 	}
 }
+
+// WithEncryptionPreferences injects the content encryption algorithm preferences into the context.
+func WithEncryptionPreferences(prefs *encpref.Preferences) ProviderOption {
+	return func(opts *Provider) error {
+		opts.encPreferences = prefs
+		return nil
+	}
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer/encpref"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/protocol/didexchange"
 	serviceMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/didcomm/common/service"
 	verifiableStoreMocks "github.com/hyperledger/aries-framework-go/pkg/internal/gomocks/store/verifiable"
@@ -381,6 +382,18 @@ func TestNewProvider(t *testing.T) {
 		require.Equal(t, verifiableStore, prov.VerifiableStore())
 	})
 
+	t.Run("test new with encryption preferences", func(t *testing.T) {
+		storeProv, err := New(WithStorageProvider(storage.NewMockStoreProvider()))
+		require.NoError(t, err)
+
+		prefs, err := encpref.New(storeProv)
+		require.NoError(t, err)
+
+		prov, err := New(WithEncryptionPreferences(prefs))
+		require.NoError(t, err)
+		require.Equal(t, prefs, prov.EncryptionPreferences())
+	})
+
 	t.Run("test new with bad (fake) option", func(t *testing.T) {
 		prov, err := New(func(opts *Provider) error {
 			return fmt.Errorf("bad option")
@@ -162,6 +162,30 @@ const (
 	ECDH521KWAES256GCMType = KeyType(ECDH521KWAES256GCM)
 )
 
+// nolint:gochecknoglobals
+var fipsApprovedKeyTypes = map[KeyType]bool{
+	AES128GCMType:          true,
+	AES256GCMNoPrefixType:  true,
+	AES256GCMType:          true,
+	ECDSAP256TypeDER:       true,
+	ECDSAP384TypeDER:       true,
+	ECDSAP521TypeDER:       true,
+	ECDSAP256TypeIEEEP1363: true,
+	ECDSAP384TypeIEEEP1363: true,
+	ECDSAP521TypeIEEEP1363: true,
+	RSARS256Type:           true,
+	RSAPS256Type:           true,
+	HMACSHA256Tag256Type:   true,
+	ECDH256KWAES256GCMType: true,
+	ECDH384KWAES256GCMType: true,
+	ECDH521KWAES256GCMType: true,
+}
+
+// IsFIPSApproved returns true if the given key type is approved for FIPS 140 deployments.
+func IsFIPSApproved(kt KeyType) bool {
+	return fipsApprovedKeyTypes[kt]
+}
+
 // CryptoBox is a libsodium crypto service used by legacy authcrypt packer.
 // TODO remove this service when legacy packer is retired from the framework.
 type CryptoBox interface {
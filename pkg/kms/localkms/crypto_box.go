@@ -20,6 +20,7 @@ import (
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"golang.org/x/crypto/nacl/box"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
@@ -44,12 +45,17 @@ type CryptoBox struct {
 }
 
 // NewCryptoBox creates a CryptoBox which provides crypto box encryption using the given KMS's key.
+// It fails in FIPS mode as crypto box relies on X25519 and XSalsa20Poly1305 which are not FIPS approved.
 func NewCryptoBox(w kms.KeyManager) (*CryptoBox, error) {
 	lkms, ok := w.(*LocalKMS)
 	if !ok {
 		return nil, fmt.Errorf("cannot use parameter argument as KMS")
 	}
 
+	if err := fips.CheckMode(lkms.fipsMode, "crypto box", false); err != nil {
+		return nil, err
+	}
+
 	return &CryptoBox{km: lkms}, nil
 }
 
@@ -23,6 +23,7 @@ import (
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/signature"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	cryptoapi "github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
@@ -53,6 +54,18 @@ type LocalKMS struct {
 	primaryKeyURI     string
 	store             storage.Store
 	primaryKeyEnvAEAD *aead.KMSEnvelopeAEAD
+	fipsMode          bool
+}
+
+// Opt configures the LocalKMS.
+type Opt func(l *LocalKMS)
+
+// WithFIPSMode restricts the KMS to FIPS approved key types. FIPS mode is always enabled when enabled for the whole
+// process (see package fips).
+func WithFIPSMode() Opt {
+	return func(l *LocalKMS) {
+		l.fipsMode = true
+	}
 }
 
 func newKeyIDWrapperStore(provider storage.Provider) (storage.Store, error) {
@@ -65,7 +78,7 @@ func newKeyIDWrapperStore(provider storage.Provider) (storage.Store, error) {
 }
 
 // New will create a new (local) KMS service.
-func New(primaryKeyURI string, p kms.Provider) (*LocalKMS, error) {
+func New(primaryKeyURI string, p kms.Provider, opts ...Opt) (*LocalKMS, error) {
 	store, err := newKeyIDWrapperStore(p.StorageProvider())
 	if err != nil {
 		return nil, fmt.Errorf("new: failed to ceate local kms: %w", err)
@@ -81,13 +94,18 @@ func New(primaryKeyURI string, p kms.Provider) (*LocalKMS, error) {
 	// create a KMSEnvelopeAEAD instance to wrap/unwrap keys managed by LocalKMS
 	keyEnvelopeAEAD := aead.NewKMSEnvelopeAEAD2(aead.AES256GCMKeyTemplate(), kw)
 
-	return &LocalKMS{
-			store:             store,
-			secretLock:        secretLock,
-			primaryKeyURI:     primaryKeyURI,
-			primaryKeyEnvAEAD: keyEnvelopeAEAD,
-		},
-		nil
+	l := &LocalKMS{
+		store:             store,
+		secretLock:        secretLock,
+		primaryKeyURI:     primaryKeyURI,
+		primaryKeyEnvAEAD: keyEnvelopeAEAD,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l, nil
 }
 
 // Create a new key/keyset/key handle for the type kt
@@ -100,6 +118,10 @@ func (l *LocalKMS) Create(kt kms.KeyType) (string, interface{}, error) {
 		return "", nil, fmt.Errorf("failed to create new key, missing key type")
 	}
 
+	if err := fips.CheckMode(l.fipsMode, string(kt), kms.IsFIPSApproved(kt)); err != nil {
+		return "", nil, fmt.Errorf("create: %w", err)
+	}
+
 	keyTemplate, err := getKeyTemplate(kt)
 	if err != nil {
 		return "", nil, fmt.Errorf("create: failed to getKeyTemplate: %w", err)
@@ -133,6 +155,10 @@ func (l *LocalKMS) Get(keyID string) (interface{}, error) {
 //  - handle instance (to private key)
 //  - error if failure
 func (l *LocalKMS) Rotate(kt kms.KeyType, keyID string) (string, interface{}, error) {
+	if err := fips.CheckMode(l.fipsMode, string(kt), kms.IsFIPSApproved(kt)); err != nil {
+		return "", nil, fmt.Errorf("rotate: %w", err)
+	}
+
 	kh, err := l.getKeySet(keyID)
 	if err != nil {
 		return "", nil, fmt.Errorf("rotate: failed to getKeySet: %w", err)
@@ -364,6 +390,10 @@ func (l *LocalKMS) CreateAndExportPubKeyBytes(kt kms.KeyType) (string, []byte, e
 // Note: The key handle created is not stored in the KMS, it's only useful to execute the crypto primitive
 // associated with it.
 func (l *LocalKMS) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (interface{}, error) {
+	if err := fips.CheckMode(l.fipsMode, string(kt), kms.IsFIPSApproved(kt)); err != nil {
+		return nil, fmt.Errorf("pubKeyBytesToHandle: %w", err)
+	}
+
 	return publicKeyBytesToHandle(pubKey, kt)
 }
 
@@ -379,6 +409,10 @@ func (l *LocalKMS) PubKeyBytesToHandle(pubKey []byte, kt kms.KeyType) (interface
 //  - error if import failure (key empty, invalid, doesn't match keyType, unsupported keyType or storing key failed)
 func (l *LocalKMS) ImportPrivateKey(privKey interface{}, kt kms.KeyType,
 	opts ...kms.PrivateKeyOpts) (string, interface{}, error) {
+	if err := fips.CheckMode(l.fipsMode, string(kt), kms.IsFIPSApproved(kt)); err != nil {
+		return "", nil, fmt.Errorf("import private key: %w", err)
+	}
+
 	switch pk := privKey.(type) {
 	case *ecdsa.PrivateKey:
 		return l.importECDSAKey(pk, kt, opts...)
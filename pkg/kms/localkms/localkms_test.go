@@ -25,6 +25,7 @@ import (
 	"github.com/google/tink/go/subtle/random"
 	"github.com/stretchr/testify/require"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/fips"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms/internal/keywrapper"
 	mocksecretlock "github.com/hyperledger/aries-framework-go/pkg/mock/secretlock"
@@ -470,6 +471,80 @@ func TestLocalKMS_ImportPrivateKey(t *testing.T) {
 	}
 }
 
+func TestLocalKMS_FIPSMode(t *testing.T) {
+	storeProvider := mockstorage.NewMockStoreProvider()
+
+	kmsService, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: &noop.NoLock{}})
+	require.NoError(t, err)
+
+	edKID, _, err := kmsService.Create(kms.ED25519Type)
+	require.NoError(t, err)
+
+	t.Run("test FIPS mode - enabled for the whole process", func(t *testing.T) {
+		fips.SetEnabled(true)
+		defer fips.SetEnabled(false)
+
+		_, _, err := kmsService.Create(kms.ED25519Type)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		_, err = NewCryptoBox(kmsService)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+	})
+
+	// the FIPS mode KMS shares the keys of the store
+	kmsService, err = New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: &noop.NoLock{}},
+		WithFIPSMode())
+	require.NoError(t, err)
+
+	t.Run("test FIPS mode - approved key types", func(t *testing.T) {
+		kid, kh, err := kmsService.Create(kms.ECDSAP256TypeIEEEP1363)
+		require.NoError(t, err)
+		require.NotEmpty(t, kh)
+
+		_, _, err = kmsService.Rotate(kms.ECDSAP384TypeIEEEP1363, kid)
+		require.NoError(t, err)
+
+		_, _, err = kmsService.CreateAndExportPubKeyBytes(kms.ECDH256KWAES256GCMType)
+		require.NoError(t, err)
+	})
+
+	t.Run("test FIPS mode - non approved key types", func(t *testing.T) {
+		for _, kt := range []kms.KeyType{kms.ED25519Type, kms.XChaCha20Poly1305Type, kms.ChaCha20Poly1305Type} {
+			_, _, err := kmsService.Create(kt)
+			require.True(t, errors.Is(err, fips.ErrNotApproved), kt)
+		}
+
+		_, _, err = kmsService.Rotate(kms.ED25519Type, edKID)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		_, _, err = kmsService.CreateAndExportPubKeyBytes(kms.ED25519Type)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		_, err = kmsService.PubKeyBytesToHandle([]byte("key"), kms.ED25519Type)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		_, _, err = kmsService.ImportPrivateKey(edPriv, kms.ED25519Type)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+
+		_, err = NewCryptoBox(kmsService)
+		require.True(t, errors.Is(err, fips.ErrNotApproved))
+	})
+
+	t.Run("test FIPS mode - other instances are not restricted", func(t *testing.T) {
+		otherKMS, err := New(testMasterKeyURI, &mockProvider{storage: storeProvider, secretLock: &noop.NoLock{}})
+		require.NoError(t, err)
+
+		_, _, err = otherKMS.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = NewCryptoBox(otherKMS)
+		require.NoError(t, err)
+	})
+}
+
 func TestLocalKMS_getKeyTemplate(t *testing.T) {
 	keyTemplate, err := getKeyTemplate(kms.HMACSHA256Tag256Type)
 	require.NoError(t, err)
@@ -7,8 +7,10 @@ package provider
 
 import (
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	commontransport "github.com/hyperledger/aries-framework-go/pkg/didcomm/common/transport"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/dispatcher"
 	"github.com/hyperledger/aries-framework-go/pkg/didcomm/packer"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/transport"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
@@ -28,6 +30,8 @@ type Provider struct {
 	OutboundDispatcherValue           dispatcher.Outbound
 	VDRegistryValue                   vdrapi.Registry
 	CryptoValue                       crypto.Crypto
+	PackagerValue                     commontransport.Packager
+	InboundMessageHandlerValue        transport.InboundMessageHandler
 }
 
 // Service return service.
@@ -92,3 +96,13 @@ func (p *Provider) OutboundDispatcher() dispatcher.Outbound {
 func (p *Provider) VDRegistry() vdrapi.Registry {
 	return p.VDRegistryValue
 }
+
+// Packager returns the packager.
+func (p *Provider) Packager() commontransport.Packager {
+	return p.PackagerValue
+}
+
+// InboundMessageHandler returns the inbound message handler.
+func (p *Provider) InboundMessageHandler() transport.InboundMessageHandler {
+	return p.InboundMessageHandlerValue
+}
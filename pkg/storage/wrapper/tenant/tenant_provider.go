@@ -0,0 +1,108 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package tenant offers a storage.Provider wrapper that scopes all stores of an underlying provider to a single
+// tenant, so that one storage backend can be shared by agents serving different organizations.
+package tenant
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// separator delimits the tenant ID from the store name. Tenant IDs can't contain it, which keeps the
+// scoped store names of different tenants from colliding.
+const separator = "_"
+
+// tenantIDPattern restricts tenant IDs to characters which are safe in store names of all storage providers
+// (for example, leveldb uses store names as directory names and the mem provider ignores case).
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ValidateID checks that the tenant ID can be used to scope store names.
+func ValidateID(tenantID string) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return fmt.Errorf("invalid tenant ID '%s': only lowercase letters, digits and '-' are allowed", tenantID)
+	}
+
+	return nil
+}
+
+// Provider is a storage.Provider which prefixes the names of all stores it opens with the tenant ID.
+type Provider struct {
+	provider storage.Provider
+	tenantID string
+	stores   map[string]struct{}
+	lock     sync.Mutex
+}
+
+// NewProvider creates a new tenant scoped Provider on top of the given storage provider.
+func NewProvider(provider storage.Provider, tenantID string) (*Provider, error) {
+	if err := ValidateID(tenantID); err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		provider: provider,
+		tenantID: tenantID,
+		stores:   make(map[string]struct{}),
+	}, nil
+}
+
+// TenantID returns the ID of the tenant the provider is scoped to.
+func (p *Provider) TenantID() string {
+	return p.tenantID
+}
+
+// OpenStore opens the tenant's store with the given name.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	scopedName := p.scopedName(name)
+
+	store, err := p.provider.OpenStore(scopedName)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	p.stores[scopedName] = struct{}{}
+	p.lock.Unlock()
+
+	return store, nil
+}
+
+// CloseStore closes the tenant's store with the given name.
+func (p *Provider) CloseStore(name string) error {
+	scopedName := p.scopedName(name)
+
+	p.lock.Lock()
+	delete(p.stores, scopedName)
+	p.lock.Unlock()
+
+	return p.provider.CloseStore(scopedName)
+}
+
+// Close closes all stores opened by the tenant. The underlying provider and the stores of other tenants
+// stay open.
+func (p *Provider) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for name := range p.stores {
+		if err := p.provider.CloseStore(name); err != nil {
+			return fmt.Errorf("close store '%s' of tenant '%s': %w", name, p.tenantID, err)
+		}
+
+		delete(p.stores, name)
+	}
+
+	return nil
+}
+
+func (p *Provider) scopedName(name string) string {
+	return p.tenantID + separator + name
+}
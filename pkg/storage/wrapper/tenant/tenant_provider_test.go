@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package tenant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mockstorage "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestValidateID(t *testing.T) {
+	for _, id := range []string{"acme", "org-1", "0"} {
+		require.NoError(t, ValidateID(id), id)
+	}
+
+	for _, id := range []string{"", "Acme", "org_1", "-org", "org/1", "org 1"} {
+		err := ValidateID(id)
+		require.Error(t, err, id)
+		require.Contains(t, err.Error(), "invalid tenant ID")
+	}
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("test tenants are isolated", func(t *testing.T) {
+		memProvider := mem.NewProvider()
+
+		acme, err := NewProvider(memProvider, "acme")
+		require.NoError(t, err)
+		require.Equal(t, "acme", acme.TenantID())
+
+		globex, err := NewProvider(memProvider, "globex")
+		require.NoError(t, err)
+
+		acmeStore, err := acme.OpenStore("connections")
+		require.NoError(t, err)
+		require.NoError(t, acmeStore.Put("conn1", []byte("acme-data")))
+
+		globexStore, err := globex.OpenStore("connections")
+		require.NoError(t, err)
+
+		_, err = globexStore.Get("conn1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		underlying, err := memProvider.OpenStore("acme_connections")
+		require.NoError(t, err)
+
+		data, err := underlying.Get("conn1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("acme-data"), data)
+	})
+
+	t.Run("test close only closes stores of the tenant", func(t *testing.T) {
+		memProvider := mem.NewProvider()
+
+		acme, err := NewProvider(memProvider, "acme")
+		require.NoError(t, err)
+
+		globex, err := NewProvider(memProvider, "globex")
+		require.NoError(t, err)
+
+		acmeStore, err := acme.OpenStore("credentials")
+		require.NoError(t, err)
+		require.NoError(t, acmeStore.Put("vc1", []byte("acme-vc")))
+
+		globexStore, err := globex.OpenStore("credentials")
+		require.NoError(t, err)
+		require.NoError(t, globexStore.Put("vc1", []byte("globex-vc")))
+
+		require.NoError(t, acme.Close())
+
+		acmeStore, err = acme.OpenStore("credentials")
+		require.NoError(t, err)
+
+		_, err = acmeStore.Get("vc1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		globexStore, err = globex.OpenStore("credentials")
+		require.NoError(t, err)
+
+		data, err := globexStore.Get("vc1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("globex-vc"), data)
+
+		require.NoError(t, globex.CloseStore("credentials"))
+
+		globexStore, err = globex.OpenStore("credentials")
+		require.NoError(t, err)
+
+		_, err = globexStore.Get("vc1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test invalid tenant ID", func(t *testing.T) {
+		p, err := NewProvider(mem.NewProvider(), "Acme")
+		require.Error(t, err)
+		require.Nil(t, p)
+	})
+
+	t.Run("test underlying provider errors", func(t *testing.T) {
+		mockProvider := mockstorage.NewMockStoreProvider()
+		mockProvider.ErrOpenStoreHandle = errors.New("open error")
+
+		p, err := NewProvider(mockProvider, "acme")
+		require.NoError(t, err)
+
+		_, err = p.OpenStore("connections")
+		require.EqualError(t, err, "open error")
+
+		mockProvider.ErrOpenStoreHandle = nil
+
+		_, err = p.OpenStore("connections")
+		require.NoError(t, err)
+
+		mockProvider.ErrCloseStore = errors.New("close error")
+
+		err = p.Close()
+		require.EqualError(t, err, "close store 'acme_connections' of tenant 'acme': close error")
+
+		err = p.CloseStore("connections")
+		require.EqualError(t, err, "close error")
+	})
+}
@@ -0,0 +1,289 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// DefaultMaxResourceSize is the default limit of the size of a fetched linked resource.
+	DefaultMaxResourceSize = 10 << 20
+
+	// DefaultResourceTimeout is the timeout of the default HTTP client fetching linked resources.
+	DefaultResourceTimeout = 30 * time.Second
+
+	resourceKey = "vcresource_"
+)
+
+// HTTPClient fetches linked resources of credentials.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ResourceOpt configures fetching of linked resources.
+type ResourceOpt func(o *resourceOptions)
+
+type resourceOptions struct {
+	client  HTTPClient
+	maxSize int64
+	refresh bool
+}
+
+// WithResourceHTTPClient sets the HTTP client used to fetch linked resources (defaults to an HTTP client with
+// a DefaultResourceTimeout timeout).
+func WithResourceHTTPClient(client HTTPClient) ResourceOpt {
+	return func(o *resourceOptions) {
+		o.client = client
+	}
+}
+
+// WithMaxResourceSize limits the size of fetched linked resources (defaults to DefaultMaxResourceSize).
+func WithMaxResourceSize(size int64) ResourceOpt {
+	return func(o *resourceOptions) {
+		o.maxSize = size
+	}
+}
+
+// WithResourceRefresh fetches linked resources again even if they are cached.
+func WithResourceRefresh() ResourceOpt {
+	return func(o *resourceOptions) {
+		o.refresh = true
+	}
+}
+
+// LinkedResourceContent is the verified content of a linked resource of a credential.
+type LinkedResourceContent struct {
+	ID        string    `json:"id"`
+	DigestSRI string    `json:"digestSRI"`
+	MediaType string    `json:"mediaType,omitempty"`
+	Content   []byte    `json:"content"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// FetchLinkedResources fetches the linked resources referenced by the evidence of the stored credential,
+// verifies their digests and caches them alongside the credential. Cached resources are verified again and
+// not fetched unless WithResourceRefresh is used. Cached resources are removed together with the credential.
+func (s *StoreImplementation) FetchLinkedResources(credentialID string,
+	opts ...ResourceOpt) ([]*LinkedResourceContent, error) {
+	o := &resourceOptions{client: &http.Client{Timeout: DefaultResourceTimeout}, maxSize: DefaultMaxResourceSize}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	vc, err := s.GetCredential(credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := vc.LinkedResources()
+	if err != nil {
+		return nil, fmt.Errorf("get linked resources: %w", err)
+	}
+
+	contents := make([]*LinkedResourceContent, 0, len(resources))
+
+	for _, resource := range resources {
+		if !o.refresh {
+			cached, cacheErr := s.getCachedResource(credentialID, resource)
+			if cacheErr == nil {
+				contents = append(contents, cached)
+
+				continue
+			}
+		}
+
+		content, err := fetchResource(resource, o)
+		if err != nil {
+			return nil, err
+		}
+
+		contentBytes, err := json.Marshal(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal linked resource: %w", err)
+		}
+
+		err = s.store.Put(resourceDataKey(credentialID, resource.ID), contentBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to put linked resource: %w", err)
+		}
+
+		contents = append(contents, content)
+	}
+
+	return contents, nil
+}
+
+// GetLinkedResource returns the cached linked resource of the credential.
+func (s *StoreImplementation) GetLinkedResource(credentialID, resourceID string) (*LinkedResourceContent, error) {
+	vc, err := s.GetCredential(credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := vc.LinkedResources()
+	if err != nil {
+		return nil, fmt.Errorf("get linked resources: %w", err)
+	}
+
+	for _, resource := range resources {
+		if resource.ID == resourceID {
+			return s.getCachedResource(credentialID, resource)
+		}
+	}
+
+	return nil, fmt.Errorf("linked resource %s of credential %s: %w", resourceID, credentialID,
+		storage.ErrDataNotFound)
+}
+
+// getCachedResource returns the cached resource after verifying it against the digest in the credential.
+func (s *StoreImplementation) getCachedResource(credentialID string,
+	resource *verifiable.LinkedResource) (*LinkedResourceContent, error) {
+	contentBytes, err := s.store.Get(resourceDataKey(credentialID, resource.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get linked resource: %w", err)
+	}
+
+	var content LinkedResourceContent
+
+	err = json.Unmarshal(contentBytes, &content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal linked resource: %w", err)
+	}
+
+	err = resource.Verify(content.Content)
+	if err != nil {
+		return nil, fmt.Errorf("cached linked resource: %w", err)
+	}
+
+	return &content, nil
+}
+
+func (s *StoreImplementation) removeLinkedResources(credentialID string) error {
+	searchKey := resourceDataKey(credentialID, "")
+
+	itr := s.store.Iterator(searchKey, fmt.Sprintf(limitPattern, searchKey))
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	itr.Release()
+
+	for _, key := range keys {
+		if err := s.store.Delete(key); err != nil {
+			return fmt.Errorf("unable to delete linked resource : %w", err)
+		}
+	}
+
+	return nil
+}
+
+func fetchResource(resource *verifiable.LinkedResource, o *resourceOptions) (*LinkedResourceContent, error) {
+	req, err := http.NewRequest(http.MethodGet, resource.ID, nil) // nolint:noctx
+	if err != nil {
+		return nil, fmt.Errorf("new request for linked resource %s: %w", resource.ID, err)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch linked resource %s: %w", resource.ID, err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logger.Warnf("failed to close response body: %s", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch linked resource %s: unexpected status %d", resource.ID, resp.StatusCode)
+	}
+
+	if resp.ContentLength > o.maxSize {
+		return nil, fmt.Errorf("linked resource %s exceeds %d bytes", resource.ID, o.maxSize)
+	}
+
+	mediaType, err := resourceMediaType(resource, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, o.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read linked resource %s: %w", resource.ID, err)
+	}
+
+	if int64(len(content)) > o.maxSize {
+		return nil, fmt.Errorf("linked resource %s exceeds %d bytes", resource.ID, o.maxSize)
+	}
+
+	if err := resource.Verify(content); err != nil {
+		return nil, err
+	}
+
+	return &LinkedResourceContent{
+		ID:        resource.ID,
+		DigestSRI: resource.DigestSRI,
+		MediaType: mediaType,
+		Content:   content,
+		FetchedAt: time.Now(),
+	}, nil
+}
+
+// resourceMediaType returns the media type of the fetched resource, which must be the one declared in the credential
+// if any: a resource matching the digest but served as another type is rejected.
+func resourceMediaType(resource *verifiable.LinkedResource, contentType string) (string, error) {
+	if contentType == "" {
+		return resource.MediaType, nil
+	}
+
+	if resource.MediaType == "" {
+		return contentType, nil
+	}
+
+	served, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("linked resource %s: invalid content type '%s': %w", resource.ID, contentType, err)
+	}
+
+	declared, _, err := mime.ParseMediaType(resource.MediaType)
+	if err != nil {
+		return "", fmt.Errorf("linked resource %s: invalid media type '%s': %w", resource.ID, resource.MediaType, err)
+	}
+
+	if served != declared {
+		return "", fmt.Errorf("linked resource %s is served as %s instead of %s", resource.ID, served,
+			resource.MediaType)
+	}
+
+	return contentType, nil
+}
+
+// resourceDataKey encodes the IDs, as they may contain the separator.
+func resourceDataKey(credentialID, resourceID string) string {
+	key := resourceKey + base64.RawStdEncoding.EncodeToString([]byte(credentialID)) + "_"
+
+	if resourceID != "" {
+		key += base64.RawStdEncoding.EncodeToString([]byte(resourceID))
+	}
+
+	return key
+}
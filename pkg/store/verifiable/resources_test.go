@@ -0,0 +1,285 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+type resourceServer struct {
+	*httptest.Server
+
+	lock      sync.Mutex
+	resources map[string][]byte
+	requests  int
+}
+
+func newResourceServer(resources map[string][]byte) *resourceServer {
+	s := &resourceServer{resources: resources}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		s.requests++
+
+		content, ok := s.resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		contentType := r.URL.Query().Get("type")
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(r.URL.Path))
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(content) // nolint:errcheck,gosec
+	}))
+
+	return s
+}
+
+func digestSRI(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func newCredentialWithEvidence(id string, evidence ...map[string]interface{}) *verifiable.Credential {
+	return &verifiable.Credential{
+		Context:  []string{"https://www.w3.org/2018/credentials/v1"},
+		ID:       id,
+		Types:    []string{"VerifiableCredential"},
+		Subject:  "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		Issuer:   verifiable.Issuer{ID: "did:example:76e12ec712ebc6f1c221ebfeb1f"},
+		Issued:   util.NewTime(retentionNow),
+		Evidence: evidence,
+	}
+}
+
+func TestStore_FetchLinkedResources(t *testing.T) {
+	transcript := []byte("transcript of records")
+	photo := []byte("photo")
+
+	server := newResourceServer(map[string][]byte{"/transcript.pdf": transcript, "/photo.jpg": photo})
+	defer server.Close()
+
+	newStore := func(t *testing.T, evidence ...map[string]interface{}) *StoreImplementation {
+		t.Helper()
+
+		store, err := New(&mockprovider.Provider{StorageProviderValue: mem.NewProvider()})
+		require.NoError(t, err)
+
+		require.NoError(t, store.SaveCredential("degree",
+			newCredentialWithEvidence("http://example.edu/credentials/1872", evidence...)))
+
+		return store
+	}
+
+	evidence := []map[string]interface{}{
+		{"id": server.URL + "/transcript.pdf", "digestSRI": digestSRI(transcript)},
+		{"id": server.URL + "/photo.jpg", "digestSRI": digestSRI(photo), "mediaType": "image/jpeg"},
+		{"id": "https://example.edu/evidence/f2aeec97", "verifier": "https://example.edu/issuers/14"},
+	}
+
+	t.Run("test fetch verify and cache", func(t *testing.T) {
+		store := newStore(t, evidence...)
+
+		contents, err := store.FetchLinkedResources("http://example.edu/credentials/1872",
+			WithResourceHTTPClient(server.Client()))
+		require.NoError(t, err)
+		require.Len(t, contents, 2)
+		require.Equal(t, server.URL+"/transcript.pdf", contents[0].ID)
+		require.Equal(t, transcript, contents[0].Content)
+		require.Equal(t, "application/pdf", contents[0].MediaType)
+		require.Equal(t, photo, contents[1].Content)
+		require.False(t, contents[1].FetchedAt.IsZero())
+
+		requests := server.requests
+
+		// cached resources aren't fetched again
+		contents, err = store.FetchLinkedResources("http://example.edu/credentials/1872")
+		require.NoError(t, err)
+		require.Len(t, contents, 2)
+		require.Equal(t, requests, server.requests)
+
+		content, err := store.GetLinkedResource("http://example.edu/credentials/1872", server.URL+"/photo.jpg")
+		require.NoError(t, err)
+		require.Equal(t, photo, content.Content)
+
+		_, err = store.FetchLinkedResources("http://example.edu/credentials/1872", WithResourceRefresh())
+		require.NoError(t, err)
+		require.Equal(t, requests+2, server.requests)
+
+		// cached resources are removed with the credential
+		require.NoError(t, store.RemoveCredentialByName("degree"))
+		require.NoError(t, store.SaveCredential("degree",
+			newCredentialWithEvidence("http://example.edu/credentials/1872", evidence...)))
+
+		_, err = store.GetLinkedResource("http://example.edu/credentials/1872", server.URL+"/photo.jpg")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test tampered cache is fetched again", func(t *testing.T) {
+		store := newStore(t, evidence[0])
+
+		require.NoError(t, store.store.Put(resourceDataKey("http://example.edu/credentials/1872",
+			server.URL+"/transcript.pdf"), []byte(`{"content":"dGFtcGVyZWQ="}`)))
+
+		_, err := store.GetLinkedResource("http://example.edu/credentials/1872", server.URL+"/transcript.pdf")
+		require.True(t, errors.Is(err, verifiable.ErrDigestMismatch))
+
+		contents, err := store.FetchLinkedResources("http://example.edu/credentials/1872")
+		require.NoError(t, err)
+		require.Equal(t, transcript, contents[0].Content)
+	})
+
+	t.Run("test digest mismatch", func(t *testing.T) {
+		store := newStore(t, map[string]interface{}{
+			"id": server.URL + "/transcript.pdf", "digestSRI": digestSRI([]byte("other")),
+		})
+
+		_, err := store.FetchLinkedResources("http://example.edu/credentials/1872")
+		require.True(t, errors.Is(err, verifiable.ErrDigestMismatch))
+
+		_, err = store.GetLinkedResource("http://example.edu/credentials/1872", server.URL+"/transcript.pdf")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test fetch errors", func(t *testing.T) {
+		tests := []struct {
+			resource map[string]interface{}
+			opts     []ResourceOpt
+			err      string
+		}{
+			{
+				resource: map[string]interface{}{"id": server.URL + "/missing.pdf", "digestSRI": digestSRI(nil)},
+				err:      "unexpected status 404",
+			},
+			{
+				resource: map[string]interface{}{"id": server.URL + "/transcript.pdf", "digestSRI": digestSRI(transcript)},
+				opts:     []ResourceOpt{WithMaxResourceSize(5)},
+				err:      "exceeds 5 bytes",
+			},
+			{
+				resource: map[string]interface{}{
+					"id": server.URL + "/transcript.pdf", "digestSRI": digestSRI(transcript), "mediaType": "image/jpeg",
+				},
+				err: "linked resource " + server.URL + "/transcript.pdf is served as application/pdf instead of image/jpeg",
+			},
+			{
+				resource: map[string]interface{}{
+					"id": server.URL + "/transcript.pdf", "digestSRI": digestSRI(transcript), "mediaType": "pdf/",
+				},
+				err: "invalid media type 'pdf/'",
+			},
+			{
+				resource: map[string]interface{}{
+					"id": server.URL + "/transcript.pdf?type=application/", "digestSRI": digestSRI(transcript),
+					"mediaType": "application/pdf",
+				},
+				err: "invalid content type 'application/'",
+			},
+			{
+				resource: map[string]interface{}{"id": "http://[::1]:namedport", "digestSRI": digestSRI(nil)},
+				err:      "new request for linked resource",
+			},
+			{
+				resource: map[string]interface{}{"id": "unknown://example.edu/1", "digestSRI": digestSRI(nil)},
+				err:      "fetch linked resource unknown://example.edu/1",
+			},
+		}
+
+		for _, tc := range tests {
+			store := newStore(t, tc.resource)
+
+			_, err := store.FetchLinkedResources("http://example.edu/credentials/1872", tc.opts...)
+			require.Error(t, err, tc.err)
+			require.Contains(t, err.Error(), tc.err)
+		}
+
+		store := newStore(t)
+
+		_, err := store.FetchLinkedResources("http://example.edu/credentials/1873")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get vc")
+
+		_, err = store.GetLinkedResource("http://example.edu/credentials/1873", server.URL+"/photo.jpg")
+		require.Error(t, err)
+
+		_, err = store.GetLinkedResource("http://example.edu/credentials/1872", server.URL+"/photo.jpg")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test invalid evidence", func(t *testing.T) {
+		store, err := New(&mockprovider.Provider{StorageProviderValue: mem.NewProvider()})
+		require.NoError(t, err)
+
+		vc := newCredentialWithEvidence("http://example.edu/credentials/1872")
+		vc.Evidence = []interface{}{"https://example.edu/evidence/1"}
+		require.NoError(t, store.SaveCredential("degree", vc))
+
+		_, err = store.FetchLinkedResources(vc.ID)
+		require.EqualError(t, err, "get linked resources: evidence 0 is not an object")
+
+		_, err = store.GetLinkedResource(vc.ID, "https://example.edu/evidence/1")
+		require.EqualError(t, err, "get linked resources: evidence 0 is not an object")
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		storeProvider := mockstore.NewMockStoreProvider()
+
+		store, err := New(&mockprovider.Provider{StorageProviderValue: storeProvider})
+		require.NoError(t, err)
+
+		require.NoError(t, store.SaveCredential("degree",
+			newCredentialWithEvidence("http://example.edu/credentials/1872", evidence[0])))
+
+		storeProvider.Store.Store[resourceDataKey("http://example.edu/credentials/1872", "x")] = []byte("{")
+
+		_, err = store.GetLinkedResource("http://example.edu/credentials/1872", server.URL+"/transcript.pdf")
+		require.Error(t, err)
+
+		storeProvider.Store.Store[resourceDataKey("http://example.edu/credentials/1872",
+			server.URL+"/transcript.pdf")] = []byte("{")
+
+		_, err = store.GetLinkedResource("http://example.edu/credentials/1872", server.URL+"/transcript.pdf")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal linked resource")
+
+		storeProvider.Store.ErrPut = errors.New("put error")
+
+		_, err = store.FetchLinkedResources("http://example.edu/credentials/1872")
+		require.EqualError(t, err, "failed to put linked resource: put error")
+
+		storeProvider.Store.ErrPut = nil
+		storeProvider.Store.ErrDelete = errors.New("delete error")
+
+		err = store.removeLinkedResources("http://example.edu/credentials/1872")
+		require.EqualError(t, err, "unable to delete linked resource : delete error")
+	})
+}
@@ -0,0 +1,525 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// RetentionJanitor is the name used in the events of the retention janitor.
+	RetentionJanitor = "credential-retention"
+
+	// RetentionNameSpace for the store of archived credentials and retention metadata.
+	RetentionNameSpace = "verifiable_retention"
+
+	// StateDeleted is the state of events for credentials deleted by the janitor.
+	StateDeleted = "deleted"
+	// StateArchived is the state of events for credentials archived by the janitor.
+	StateArchived = "archived"
+
+	// DefaultRetentionInterval is the default interval between janitor runs.
+	DefaultRetentionInterval = time.Hour
+
+	archivedKeyPrefix = "archived_"
+	revokedKeyPrefix  = "revoked_"
+
+	credentialIDPropKey   = "credentialID"
+	credentialNamePropKey = "credentialName"
+	reasonPropKey         = "reason"
+	errorPropKey          = "error"
+)
+
+var logger = log.New("aries-framework/store/verifiable")
+
+// RetentionAction is the action applied to a credential by the retention janitor.
+type RetentionAction string
+
+const (
+	// RetentionKeep keeps the credential, it is the default.
+	RetentionKeep RetentionAction = ""
+	// RetentionDelete deletes the credential.
+	RetentionDelete RetentionAction = "delete"
+	// RetentionArchive moves the credential out of the store into the archive.
+	RetentionArchive RetentionAction = "archive"
+)
+
+// RetentionReason is the reason the retention janitor applied an action to a credential.
+type RetentionReason string
+
+const (
+	// ReasonExpired is used for credentials past their expiration date.
+	ReasonExpired RetentionReason = "expired"
+	// ReasonRevoked is used for revoked credentials.
+	ReasonRevoked RetentionReason = "revoked"
+	// ReasonSuperseded is used for old versions of re-issued credentials.
+	ReasonSuperseded RetentionReason = "superseded"
+)
+
+// RetentionPolicy defines which credentials of the store the janitor deletes or archives.
+type RetentionPolicy struct {
+	// ExpiredAction is applied to credentials ExpiredAfter past their expiration date.
+	ExpiredAction RetentionAction
+	ExpiredAfter  time.Duration
+
+	// RevokedAction is applied to credentials RevokedAfter after the janitor found them revoked.
+	// Requires a revocation checker, see WithRevocationChecker.
+	RevokedAction RetentionAction
+	RevokedAfter  time.Duration
+
+	// KeepVersions is the number of the latest versions kept of credentials re-issued with the same issuer,
+	// types and subject. SupersededAction is applied to older versions. Zero keeps all versions.
+	KeepVersions     int
+	SupersededAction RetentionAction
+}
+
+// RevocationChecker checks whether the credential is revoked.
+type RevocationChecker func(vc *verifiable.Credential) (bool, error)
+
+// ArchivedCredential is a credential moved to the archive by the retention janitor.
+type ArchivedCredential struct {
+	Record     *Record         `json:"record"`
+	Credential json.RawMessage `json:"credential"`
+	Reason     RetentionReason `json:"reason"`
+	ArchivedAt time.Time       `json:"archivedAt"`
+}
+
+// JanitorOpt configures the Janitor.
+type JanitorOpt func(j *Janitor)
+
+// WithRevocationChecker sets the checker used to find revoked credentials.
+func WithRevocationChecker(checker RevocationChecker) JanitorOpt {
+	return func(j *Janitor) {
+		j.revocationChecker = checker
+	}
+}
+
+// WithRetentionInterval sets the interval between janitor runs started with Start.
+func WithRetentionInterval(interval time.Duration) JanitorOpt {
+	return func(j *Janitor) {
+		j.interval = interval
+	}
+}
+
+// Janitor applies a retention policy to the credentials of the store. It emits a PostState message event
+// (StateDeleted or StateArchived) for every credential it deletes or archives.
+type Janitor struct {
+	service.Message
+
+	store             Store
+	retentionStore    storage.Store
+	policy            RetentionPolicy
+	revocationChecker RevocationChecker
+	interval          time.Duration
+	now               func() time.Time
+
+	lock sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewJanitor returns a new retention janitor for the credentials of the store.
+func NewJanitor(ctx provider, store Store, policy RetentionPolicy, opts ...JanitorOpt) (*Janitor, error) {
+	for _, action := range []RetentionAction{policy.ExpiredAction, policy.RevokedAction, policy.SupersededAction} {
+		if action != RetentionKeep && action != RetentionDelete && action != RetentionArchive {
+			return nil, fmt.Errorf("unsupported retention action '%s'", action)
+		}
+	}
+
+	if policy.KeepVersions < 0 {
+		return nil, errors.New("number of kept versions can't be negative")
+	}
+
+	retentionStore, err := ctx.StorageProvider().OpenStore(RetentionNameSpace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open retention store: %w", err)
+	}
+
+	j := &Janitor{
+		store:          store,
+		retentionStore: retentionStore,
+		policy:         policy,
+		interval:       DefaultRetentionInterval,
+		now:            time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	if policy.RevokedAction != RetentionKeep && j.revocationChecker == nil {
+		return nil, errors.New("revoked credentials policy requires a revocation checker")
+	}
+
+	return j, nil
+}
+
+// Start runs the janitor in the background at the configured interval until Stop is called.
+func (j *Janitor) Start() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.stop != nil {
+		return
+	}
+
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := j.Run(); err != nil {
+					logger.Warnf("credential retention: %s", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(j.stop, j.done)
+}
+
+// Stop stops the background janitor and waits until its current run has finished.
+func (j *Janitor) Stop() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.stop == nil {
+		return
+	}
+
+	close(j.stop)
+	<-j.done
+
+	j.stop, j.done = nil, nil
+}
+
+type candidate struct {
+	record *Record
+	vc     *verifiable.Credential
+}
+
+// Run applies the retention policy to the credentials of the store once. Failing actions don't stop the run,
+// they are reported by events and the returned error.
+func (j *Janitor) Run() error {
+	records, err := j.store.GetCredentials()
+	if err != nil {
+		return fmt.Errorf("get credential records: %w", err)
+	}
+
+	var (
+		kept   []*candidate
+		failed []string
+	)
+
+	for _, record := range records {
+		vc, err := j.store.GetCredential(record.ID)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("get credential '%s': %s", record.Name, err))
+
+			continue
+		}
+
+		action, reason, err := j.expiredOrRevoked(record, vc)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("check credential '%s': %s", record.Name, err))
+		}
+
+		if action == RetentionKeep {
+			kept = append(kept, &candidate{record: record, vc: vc})
+
+			continue
+		}
+
+		if err := j.apply(action, reason, record, vc); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+
+	for _, c := range j.superseded(kept) {
+		if err := j.apply(j.policy.SupersededAction, ReasonSuperseded, c.record, c.vc); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("credential retention failed: %s", strings.Join(failed, "; "))
+	}
+
+	return nil
+}
+
+// ArchivedCredentials returns the credentials moved to the archive.
+func (j *Janitor) ArchivedCredentials() ([]*ArchivedCredential, error) {
+	itr := j.retentionStore.Iterator(archivedKeyPrefix, fmt.Sprintf(limitPattern, archivedKeyPrefix))
+	defer itr.Release()
+
+	var archived []*ArchivedCredential
+
+	for itr.Next() {
+		var a ArchivedCredential
+
+		err := json.Unmarshal(itr.Value(), &a)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal archived credential : %w", err)
+		}
+
+		archived = append(archived, &a)
+	}
+
+	return archived, nil
+}
+
+func (j *Janitor) expiredOrRevoked(record *Record, vc *verifiable.Credential) (RetentionAction, RetentionReason,
+	error) {
+	now := j.now()
+
+	if j.policy.ExpiredAction != RetentionKeep && vc.Expired != nil &&
+		now.Sub(vc.Expired.Time) >= j.policy.ExpiredAfter {
+		return j.policy.ExpiredAction, ReasonExpired, nil
+	}
+
+	if j.policy.RevokedAction == RetentionKeep {
+		return RetentionKeep, "", nil
+	}
+
+	revokedAt, err := j.revokedAt(record, vc)
+	if err != nil || revokedAt.IsZero() {
+		return RetentionKeep, "", err
+	}
+
+	if now.Sub(revokedAt) >= j.policy.RevokedAfter {
+		return j.policy.RevokedAction, ReasonRevoked, nil
+	}
+
+	return RetentionKeep, "", nil
+}
+
+// revokedAt returns the time the credential was first found revoked, or zero time if it isn't revoked.
+// The revocation time is tracked by the name of the credential record, like archived credentials, as credentials
+// may have no ID.
+func (j *Janitor) revokedAt(record *Record, vc *verifiable.Credential) (time.Time, error) {
+	key := revokedKeyPrefix + record.Name
+
+	data, err := j.retentionStore.Get(key)
+	if err == nil {
+		revokedAt, e := time.Parse(time.RFC3339Nano, string(data))
+		if e != nil {
+			return time.Time{}, fmt.Errorf("parse revocation time: %w", e)
+		}
+
+		return revokedAt, nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return time.Time{}, fmt.Errorf("get revocation time: %w", err)
+	}
+
+	revoked, err := j.revocationChecker(vc)
+	if err != nil || !revoked {
+		return time.Time{}, err
+	}
+
+	now := j.now()
+
+	err = j.retentionStore.Put(key, []byte(now.Format(time.RFC3339Nano)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("save revocation time: %w", err)
+	}
+
+	return now, nil
+}
+
+// superseded returns the credentials which aren't among the latest KeepVersions versions of credentials
+// with the same issuer, types and subject.
+func (j *Janitor) superseded(candidates []*candidate) []*candidate {
+	if j.policy.KeepVersions == 0 || j.policy.SupersededAction == RetentionKeep {
+		return nil
+	}
+
+	groups := make(map[string][]*candidate)
+
+	var keys []string
+
+	for _, c := range candidates {
+		if c.record.SubjectID == "" {
+			continue
+		}
+
+		types := append([]string{}, c.vc.Types...)
+		sort.Strings(types)
+
+		key := strings.Join([]string{c.vc.Issuer.ID, strings.Join(types, ","), c.record.SubjectID}, "|")
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], c)
+	}
+
+	var result []*candidate
+
+	for _, key := range keys {
+		versions := groups[key]
+		if len(versions) <= j.policy.KeepVersions {
+			continue
+		}
+
+		sort.SliceStable(versions, func(a, b int) bool {
+			return issued(versions[a].vc).After(issued(versions[b].vc))
+		})
+
+		result = append(result, versions[j.policy.KeepVersions:]...)
+	}
+
+	return result
+}
+
+func (j *Janitor) apply(action RetentionAction, reason RetentionReason, record *Record,
+	vc *verifiable.Credential) error {
+	var (
+		state string
+		err   error
+	)
+
+	switch action {
+	case RetentionArchive:
+		state = StateArchived
+		err = j.archive(reason, record, vc)
+	default:
+		state = StateDeleted
+	}
+
+	if err == nil {
+		err = j.store.RemoveCredentialByName(record.Name)
+	}
+
+	if err == nil {
+		err = j.retentionStore.Delete(revokedKeyPrefix + record.Name)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("%s %s credential '%s': %w", action, reason, record.Name, err)
+	}
+
+	j.sendEvent(state, newRetentionProps(record, reason, err))
+
+	return err
+}
+
+func (j *Janitor) archive(reason RetentionReason, record *Record, vc *verifiable.Credential) error {
+	vcBytes, err := vc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal vc: %w", err)
+	}
+
+	archivedAt := j.now()
+
+	archivedBytes, err := json.Marshal(&ArchivedCredential{
+		Record:     record,
+		Credential: vcBytes,
+		Reason:     reason,
+		ArchivedAt: archivedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal archived credential: %w", err)
+	}
+
+	// credentials are archived by the name of their record, which (unlike their ID) is always set and unique in
+	// the store; the archive time keeps the credentials archived earlier under a reused name.
+	return j.retentionStore.Put(archivedKeyPrefix+record.Name+"_"+archivedAt.Format(time.RFC3339Nano), archivedBytes)
+}
+
+func (j *Janitor) sendEvent(state string, props *retentionProps) {
+	msg := service.StateMsg{
+		ProtocolName: RetentionJanitor,
+		Type:         service.PostState,
+		StateID:      state,
+		Properties:   props,
+	}
+
+	for _, handler := range j.MsgEvents() {
+		handler <- msg
+	}
+}
+
+func issued(vc *verifiable.Credential) time.Time {
+	if vc.Issued == nil {
+		return time.Time{}
+	}
+
+	return vc.Issued.Time
+}
+
+type retentionProps struct {
+	credentialID   string
+	credentialName string
+	reason         RetentionReason
+	err            error
+}
+
+func newRetentionProps(record *Record, reason RetentionReason, err error) *retentionProps {
+	return &retentionProps{
+		credentialID:   record.ID,
+		credentialName: record.Name,
+		reason:         reason,
+		err:            err,
+	}
+}
+
+// CredentialID returns the ID of the credential.
+func (e *retentionProps) CredentialID() string {
+	return e.credentialID
+}
+
+// CredentialName returns the name the credential was saved with.
+func (e *retentionProps) CredentialName() string {
+	return e.credentialName
+}
+
+// Reason returns the reason of the action.
+func (e *retentionProps) Reason() RetentionReason {
+	return e.reason
+}
+
+// Err returns the error of a failed action.
+func (e *retentionProps) Err() error {
+	return e.err
+}
+
+// All implements EventProperties interface.
+func (e *retentionProps) All() map[string]interface{} {
+	all := map[string]interface{}{
+		credentialIDPropKey:   e.credentialID,
+		credentialNamePropKey: e.credentialName,
+		reasonPropKey:         e.reason,
+	}
+
+	if e.err != nil {
+		all[errorPropKey] = e.err
+	}
+
+	return all
+}
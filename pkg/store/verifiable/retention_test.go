@@ -0,0 +1,539 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/didcomm/common/service"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	mockprovider "github.com/hyperledger/aries-framework-go/pkg/mock/provider"
+	mockstore "github.com/hyperledger/aries-framework-go/pkg/mock/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+//nolint:gochecknoglobals
+var retentionNow = time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func newRetentionCredential(id, subject string, issued time.Time, expired *time.Time) *verifiable.Credential {
+	vc := &verifiable.Credential{
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		ID:      id,
+		Types:   []string{"VerifiableCredential", "UniversityDegreeCredential"},
+		Subject: subject,
+		Issuer:  verifiable.Issuer{ID: "did:example:76e12ec712ebc6f1c221ebfeb1f"},
+		Issued:  util.NewTime(issued),
+	}
+
+	if expired != nil {
+		vc.Expired = util.NewTime(*expired)
+	}
+
+	return vc
+}
+
+func daysAgo(days int) *time.Time {
+	t := retentionNow.AddDate(0, 0, -days)
+
+	return &t
+}
+
+type retentionFixture struct {
+	provider *mockprovider.Provider
+	store    *StoreImplementation
+}
+
+func newRetentionFixture(t *testing.T) *retentionFixture {
+	t.Helper()
+
+	provider := &mockprovider.Provider{StorageProviderValue: mem.NewProvider()}
+
+	store, err := New(provider)
+	require.NoError(t, err)
+
+	return &retentionFixture{provider: provider, store: store}
+}
+
+func (f *retentionFixture) save(t *testing.T, name string, vc *verifiable.Credential) {
+	t.Helper()
+
+	require.NoError(t, f.store.SaveCredential(name, vc))
+}
+
+func (f *retentionFixture) newJanitor(t *testing.T, policy RetentionPolicy, opts ...JanitorOpt) *Janitor {
+	t.Helper()
+
+	janitor, err := NewJanitor(f.provider, f.store, policy, opts...)
+	require.NoError(t, err)
+
+	janitor.now = func() time.Time { return retentionNow }
+
+	return janitor
+}
+
+func (f *retentionFixture) names(t *testing.T) []string {
+	t.Helper()
+
+	records, err := f.store.GetCredentials()
+	require.NoError(t, err)
+
+	var names []string
+	for _, r := range records {
+		names = append(names, r.Name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+type retentionEvent struct {
+	state  string
+	name   string
+	reason RetentionReason
+	err    error
+}
+
+func collectEvents(t *testing.T, janitor *Janitor) (func() []retentionEvent, func()) {
+	t.Helper()
+
+	ch := make(chan service.StateMsg, 100)
+	require.NoError(t, janitor.RegisterMsgEvent(ch))
+
+	collect := func() []retentionEvent {
+		var events []retentionEvent
+
+		for {
+			select {
+			case msg := <-ch:
+				require.Equal(t, RetentionJanitor, msg.ProtocolName)
+				require.Equal(t, service.PostState, msg.Type)
+
+				props, ok := msg.Properties.(*retentionProps)
+				require.True(t, ok)
+				require.Equal(t, props.CredentialName(), msg.Properties.All()[credentialNamePropKey])
+				require.NotEmpty(t, props.CredentialID())
+
+				events = append(events, retentionEvent{
+					state: msg.StateID, name: props.CredentialName(), reason: props.Reason(), err: props.Err(),
+				})
+			default:
+				sort.Slice(events, func(i, j int) bool { return events[i].name < events[j].name })
+
+				return events
+			}
+		}
+	}
+
+	return collect, func() { require.NoError(t, janitor.UnregisterMsgEvent(ch)) }
+}
+
+func TestJanitor_Expired(t *testing.T) {
+	f := newRetentionFixture(t)
+
+	f.save(t, "valid", newRetentionCredential("http://example.edu/credentials/1", "did:example:a",
+		retentionNow.AddDate(-1, 0, 0), nil))
+	f.save(t, "expired-recently", newRetentionCredential("http://example.edu/credentials/2", "did:example:b",
+		retentionNow.AddDate(-1, 0, 0), daysAgo(5)))
+	f.save(t, "expired-long-ago", newRetentionCredential("http://example.edu/credentials/3", "did:example:c",
+		retentionNow.AddDate(-1, 0, 0), daysAgo(31)))
+	f.save(t, "expires-soon", newRetentionCredential("", "did:example:d",
+		retentionNow.AddDate(-1, 0, 0), daysAgo(-1)))
+
+	janitor := f.newJanitor(t, RetentionPolicy{ExpiredAction: RetentionDelete, ExpiredAfter: 30 * 24 * time.Hour})
+
+	events, unregister := collectEvents(t, janitor)
+	defer unregister()
+
+	require.NoError(t, janitor.Run())
+	require.Equal(t, []string{"expired-recently", "expires-soon", "valid"}, f.names(t))
+	require.Equal(t, []retentionEvent{{state: StateDeleted, name: "expired-long-ago", reason: ReasonExpired}},
+		events())
+
+	_, err := f.store.GetCredential("http://example.edu/credentials/3")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	archived, err := janitor.ArchivedCredentials()
+	require.NoError(t, err)
+	require.Empty(t, archived)
+
+	// nothing left to do
+	require.NoError(t, janitor.Run())
+	require.Empty(t, events())
+}
+
+func TestJanitor_Archive(t *testing.T) {
+	f := newRetentionFixture(t)
+
+	vc := newRetentionCredential("http://example.edu/credentials/1", "did:example:a",
+		retentionNow.AddDate(-1, 0, 0), daysAgo(1))
+	f.save(t, "expired", vc)
+
+	janitor := f.newJanitor(t, RetentionPolicy{ExpiredAction: RetentionArchive})
+
+	events, unregister := collectEvents(t, janitor)
+	defer unregister()
+
+	require.NoError(t, janitor.Run())
+	require.Empty(t, f.names(t))
+	require.Equal(t, []retentionEvent{{state: StateArchived, name: "expired", reason: ReasonExpired}}, events())
+
+	archived, err := janitor.ArchivedCredentials()
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	require.Equal(t, "expired", archived[0].Record.Name)
+	require.Equal(t, vc.ID, archived[0].Record.ID)
+	require.Equal(t, ReasonExpired, archived[0].Reason)
+	require.True(t, retentionNow.Equal(archived[0].ArchivedAt))
+
+	archivedVC, err := verifiable.ParseUnverifiedCredential(archived[0].Credential)
+	require.NoError(t, err)
+	require.Equal(t, vc.ID, archivedVC.ID)
+
+	// name can be reused after archiving
+	f.save(t, "expired", newRetentionCredential("http://example.edu/credentials/2", "did:example:a",
+		retentionNow, nil))
+}
+
+func TestJanitor_ArchiveWithoutID(t *testing.T) {
+	f := newRetentionFixture(t)
+
+	f.save(t, "expired-1", newRetentionCredential("", "did:example:a", retentionNow.AddDate(-1, 0, 0), daysAgo(1)))
+	f.save(t, "expired-2", newRetentionCredential("", "did:example:b", retentionNow.AddDate(-1, 0, 0), daysAgo(2)))
+
+	janitor := f.newJanitor(t, RetentionPolicy{ExpiredAction: RetentionArchive})
+
+	require.NoError(t, janitor.Run())
+	require.Empty(t, f.names(t))
+
+	// name is reused and archived again
+	f.save(t, "expired-1", newRetentionCredential("", "did:example:c", retentionNow.AddDate(-1, 0, 0), daysAgo(3)))
+
+	janitor.now = func() time.Time { return retentionNow.Add(time.Hour) }
+
+	require.NoError(t, janitor.Run())
+
+	archived, err := janitor.ArchivedCredentials()
+	require.NoError(t, err)
+	require.Len(t, archived, 3)
+
+	var subjects []string
+
+	for _, a := range archived {
+		vc, err := verifiable.ParseUnverifiedCredential(a.Credential)
+		require.NoError(t, err)
+
+		subjects = append(subjects, a.Record.Name+"|"+vc.Subject.([]verifiable.Subject)[0].ID)
+	}
+
+	sort.Strings(subjects)
+	require.Equal(t, []string{"expired-1|did:example:a", "expired-1|did:example:c", "expired-2|did:example:b"},
+		subjects)
+}
+
+func TestJanitor_Revoked(t *testing.T) {
+	f := newRetentionFixture(t)
+
+	f.save(t, "valid", newRetentionCredential("http://example.edu/credentials/1", "did:example:a",
+		retentionNow.AddDate(-1, 0, 0), nil))
+	f.save(t, "revoked", newRetentionCredential("http://example.edu/credentials/2", "did:example:b",
+		retentionNow.AddDate(-1, 0, 0), nil))
+
+	var checks int
+
+	checker := func(vc *verifiable.Credential) (bool, error) {
+		checks++
+
+		return vc.ID == "http://example.edu/credentials/2", nil
+	}
+
+	janitor := f.newJanitor(t, RetentionPolicy{RevokedAction: RetentionArchive, RevokedAfter: 7 * 24 * time.Hour},
+		WithRevocationChecker(checker))
+
+	events, unregister := collectEvents(t, janitor)
+	defer unregister()
+
+	// revocation is recorded, but the credential is kept for the retention period
+	require.NoError(t, janitor.Run())
+	require.Equal(t, []string{"revoked", "valid"}, f.names(t))
+	require.Empty(t, events())
+	require.Equal(t, 2, checks)
+
+	// revoked credential isn't checked again
+	janitor.now = func() time.Time { return retentionNow.AddDate(0, 0, 6) }
+
+	require.NoError(t, janitor.Run())
+	require.Equal(t, []string{"revoked", "valid"}, f.names(t))
+	require.Equal(t, 3, checks)
+
+	janitor.now = func() time.Time { return retentionNow.AddDate(0, 0, 7) }
+
+	require.NoError(t, janitor.Run())
+	require.Equal(t, []string{"valid"}, f.names(t))
+	require.Equal(t, []retentionEvent{{state: StateArchived, name: "revoked", reason: ReasonRevoked}}, events())
+
+	_, err := janitor.retentionStore.Get(revokedKeyPrefix + "revoked")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	t.Run("test credential without ID", func(t *testing.T) {
+		f := newRetentionFixture(t)
+		f.save(t, "revoked", newRetentionCredential("", "did:example:b", retentionNow, nil))
+		f.save(t, "valid", newRetentionCredential("", "did:example:a", retentionNow.AddDate(0, 0, -1), nil))
+
+		var checks int
+
+		janitor := f.newJanitor(t, RetentionPolicy{RevokedAction: RetentionDelete, RevokedAfter: 7 * 24 * time.Hour},
+			WithRevocationChecker(func(vc *verifiable.Credential) (bool, error) {
+				checks++
+
+				return vc.Issued.Time.Equal(retentionNow), nil
+			}))
+
+		// the revocation of the credential is tracked by the name of its record
+		require.NoError(t, janitor.Run())
+		require.Equal(t, []string{"revoked", "valid"}, f.names(t))
+		require.Equal(t, 2, checks)
+
+		janitor.now = func() time.Time { return retentionNow.AddDate(0, 0, 6) }
+
+		require.NoError(t, janitor.Run())
+		require.Equal(t, []string{"revoked", "valid"}, f.names(t))
+		require.Equal(t, 3, checks)
+
+		janitor.now = func() time.Time { return retentionNow.AddDate(0, 0, 7) }
+
+		require.NoError(t, janitor.Run())
+		require.Equal(t, []string{"valid"}, f.names(t))
+
+		_, err := janitor.retentionStore.Get(revokedKeyPrefix + "revoked")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("test revocation check error", func(t *testing.T) {
+		f := newRetentionFixture(t)
+		f.save(t, "vc", newRetentionCredential("http://example.edu/credentials/1", "did:example:b",
+			retentionNow, nil))
+
+		janitor := f.newJanitor(t, RetentionPolicy{RevokedAction: RetentionDelete},
+			WithRevocationChecker(func(*verifiable.Credential) (bool, error) {
+				return false, errors.New("status list unavailable")
+			}))
+
+		err := janitor.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "check credential 'vc': status list unavailable")
+		require.Equal(t, []string{"vc"}, f.names(t))
+	})
+
+	t.Run("test invalid revocation time", func(t *testing.T) {
+		f := newRetentionFixture(t)
+		f.save(t, "vc", newRetentionCredential("http://example.edu/credentials/1", "did:example:b",
+			retentionNow, nil))
+
+		janitor := f.newJanitor(t, RetentionPolicy{RevokedAction: RetentionDelete},
+			WithRevocationChecker(func(*verifiable.Credential) (bool, error) { return true, nil }))
+
+		require.NoError(t, janitor.retentionStore.Put(revokedKeyPrefix+"vc", []byte("yesterday")))
+
+		err := janitor.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse revocation time")
+	})
+}
+
+func TestJanitor_Versions(t *testing.T) {
+	f := newRetentionFixture(t)
+
+	for i, name := range []string{"v1", "v3", "v2"} {
+		issued := map[string]time.Time{
+			"v1": retentionNow.AddDate(-3, 0, 0),
+			"v2": retentionNow.AddDate(-2, 0, 0),
+			"v3": retentionNow.AddDate(-1, 0, 0),
+		}[name]
+
+		f.save(t, name, newRetentionCredential("http://example.edu/credentials/"+name, "did:example:a", issued, nil))
+		f.save(t, "other-subject-"+name, newRetentionCredential("", "did:example:"+string(rune('b'+i)), issued, nil))
+	}
+
+	// other types are other credentials
+	license := newRetentionCredential("", "did:example:a", retentionNow.AddDate(-5, 0, 0), nil)
+	license.Types = []string{"VerifiableCredential", "DriversLicense"}
+	f.save(t, "license", license)
+
+	// expired credential is deleted and doesn't count as version
+	f.save(t, "v4-expired", newRetentionCredential("", "did:example:a", retentionNow.AddDate(0, -1, 0), daysAgo(1)))
+
+	janitor := f.newJanitor(t, RetentionPolicy{
+		ExpiredAction:    RetentionDelete,
+		KeepVersions:     2,
+		SupersededAction: RetentionArchive,
+	})
+
+	events, unregister := collectEvents(t, janitor)
+	defer unregister()
+
+	require.NoError(t, janitor.Run())
+	require.Equal(t, []string{
+		"license", "other-subject-v1", "other-subject-v2", "other-subject-v3", "v2", "v3",
+	}, f.names(t))
+	require.Equal(t, []retentionEvent{
+		{state: StateArchived, name: "v1", reason: ReasonSuperseded},
+		{state: StateDeleted, name: "v4-expired", reason: ReasonExpired},
+	}, events())
+
+	t.Run("test keep all versions", func(t *testing.T) {
+		janitor := f.newJanitor(t, RetentionPolicy{SupersededAction: RetentionDelete})
+		require.NoError(t, janitor.Run())
+		require.Len(t, f.names(t), 6)
+	})
+}
+
+func TestJanitor_Errors(t *testing.T) {
+	t.Run("test invalid policies", func(t *testing.T) {
+		f := newRetentionFixture(t)
+
+		_, err := NewJanitor(f.provider, f.store, RetentionPolicy{ExpiredAction: "shred"})
+		require.EqualError(t, err, "unsupported retention action 'shred'")
+
+		_, err = NewJanitor(f.provider, f.store, RetentionPolicy{KeepVersions: -1})
+		require.EqualError(t, err, "number of kept versions can't be negative")
+
+		_, err = NewJanitor(f.provider, f.store, RetentionPolicy{RevokedAction: RetentionDelete})
+		require.EqualError(t, err, "revoked credentials policy requires a revocation checker")
+	})
+
+	t.Run("test open store error", func(t *testing.T) {
+		f := newRetentionFixture(t)
+
+		_, err := NewJanitor(&mockprovider.Provider{StorageProviderValue: &mockstore.MockStoreProvider{
+			ErrOpenStoreHandle: errors.New("open error"),
+		}}, f.store, RetentionPolicy{})
+		require.EqualError(t, err, "failed to open retention store: open error")
+	})
+
+	t.Run("test store errors", func(t *testing.T) {
+		vcStore := mockstore.NewMockStoreProvider()
+
+		store, err := New(&mockprovider.Provider{StorageProviderValue: vcStore})
+		require.NoError(t, err)
+
+		vc := newRetentionCredential("http://example.edu/credentials/1", "did:example:a", retentionNow, daysAgo(1))
+		require.NoError(t, store.SaveCredential("expired", vc))
+
+		retentionProvider := mockstore.NewMockStoreProvider()
+
+		janitor, err := NewJanitor(&mockprovider.Provider{StorageProviderValue: retentionProvider}, store,
+			RetentionPolicy{ExpiredAction: RetentionArchive})
+		require.NoError(t, err)
+
+		janitor.now = func() time.Time { return retentionNow }
+
+		events, unregister := collectEvents(t, janitor)
+		defer unregister()
+
+		vcStore.Store.ErrGet = errors.New("get error")
+
+		err = janitor.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get credential 'expired'")
+
+		vcStore.Store.ErrGet = nil
+		retentionProvider.Store.ErrPut = errors.New("put error")
+
+		err = janitor.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "archive expired credential 'expired': put error")
+
+		failed := events()
+		require.Len(t, failed, 1)
+		require.Equal(t, StateArchived, failed[0].state)
+		require.Error(t, failed[0].err)
+
+		retentionProvider.Store.ErrPut = nil
+		retentionProvider.Store.ErrDelete = errors.New("delete error")
+
+		err = janitor.Run()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "delete error")
+	})
+
+	t.Run("test get records error", func(t *testing.T) {
+		f := newRetentionFixture(t)
+
+		janitor, err := NewJanitor(f.provider, &failingStore{Store: f.store}, RetentionPolicy{})
+		require.NoError(t, err)
+
+		err = janitor.Run()
+		require.EqualError(t, err, "get credential records: records error")
+	})
+
+	t.Run("test archived credentials error", func(t *testing.T) {
+		retentionProvider := mockstore.NewMockStoreProvider()
+
+		janitor, err := NewJanitor(&mockprovider.Provider{StorageProviderValue: retentionProvider},
+			newRetentionFixture(t).store, RetentionPolicy{})
+		require.NoError(t, err)
+
+		retentionProvider.Store.Store[archivedKeyPrefix+"1"] = []byte("{")
+
+		_, err = janitor.ArchivedCredentials()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal archived credential")
+	})
+}
+
+type failingStore struct {
+	Store
+}
+
+func (s *failingStore) GetCredentials() ([]*Record, error) {
+	return nil, errors.New("records error")
+}
+
+func TestJanitor_StartStop(t *testing.T) {
+	f := newRetentionFixture(t)
+	f.save(t, "expired", newRetentionCredential("", "did:example:a", retentionNow, daysAgo(1)))
+
+	janitor := f.newJanitor(t, RetentionPolicy{ExpiredAction: RetentionDelete},
+		WithRetentionInterval(time.Millisecond))
+
+	ch := make(chan service.StateMsg)
+	require.NoError(t, janitor.RegisterMsgEvent(ch))
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		select {
+		case msg := <-ch:
+			require.Equal(t, StateDeleted, msg.StateID)
+		case <-time.After(5 * time.Second):
+			t.Error("timeout waiting for retention event")
+		}
+	}()
+
+	janitor.Start()
+	janitor.Start()
+
+	wg.Wait()
+
+	janitor.Stop()
+	janitor.Stop()
+
+	require.Empty(t, f.names(t))
+}
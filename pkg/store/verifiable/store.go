@@ -279,7 +279,7 @@ func (s *StoreImplementation) RemoveCredentialByName(name string) error {
 		return fmt.Errorf("unable to delete credential : %w", err)
 	}
 
-	return nil
+	return s.removeLinkedResources(id)
 }
 
 // RemovePresentationByName removes the verifiable presentation and its records containing given name.
@@ -0,0 +1,21 @@
+#!/bin/bash
+#
+# Copyright SecureKey Technologies Inc. All Rights Reserved.
+#
+# SPDX-License-Identifier: Apache-2.0
+#
+set -e
+
+echo "Running $0"
+
+BENCH=${BENCH:-.}
+BENCH_COUNT=${BENCH_COUNT:-1}
+BENCH_PKG=github.com/hyperledger/aries-framework-go/pkg/doc/verifiable/benchmark
+BENCH_OUTPUT_DIR=${BENCH_OUTPUT_DIR:-build/benchmark}
+
+mkdir -p "$BENCH_OUTPUT_DIR"
+
+# Profiles can be inspected with: go tool pprof "$BENCH_OUTPUT_DIR"/cpu.out
+go test $BENCH_PKG -run='^$' -bench="$BENCH" -benchmem -count="$BENCH_COUNT" \
+  -cpuprofile="$BENCH_OUTPUT_DIR"/cpu.out -memprofile="$BENCH_OUTPUT_DIR"/mem.out \
+  -o "$BENCH_OUTPUT_DIR"/benchmark.test | tee "$BENCH_OUTPUT_DIR"/benchmark.txt